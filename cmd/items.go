@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/hunts"
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/spf13/cobra"
+)
+
+var itemsHuntFlag *int
+var itemsEnvFlag *string
+
+var itemsCmd = &cobra.Command{
+	Use:   "items",
+	Short: "manage the items of a hunt",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// show the details of internal errors to whoever is running the command
+		response.SetDevMode(true)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+var itemsImportCmd = &cobra.Command{
+	Use:   "import --hunt N file.csv",
+	Short: "create or update the items of a hunt from a csv file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		f, err := os.Open(args[0])
+		if err != nil {
+			fmt.Printf("unable to open %s: %v\n", args[0], err)
+			os.Exit(1)
+		}
+		defer f.Close()
+
+		database := db.InitDB(*itemsEnvFlag)
+		defer db.Shutdown(database)
+
+		result, e := hunts.ImportItemsCSV(*itemsHuntFlag, f)
+		if e != nil {
+			fmt.Println(string(e.JSON()))
+			db.Shutdown(database)
+			os.Exit(1)
+		}
+
+		fmt.Printf("created %d items and updated %d items\n", result.Created, result.Updated)
+	},
+}
+
+var itemsExportCmd = &cobra.Command{
+	Use:   "export --hunt N",
+	Short: "write the items of a hunt to stdout as a csv file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		database := db.InitDB(*itemsEnvFlag)
+		defer db.Shutdown(database)
+
+		e := hunts.ExportItemsCSV(*itemsHuntFlag, os.Stdout)
+		if e != nil {
+			fmt.Fprintln(os.Stderr, string(e.JSON()))
+			db.Shutdown(database)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	itemsHuntFlag = itemsCmd.PersistentFlags().Int("hunt", 0, "id of the hunt")
+	itemsCmd.MarkPersistentFlagRequired("hunt")
+	itemsEnvFlag = itemsCmd.PersistentFlags().String(
+		"env",
+		"production",
+		"database environment [testing | production | development]",
+	)
+
+	itemsCmd.AddCommand(itemsImportCmd)
+	itemsCmd.AddCommand(itemsExportCmd)
+	rootCmd.AddCommand(itemsCmd)
+}
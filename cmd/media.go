@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/config"
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/gc"
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/cljohnson4343/scavenge/storage"
+	"github.com/spf13/cobra"
+)
+
+var mediaEnvFlag *string
+var mediaGCDeleteFlag *bool
+var mediaGCMinAgeFlag *time.Duration
+
+var mediaCmd = &cobra.Command{
+	Use:   "media",
+	Short: "manage uploaded media files",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// show the details of internal errors to whoever is running the command
+		response.SetDevMode(true)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+var mediaGCCmd = &cobra.Command{
+	Use:   "gc [--delete] [--min-age 1h]",
+	Short: "find, and optionally delete, the files in storage that no media row refers to",
+	Long: `Removes the uploads that expired without being confirmed, deletes
+the files queued in the blob outbox by deleted rows, then
+compares the files in storage against the media, thumbnail, and upload
+rows and lists the files no row refers to. The files are only deleted
+when --delete is given.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		database := db.InitDB(*mediaEnvFlag)
+		defer db.Shutdown(database)
+
+		store, e := storage.FromConfig(config.FilesURL)
+		if e != nil {
+			fmt.Println(string(e.JSON()))
+			db.Shutdown(database)
+			os.Exit(1)
+		}
+
+		expired, e := gc.ExpireUploads()
+		if e != nil {
+			fmt.Println(string(e.JSON()))
+		}
+		fmt.Printf("removed %d expired uploads\n", expired)
+
+		drained, e := gc.DrainAll(store)
+		if e != nil {
+			fmt.Println(string(e.JSON()))
+		}
+		fmt.Printf("deleted %d files queued in the blob outbox\n", drained)
+
+		report, e := gc.Reconcile(store, *mediaGCMinAgeFlag, *mediaGCDeleteFlag)
+		if report != nil {
+			for _, b := range report.Orphans {
+				fmt.Printf("%s\t%d bytes\t%s\n", b.Key, b.Size, b.ModTime.Format(time.RFC3339))
+			}
+
+			fmt.Printf(
+				"%d files in storage, %d orphaned, %d skipped for being newer than %v, %d deleted\n",
+				report.Blobs,
+				len(report.Orphans),
+				report.Recent,
+				*mediaGCMinAgeFlag,
+				report.Deleted,
+			)
+		}
+		if e != nil {
+			fmt.Println(string(e.JSON()))
+			db.Shutdown(database)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	mediaEnvFlag = mediaCmd.PersistentFlags().String(
+		"env",
+		"production",
+		"database environment [testing | production | development]",
+	)
+	mediaGCDeleteFlag = mediaGCCmd.Flags().Bool("delete", false, "delete the orphaned files")
+	mediaGCMinAgeFlag = mediaGCCmd.Flags().Duration(
+		"min-age",
+		time.Hour,
+		"only files older than this can be orphans, newer ones may not have their rows yet",
+	)
+
+	mediaCmd.AddCommand(mediaGCCmd)
+	rootCmd.AddCommand(mediaCmd)
+}
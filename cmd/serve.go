@@ -4,14 +4,17 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/cljohnson4343/scavenge/config"
 	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/gc"
 	"github.com/cljohnson4343/scavenge/response"
 	"github.com/cljohnson4343/scavenge/routes"
-	"github.com/cljohnson4343/scavenge/s3"
+	"github.com/cljohnson4343/scavenge/storage"
 	"github.com/go-chi/chi"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var devModeFlag *bool
@@ -30,14 +33,17 @@ var serveCmd = &cobra.Command{
 		database := db.InitDB(deployEnv)
 		defer db.Shutdown(database)
 
-		env := config.CreateEnv(database)
-		router := routes.Routes(env)
-
-		err := s3.InitSession()
+		store, err := storage.FromConfig(config.FilesURL)
 		if err != nil {
-			log.Panic(err.JSON())
+			log.Panic(string(err.JSON()))
 		}
 
+		// the files of deleted rows are deleted from storage in the background
+		go gc.DrainEvery(store, blobDrainInterval())
+
+		env := config.CreateEnv(database, store)
+		router := routes.Routes(env)
+
 		walkFunc := func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
 			log.Printf("%s %s\n", method, route) // walk and print out all routes
 			return nil
@@ -51,6 +57,17 @@ var serveCmd = &cobra.Command{
 	},
 }
 
+// blobDrainInterval returns how often the blob outbox is drained, set by the
+// blob_drain_interval config value
+func blobDrainInterval() time.Duration {
+	interval := viper.GetDuration("blob_drain_interval")
+	if interval <= 0 {
+		return time.Minute
+	}
+
+	return interval
+}
+
 func init() {
 	devModeFlag = serveCmd.PersistentFlags().Bool("dev-mode", false, "set the server to dev mode")
 	portFlag = serveCmd.Flags().Int("port", 4343, "port to use")
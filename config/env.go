@@ -3,6 +3,8 @@ package config
 import (
 	"database/sql"
 
+	"github.com/cljohnson4343/scavenge/storage"
+
 	// necessary for database/sql package
 	_ "github.com/lib/pq"
 )
@@ -12,12 +14,19 @@ import (
 // interfaces of the other packages.
 type Env struct {
 	*sql.DB
+
+	// Storage is where uploaded files are kept
+	Storage storage.Store
 }
 
 // CreateEnv instantiates a Env type
-func CreateEnv(db *sql.DB) *Env {
-	return &Env{db}
+func CreateEnv(db *sql.DB, store storage.Store) *Env {
+	return &Env{db, store}
 }
 
 // BaseAPIURL is the base of the api's url.
 const BaseAPIURL = `/api/v0/`
+
+// FilesURL is where the files of the local and memory storage drivers are
+// served.
+const FilesURL = BaseAPIURL + `files/`
@@ -0,0 +1,163 @@
+package db
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// BlobOutboxDB is a representation of a row in the blob_outbox table. Each
+// row is a blob that has to be deleted from storage.
+type BlobOutboxDB struct {
+
+	// The id of the row
+	ID int
+
+	// The storage key of the blob
+	Key string
+
+	// How many times deleting the blob has failed
+	Attempts int
+
+	// When the blob was queued
+	CreatedAt time.Time
+}
+
+var blobOutboxSelectScript = `
+	SELECT id, key, attempts, created_at
+	FROM blob_outbox
+	ORDER BY attempts, id
+	LIMIT $1;
+	`
+
+// GetBlobOutbox returns at most limit of the queued blobs. The blobs that
+// have failed the fewest times are returned first so one blob that can not
+// be deleted does not hold up the rest.
+func GetBlobOutbox(limit int) ([]*BlobOutboxDB, *response.Error) {
+	rows, err := stmtMap["blobOutboxSelect"].Query(limit)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting the blob outbox: %v",
+			err,
+		)
+	}
+	defer rows.Close()
+
+	blobs := make([]*BlobOutboxDB, 0)
+	for rows.Next() {
+		b := BlobOutboxDB{}
+		err = rows.Scan(&b.ID, &b.Key, &b.Attempts, &b.CreatedAt)
+		if err != nil {
+			return nil, response.NewErrorf(
+				http.StatusInternalServerError,
+				"error getting the blob outbox: %v",
+				err,
+			)
+		}
+
+		blobs = append(blobs, &b)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting the blob outbox: %v",
+			err,
+		)
+	}
+
+	return blobs, nil
+}
+
+var blobOutboxDeleteScript = `
+	DELETE FROM blob_outbox
+	WHERE id = $1;
+	`
+
+// Delete removes the row once its blob has been deleted
+func (b *BlobOutboxDB) Delete() *response.Error {
+	_, err := stmtMap["blobOutboxDelete"].Exec(b.ID)
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error removing blob %s from the outbox: %v",
+			b.Key,
+			err,
+		)
+	}
+
+	return nil
+}
+
+var blobOutboxFailScript = `
+	UPDATE blob_outbox
+	SET attempts = attempts + 1
+	WHERE id = $1
+	RETURNING attempts;
+	`
+
+// Fail records a failed attempt at deleting the blob
+func (b *BlobOutboxDB) Fail() *response.Error {
+	err := stmtMap["blobOutboxFail"].QueryRow(b.ID).Scan(&b.Attempts)
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error recording failure for blob %s: %v",
+			b.Key,
+			err,
+		)
+	}
+
+	return nil
+}
+
+var blobKeysInUseScript = `
+	SELECT regexp_replace(url, '^.*/', '') FROM media
+	UNION
+	SELECT regexp_replace(url, '^.*/', '') FROM media_thumbnails
+	UNION
+	SELECT key FROM uploads WHERE confirmed_at IS NULL AND expires_at > $1
+	UNION
+	SELECT key FROM blob_outbox;
+	`
+
+// GetBlobKeysInUse returns the storage keys of every blob a row refers to:
+// media files, thumbnails, unconfirmed uploads that expire after the given
+// time, and the blobs already queued for deletion
+func GetBlobKeysInUse(expiredBefore time.Time) (map[string]bool, *response.Error) {
+	rows, err := stmtMap["blobKeysInUse"].Query(expiredBefore)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting the blob keys in use: %v",
+			err,
+		)
+	}
+	defer rows.Close()
+
+	keys := make(map[string]bool)
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			return nil, response.NewErrorf(
+				http.StatusInternalServerError,
+				"error getting the blob keys in use: %v",
+				err,
+			)
+		}
+
+		keys[key] = true
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting the blob keys in use: %v",
+			err,
+		)
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,229 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/lib/pq"
+)
+
+// BoundaryAlertDB is a representation of a row in the boundary_alerts table.
+// An alert is raised when a team leaves its hunt's boundary and stays open
+// until the team returns.
+//
+// swagger:model BoundaryAlert
+type BoundaryAlertDB struct {
+
+	// The id of the alert
+	ID int `json:"alertID"`
+
+	// The id of the team that left the boundary
+	TeamID int `json:"teamID"`
+
+	// The id of the location the team was first seen outside at
+	LocationID int `json:"locationID,omitempty"`
+
+	// Where the team was first seen outside
+	Latitude float32 `json:"latitude"`
+
+	// Where the team was first seen outside
+	Longitude float32 `json:"longitude"`
+
+	// How far outside the boundary the team was, in meters
+	Distance float64 `json:"distance"`
+
+	// When the team was first seen outside
+	//
+	// swagger:strfmt date
+	LeftAt time.Time `json:"leftAt"`
+
+	// When the team was seen inside again, nil while it is still outside
+	//
+	// swagger:strfmt date
+	ReturnedAt *time.Time `json:"returnedAt,omitempty"`
+}
+
+// Open returns whether or not the team has not returned yet
+func (a *BoundaryAlertDB) Open() bool {
+	return a.ReturnedAt == nil
+}
+
+var boundaryAlertInsertScript = `
+	INSERT INTO boundary_alerts(team_id, location_id, latitude, longitude,
+		distance, left_at)
+	VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6)
+	ON CONFLICT (team_id) WHERE returned_at IS NULL DO NOTHING
+	RETURNING id;
+	`
+
+// Insert raises the alert unless the team already has an open alert. It
+// returns whether or not the alert was raised.
+func (a *BoundaryAlertDB) Insert() (bool, *response.Error) {
+	err := stmtMap["boundaryAlertInsert"].QueryRow(
+		a.TeamID,
+		a.LocationID,
+		a.Latitude,
+		a.Longitude,
+		a.Distance,
+		a.LeftAt,
+	).Scan(&a.ID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if ok && pqErr.Constraint == "boundary_alerts_team_id_fkey" {
+			return false, response.NewErrorf(
+				http.StatusBadRequest,
+				"team_id: team %d does not exist",
+				a.TeamID,
+			)
+		}
+
+		return false, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error raising boundary alert for team %d: %v",
+			a.TeamID,
+			err,
+		)
+	}
+
+	return true, nil
+}
+
+// the columns selected for a boundary alert, in the order scanBoundaryAlert
+// scans them
+const boundaryAlertColumns = `a.id, a.team_id, COALESCE(a.location_id, 0),
+	a.latitude, a.longitude, a.distance, a.left_at, a.returned_at`
+
+// scanBoundaryAlert scans a row of boundaryAlertColumns
+func scanBoundaryAlert(row interface{ Scan(...interface{}) error }) (*BoundaryAlertDB, error) {
+	a := BoundaryAlertDB{}
+	var returnedAt pq.NullTime
+	err := row.Scan(
+		&a.ID,
+		&a.TeamID,
+		&a.LocationID,
+		&a.Latitude,
+		&a.Longitude,
+		&a.Distance,
+		&a.LeftAt,
+		&returnedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if returnedAt.Valid {
+		a.ReturnedAt = &returnedAt.Time
+	}
+
+	return &a, nil
+}
+
+var boundaryAlertResolveScript = `
+	UPDATE boundary_alerts a
+	SET returned_at = $2
+	WHERE a.team_id = $1 AND a.returned_at IS NULL
+	RETURNING ` + boundaryAlertColumns + `;`
+
+// ResolveBoundaryAlert closes the open alert of the team with the given id
+// because it returned at the given time. nil is returned if the team has no
+// open alert.
+func ResolveBoundaryAlert(teamID int, at time.Time) (*BoundaryAlertDB, *response.Error) {
+	a, err := scanBoundaryAlert(stmtMap["boundaryAlertResolve"].QueryRow(teamID, at))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error resolving boundary alert for team %d: %v",
+			teamID,
+			err,
+		)
+	}
+
+	return a, nil
+}
+
+var boundaryAlertsForTeamScript = `
+	SELECT ` + boundaryAlertColumns + `
+	FROM boundary_alerts a
+	WHERE a.team_id = $1
+	ORDER BY a.left_at DESC, a.id DESC;`
+
+var boundaryAlertsForHuntScript = `
+	SELECT ` + boundaryAlertColumns + `
+	FROM boundary_alerts a
+	INNER JOIN teams t ON t.id = a.team_id
+	WHERE t.hunt_id = $1 AND (NOT $2 OR a.returned_at IS NULL)
+	ORDER BY a.left_at DESC, a.id DESC;`
+
+// queryBoundaryAlerts returns the alerts selected by the query with the given
+// args
+func queryBoundaryAlerts(query, desc string, args ...interface{}) ([]*BoundaryAlertDB, *response.Error) {
+	rows, err := stmtMap[query].Query(args...)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting boundary alerts for %s: %v",
+			desc,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	alerts := make([]*BoundaryAlertDB, 0)
+	for rows.Next() {
+		a, err := scanBoundaryAlert(rows)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error getting boundary alerts for %s: %v",
+				desc,
+				err,
+			)
+			break
+		}
+
+		alerts = append(alerts, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error getting boundary alerts for %s: %v",
+			desc,
+			err,
+		)
+	}
+
+	return alerts, e.GetError()
+}
+
+// GetBoundaryAlertsForTeam returns the alerts of the team with the given id,
+// the newest first
+func GetBoundaryAlertsForTeam(teamID int) ([]*BoundaryAlertDB, *response.Error) {
+	return queryBoundaryAlerts(
+		"boundaryAlertsForTeam",
+		fmt.Sprintf("team %d", teamID),
+		teamID,
+	)
+}
+
+// GetBoundaryAlertsForHunt returns the alerts of the teams in the hunt with
+// the given id, the newest first. Only the open alerts are returned if
+// openOnly is set.
+func GetBoundaryAlertsForHunt(huntID int, openOnly bool) ([]*BoundaryAlertDB, *response.Error) {
+	return queryBoundaryAlerts(
+		"boundaryAlertsForHunt",
+		fmt.Sprintf("hunt %d", huntID),
+		huntID,
+		openOnly,
+	)
+}
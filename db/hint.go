@@ -0,0 +1,323 @@
+package db
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/lib/pq"
+)
+
+// HintDB is a representation of a row in the item_hints table
+//
+// swagger:model Hint
+type HintDB struct {
+
+	// The id of the hint
+	//
+	// required: false
+	ID int `json:"hintID" valid:"int,optional"`
+
+	// The id of the item the hint is for
+	//
+	// required: false
+	ItemID int `json:"itemID" valid:"int,optional"`
+
+	// The order the hint is revealed in, starting at 1. The next position
+	// is used if one is not given.
+	//
+	// required: false
+	Position int `json:"position,omitempty" valid:"-"`
+
+	// The hint
+	//
+	// maximum length: 1000
+	// required: true
+	Body string `json:"body" valid:"-"`
+
+	// The points a team loses for unlocking the hint
+	//
+	// minimum: 0
+	// required: false
+	Cost int `json:"cost" valid:"-"`
+
+	// The number of minutes after the hunt starts that the hint is revealed
+	// to every team for free. Hints without one are only revealed when a
+	// team unlocks them.
+	//
+	// required: false
+	RevealAfter int `json:"revealAfter,omitempty" valid:"-"`
+}
+
+// Validate validates the hint
+func (h *HintDB) Validate(r *http.Request) *response.Error {
+	e := response.NewNilError()
+
+	body := strings.TrimSpace(h.Body)
+	if body == "" || len(body) > 1000 {
+		e.Add(http.StatusBadRequest, "body: a hint must be between 1 and 1000 characters")
+	}
+
+	if h.Position < 0 {
+		e.Add(http.StatusBadRequest, "position: must be a positive number")
+	}
+
+	if h.Cost < 0 {
+		e.Add(http.StatusBadRequest, "cost: can not be negative")
+	}
+
+	if h.RevealAfter < 0 {
+		e.Add(http.StatusBadRequest, "revealAfter: can not be negative")
+	}
+
+	return e.GetError()
+}
+
+// RevealedAt returns the time the hint is revealed to every team in a hunt
+// that starts at the given time. The zero time is returned if the hint is
+// only revealed when unlocked.
+func (h *HintDB) RevealedAt(start time.Time) time.Time {
+	if h.RevealAfter == 0 {
+		return time.Time{}
+	}
+
+	return start.Add(time.Duration(h.RevealAfter) * time.Minute)
+}
+
+var hintInsertScript = `
+	INSERT INTO item_hints(item_id, position, body, cost, reveal_after)
+	VALUES (
+		$1,
+		COALESCE(
+			NULLIF($2, 0),
+			(SELECT COALESCE(MAX(position), 0) + 1 FROM item_hints WHERE item_id = $1)
+		),
+		$3,
+		$4,
+		NULLIF($5, 0)
+	)
+	RETURNING id, position;
+	`
+
+// Insert adds the hint to its item
+func (h *HintDB) Insert() *response.Error {
+	err := stmtMap["hintInsert"].QueryRow(
+		h.ItemID,
+		h.Position,
+		h.Body,
+		h.Cost,
+		h.RevealAfter,
+	).Scan(&h.ID, &h.Position)
+	if err != nil {
+		return h.ParseError(err, "insert")
+	}
+
+	return nil
+}
+
+var hintsForItemScript = `
+	SELECT id, item_id, position, body, cost, COALESCE(reveal_after, 0)
+	FROM item_hints
+	WHERE item_id = $1
+	ORDER BY position ASC;
+	`
+
+// GetHintsForItem returns the hints for the given item in the order they are
+// revealed
+func GetHintsForItem(itemID int) ([]*HintDB, *response.Error) {
+	rows, err := stmtMap["hintsForItem"].Query(itemID)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting hints for item %d: %v",
+			itemID,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	hints := make([]*HintDB, 0)
+	for rows.Next() {
+		h := HintDB{}
+		err = rows.Scan(&h.ID, &h.ItemID, &h.Position, &h.Body, &h.Cost, &h.RevealAfter)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error getting hints for item %d: %v",
+				itemID,
+				err,
+			)
+			break
+		}
+
+		hints = append(hints, &h)
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error getting hints for item %d: %v",
+			itemID,
+			err,
+		)
+	}
+
+	return hints, e.GetError()
+}
+
+var hintDeleteScript = `
+	DELETE FROM item_hints
+	WHERE id = $1 AND item_id = $2;
+	`
+
+// DeleteHint deletes the hint with the given id AND itemID
+func DeleteHint(hintID, itemID int) *response.Error {
+	res, err := stmtMap["hintDelete"].Exec(hintID, itemID)
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error deleting hint %d: %v",
+			hintID,
+			err,
+		)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error deleting hint %d: %v",
+			hintID,
+			err,
+		)
+	}
+
+	if n < 1 {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"hint_id: there is no hint with id %d for item %d",
+			hintID,
+			itemID,
+		)
+	}
+
+	return nil
+}
+
+// ParseError maps a pq driver error to a response.Error
+func (h *HintDB) ParseError(err error, op string) *response.Error {
+	pqErr, ok := err.(*pq.Error)
+	if ok {
+		switch pqErr.Constraint {
+		case "hint_position_unique":
+			return response.NewErrorf(
+				http.StatusBadRequest,
+				"position: item %d already has a hint at position %d",
+				h.ItemID,
+				h.Position,
+			)
+		case "item_hints_item_id_fkey":
+			return response.NewErrorf(
+				http.StatusBadRequest,
+				"item_id: item %d does not exist",
+				h.ItemID,
+			)
+		}
+	}
+
+	return response.NewErrorf(
+		http.StatusInternalServerError,
+		"error executing hint %s: %v",
+		op,
+		err,
+	)
+}
+
+var hintUnlockInsertScript = `
+	INSERT INTO hint_unlocks(hint_id, team_id, cost)
+	SELECT id, $2, cost
+	FROM item_hints
+	WHERE id = $1
+	RETURNING cost;
+	`
+
+// UnlockHint records that the team unlocked the hint and returns the points
+// it cost the team
+func UnlockHint(hintID, teamID int) (int, *response.Error) {
+	var cost int
+	err := stmtMap["hintUnlockInsert"].QueryRow(hintID, teamID).Scan(&cost)
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if ok && pqErr.Constraint == "team_unlocks_hint_once" {
+			return 0, response.NewErrorf(
+				http.StatusBadRequest,
+				"hint_id: team %d has already unlocked hint %d",
+				teamID,
+				hintID,
+			)
+		}
+
+		return 0, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error unlocking hint %d for team %d: %v",
+			hintID,
+			teamID,
+			err,
+		)
+	}
+
+	return cost, nil
+}
+
+var hintsUnlockedForTeamScript = `
+	SELECT hu.hint_id
+	FROM hint_unlocks hu
+	INNER JOIN item_hints h ON h.id = hu.hint_id
+	WHERE hu.team_id = $1 AND h.item_id = $2;
+	`
+
+// GetUnlockedHintIDs returns the ids of the hints for the item that the team
+// has unlocked
+func GetUnlockedHintIDs(teamID, itemID int) (map[int]bool, *response.Error) {
+	rows, err := stmtMap["hintsUnlockedForTeam"].Query(teamID, itemID)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting unlocked hints for team %d: %v",
+			teamID,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	ids := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		err = rows.Scan(&id)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error getting unlocked hints for team %d: %v",
+				teamID,
+				err,
+			)
+			break
+		}
+
+		ids[id] = true
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error getting unlocked hints for team %d: %v",
+			teamID,
+			err,
+		)
+	}
+
+	return ids, e.GetError()
+}
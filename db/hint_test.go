@@ -0,0 +1,60 @@
+// +build unit
+
+package db_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+)
+
+func TestHintValidate(t *testing.T) {
+	cases := []struct {
+		name string
+		hint db.HintDB
+		key  string
+	}{
+		{name: "valid", hint: db.HintDB{Body: "look up", Cost: 5, RevealAfter: 30}},
+		{name: "free hint", hint: db.HintDB{Body: "look up"}},
+		{name: "blank body", hint: db.HintDB{Body: "  "}, key: "body"},
+		{name: "long body", hint: db.HintDB{Body: strings.Repeat("a", 1001)}, key: "body"},
+		{name: "negative cost", hint: db.HintDB{Body: "look up", Cost: -1}, key: "cost"},
+		{name: "negative position", hint: db.HintDB{Body: "look up", Position: -1}, key: "position"},
+		{name: "negative reveal", hint: db.HintDB{Body: "look up", RevealAfter: -1}, key: "revealAfter"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e := c.hint.Validate(r)
+			if c.key == "" {
+				if e != nil {
+					t.Errorf("expected no error got %s", e.JSON())
+				}
+				return
+			}
+
+			if e == nil {
+				t.Fatalf("expected a %s error got nil", c.key)
+			}
+			if _, ok := e.ErrorsByKey()[c.key]; !ok {
+				t.Errorf("expected a %s error got %s", c.key, e.JSON())
+			}
+		})
+	}
+}
+
+func TestHintRevealedAt(t *testing.T) {
+	start := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	h := db.HintDB{RevealAfter: 90}
+	if got := h.RevealedAt(start); !got.Equal(start.Add(90 * time.Minute)) {
+		t.Errorf("expected hint to be revealed at %v got %v", start.Add(90*time.Minute), got)
+	}
+
+	h = db.HintDB{}
+	if got := h.RevealedAt(start); !got.IsZero() {
+		t.Errorf("expected a hint without a reveal time to never be revealed got %v", got)
+	}
+}
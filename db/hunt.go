@@ -16,6 +16,15 @@ import (
 // HuntTbl is the name of the hunts db table
 const HuntTbl string = "hunts"
 
+const (
+	// DefaultMaxUploadSize is the largest media file, in bytes, that can be
+	// uploaded to a hunt that does not set its own max upload size
+	DefaultMaxUploadSize int64 = 10 << 20
+
+	// MaxUploadSizeLimit is the largest max upload size a hunt can set
+	MaxUploadSizeLimit int64 = 100 << 20
+)
+
 // A HuntDB is the representation of a row from the hunts table
 //
 // swagger:model Hunt
@@ -33,6 +42,27 @@ type HuntDB struct {
 	// required: true
 	MaxTeams int `json:"maxTeams" valid:"positive"`
 
+	// The maximum number of players that can be on a team in the Hunt. Zero
+	// means there is no limit.
+	//
+	// minimum: 1
+	// required: false
+	MaxPlayersPerTeam int `json:"maxPlayersPerTeam" valid:"positive,optional"`
+
+	// Whether or not players can create, join, and leave teams on their
+	// own. A pointer is used so PATCH can turn the setting off.
+	//
+	// required: false
+	SelfServiceTeams *bool `json:"selfServiceTeams,omitempty" valid:"-"`
+
+	// The largest media file, in bytes, that teams can upload. Zero means
+	// the default of 10 MB.
+	//
+	// minimum: 1
+	// maximum: 104857600
+	// required: false
+	MaxUploadSize int64 `json:"maxUploadSize" valid:"range(1|104857600),optional"`
+
 	// The id of the Hunt
 	//
 	// required: false
@@ -81,6 +111,12 @@ type HuntDB struct {
 	//
 	// required: true
 	Longitude float32 `json:"longitude" valid:"longitude,optional"`
+
+	// The distance, in meters, between the Hunt and the point used to search
+	// for it. Only location searches set this field.
+	//
+	// required: false
+	Distance float64 `json:"distance,omitempty" valid:"-"`
 }
 
 // Update updates the non-zero value fields in the HuntDB struct
@@ -109,6 +145,18 @@ func (h *HuntDB) GetTableColumnMap() pgsql.TableColumnMap {
 		tblColMap[HuntTbl]["max_teams"] = h.MaxTeams
 	}
 
+	if z.MaxPlayersPerTeam != h.MaxPlayersPerTeam {
+		tblColMap[HuntTbl]["max_players_per_team"] = h.MaxPlayersPerTeam
+	}
+
+	if h.SelfServiceTeams != nil {
+		tblColMap[HuntTbl]["self_service_teams"] = *h.SelfServiceTeams
+	}
+
+	if z.MaxUploadSize != h.MaxUploadSize {
+		tblColMap[HuntTbl]["max_upload_size"] = h.MaxUploadSize
+	}
+
 	if !h.StartTime.IsZero() {
 		tblColMap[HuntTbl]["start_time"] = h.StartTime
 	}
@@ -144,6 +192,16 @@ func (h *HuntDB) GetTableColumnMap() pgsql.TableColumnMap {
 	return tblColMap
 }
 
+// UploadLimit returns the largest media file, in bytes, that teams can upload
+// to the hunt
+func (h *HuntDB) UploadLimit() int64 {
+	if h.MaxUploadSize == 0 {
+		return DefaultMaxUploadSize
+	}
+
+	return h.MaxUploadSize
+}
+
 // Validate validates a HuntDB
 func (h *HuntDB) Validate(r *http.Request) *response.Error {
 	_, err := govalidator.ValidateStruct(h)
@@ -206,6 +264,9 @@ var huntsSelectScript = `
 		h.latitude, 
 		h.longitude, 
 		h.max_teams, 
+		COALESCE(h.max_players_per_team, 0),
+		h.self_service_teams,
+		COALESCE(h.max_upload_size, 0),
 		h.created_at,
 		h.creator_id,
 		u.username
@@ -235,6 +296,9 @@ func GetHunts() ([]*HuntDB, *response.Error) {
 			&hunt.Latitude,
 			&hunt.Longitude,
 			&hunt.MaxTeams,
+			&hunt.MaxPlayersPerTeam,
+			&hunt.SelfServiceTeams,
+			&hunt.MaxUploadSize,
 			&hunt.CreatedAt,
 			&hunt.CreatorID,
 			&hunt.CreatorUsername,
@@ -269,6 +333,9 @@ WITH hunts_for_user AS (
 		h.latitude, 
 		h.longitude, 
 		h.max_teams, 
+		COALESCE(h.max_players_per_team, 0),
+		h.self_service_teams,
+		COALESCE(h.max_upload_size, 0),
 		h.created_at,
 		h.creator_id,
 		u.username
@@ -301,6 +368,9 @@ func GetHuntsByUserID(userID int) ([]*HuntDB, *response.Error) {
 			&hunt.Latitude,
 			&hunt.Longitude,
 			&hunt.MaxTeams,
+			&hunt.MaxPlayersPerTeam,
+			&hunt.SelfServiceTeams,
+			&hunt.MaxUploadSize,
 			&hunt.CreatedAt,
 			&hunt.CreatorID,
 			&hunt.CreatorUsername,
@@ -320,6 +390,84 @@ func GetHuntsByUserID(userID int) ([]*HuntDB, *response.Error) {
 	return hunts, e.GetError()
 }
 
+var huntsNearSelectScript = `
+	WITH search AS (
+		SELECT ll_to_earth($1, $2) AS origin
+	)
+	SELECT 
+		h.name, 
+		h.id, 
+		h.start_time, 
+		h.end_time, 
+		h.location_name, 
+		h.latitude, 
+		h.longitude, 
+		h.max_teams, 
+		COALESCE(h.max_players_per_team, 0),
+		h.self_service_teams,
+		COALESCE(h.max_upload_size, 0),
+		h.created_at,
+		h.creator_id,
+		u.username,
+		earth_distance(s.origin, ll_to_earth(h.latitude, h.longitude)) AS distance
+	FROM search s
+	INNER JOIN hunts h 
+		ON earth_box(s.origin, $3) @> ll_to_earth(h.latitude, h.longitude)
+	INNER JOIN users u 
+		ON h.creator_id = u.id
+	WHERE earth_distance(s.origin, ll_to_earth(h.latitude, h.longitude)) <= $3
+	ORDER BY distance
+	LIMIT $4;
+	`
+
+// GetHuntsNear returns, ordered by distance, at most limit huntDBs that are
+// within radius meters of the given point. The earth_box condition lets the
+// query use the hunts_earth_location_idx index; the earth_distance condition
+// then trims the corners of the box. NOTE that it is possible to have returned
+// hunts and an error, check both
+func GetHuntsNear(latitude, longitude, radius float64, limit int) ([]*HuntDB, *response.Error) {
+	rows, err := stmtMap["huntsNearSelect"].Query(latitude, longitude, radius, limit)
+	if err != nil {
+		return nil, response.NewErrorf(http.StatusInternalServerError, "error getting hunts near location: %s", err.Error())
+	}
+	defer rows.Close()
+
+	hunts := make([]*HuntDB, 0)
+	e := response.NewNilError()
+	for rows.Next() {
+		hunt := HuntDB{}
+		huntErr := rows.Scan(
+			&hunt.Name,
+			&hunt.ID,
+			&hunt.StartTime,
+			&hunt.EndTime,
+			&hunt.LocationName,
+			&hunt.Latitude,
+			&hunt.Longitude,
+			&hunt.MaxTeams,
+			&hunt.MaxPlayersPerTeam,
+			&hunt.SelfServiceTeams,
+			&hunt.MaxUploadSize,
+			&hunt.CreatedAt,
+			&hunt.CreatorID,
+			&hunt.CreatorUsername,
+			&hunt.Distance,
+		)
+		if huntErr != nil {
+			e.Addf(http.StatusInternalServerError, "error getting hunt near location: %s", huntErr.Error())
+			break
+		}
+		hunts = append(hunts, &hunt)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		e.Addf(http.StatusInternalServerError, "error getting hunt near location: %s", err.Error())
+	}
+
+	return hunts, e.GetError()
+}
+
 var huntSelectScript = `
 	SELECT 
 		h.name, 
@@ -330,6 +478,9 @@ var huntSelectScript = `
 		h.latitude, 
 		h.longitude, 
 		h.max_teams, 
+		COALESCE(h.max_players_per_team, 0),
+		h.self_service_teams,
+		COALESCE(h.max_upload_size, 0),
 		h.created_at,
 		h.creator_id,
 		u.username
@@ -351,6 +502,9 @@ func GetHunt(huntID int) (*HuntDB, *response.Error) {
 		&h.Latitude,
 		&h.Longitude,
 		&h.MaxTeams,
+		&h.MaxPlayersPerTeam,
+		&h.SelfServiceTeams,
+		&h.MaxUploadSize,
 		&h.CreatedAt,
 		&h.CreatorID,
 		&h.CreatorUsername,
@@ -377,6 +531,9 @@ var huntGetByCreatorAndNameScript = `
 		h.latitude, 
 		h.longitude, 
 		h.max_teams, 
+		COALESCE(h.max_players_per_team, 0),
+		h.self_service_teams,
+		COALESCE(h.max_upload_size, 0),
 		h.created_at,
 		h.creator_id,
 		u.username
@@ -398,6 +555,9 @@ func GetHuntByCreatorAndName(creator, name string) (*HuntDB, *response.Error) {
 		&h.Latitude,
 		&h.Longitude,
 		&h.MaxTeams,
+		&h.MaxPlayersPerTeam,
+		&h.SelfServiceTeams,
+		&h.MaxUploadSize,
 		&h.CreatedAt,
 		&h.CreatorID,
 		&h.CreatorUsername,
@@ -461,9 +621,13 @@ var huntInsertScript = `
 		location_name, 
 		latitude, 
 		longitude,
-		creator_id
+		creator_id,
+		max_players_per_team,
+		self_service_teams,
+		max_upload_size
 	)
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, 0), COALESCE($10, false), 
+		NULLIF($11, 0))
 	RETURNING id, created_at;
 	`
 
@@ -471,7 +635,8 @@ var huntInsertScript = `
 // create_at timestamp
 func (h *HuntDB) Insert() *response.Error {
 	err := stmtMap["huntInsert"].QueryRow(h.Name, h.MaxTeams, h.StartTime, h.EndTime,
-		h.LocationName, h.Latitude, h.Longitude, h.CreatorID).Scan(&h.ID, &h.CreatedAt)
+		h.LocationName, h.Latitude, h.Longitude, h.CreatorID, h.MaxPlayersPerTeam,
+		h.SelfServiceTeams, h.MaxUploadSize).Scan(&h.ID, &h.CreatedAt)
 	if err != nil {
 		return h.ParseError(err, "insert")
 	}
@@ -492,6 +657,16 @@ func (h *HuntDB) ParseError(err error, op string) *response.Error {
 					"huntName: There is already a hunt with the name %s",
 					h.Name,
 				)
+			case "positive_max_players":
+				return response.NewError(
+					http.StatusBadRequest,
+					"maxPlayersPerTeam: must be positive",
+				)
+			case "positive_max_upload_size":
+				return response.NewError(
+					http.StatusBadRequest,
+					"maxUploadSize: must be positive",
+				)
 			}
 		}
 	}
@@ -0,0 +1,220 @@
+package db
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/geo"
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/lib/pq"
+)
+
+// maxBoundaryPoints is the most vertices a hunt's boundary can have
+const maxBoundaryPoints = 1000
+
+// HuntBoundaryDB is a representation of a row in the hunt_boundaries table.
+// The boundary is the area a hunt is played in.
+//
+// swagger:model HuntBoundary
+type HuntBoundaryDB struct {
+
+	// The id of the hunt
+	//
+	// required: false
+	HuntID int `json:"huntID" valid:"-"`
+
+	// The vertices of the boundary's polygon, in order. The first vertex is
+	// not repeated at the end.
+	//
+	// minimum items: 3
+	// maximum items: 1000
+	// required: true
+	Points []geo.Point `json:"points" valid:"-"`
+
+	// Whether or not submissions made outside the boundary are rejected,
+	// rather than only flagged
+	//
+	// required: false
+	RejectSubmissions bool `json:"rejectSubmissions" valid:"-"`
+
+	// The last time the boundary was changed
+	//
+	// required: false
+	// swagger:strfmt date
+	UpdatedAt time.Time `json:"updatedAt" valid:"-"`
+}
+
+// Validate validates the boundary. A first vertex repeated at the end is
+// removed.
+func (b *HuntBoundaryDB) Validate(r *http.Request) *response.Error {
+	if n := len(b.Points); n > 1 && b.Points[0] == b.Points[n-1] {
+		b.Points = b.Points[:n-1]
+	}
+
+	e := response.NewNilError()
+	if len(b.Points) < 3 || len(b.Points) > maxBoundaryPoints {
+		e.Addf(
+			http.StatusBadRequest,
+			"points: a boundary must have between 3 and %d points",
+			maxBoundaryPoints,
+		)
+	}
+
+	for i, p := range b.Points {
+		if p.Latitude < -90 || p.Latitude > 90 || p.Longitude < -180 || p.Longitude > 180 {
+			e.Addf(
+				http.StatusBadRequest,
+				"points: point %d is not a valid latitude and longitude",
+				i,
+			)
+		}
+	}
+
+	return e.GetError()
+}
+
+// Contains returns whether or not the point is inside the boundary
+func (b *HuntBoundaryDB) Contains(lat, lng float64) bool {
+	return geo.Contains(b.Points, lat, lng)
+}
+
+// DistanceOutside returns how far, in meters, the point is outside the
+// boundary, 0 if it is inside
+func (b *HuntBoundaryDB) DistanceOutside(lat, lng float64) float64 {
+	if b.Contains(lat, lng) {
+		return 0
+	}
+
+	return geo.DistanceToEdge(b.Points, lat, lng)
+}
+
+var huntBoundaryUpsertScript = `
+	INSERT INTO hunt_boundaries(hunt_id, latitudes, longitudes, reject_submissions)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (hunt_id) DO UPDATE
+	SET latitudes = EXCLUDED.latitudes,
+		longitudes = EXCLUDED.longitudes,
+		reject_submissions = EXCLUDED.reject_submissions,
+		updated_at = NOW()
+	RETURNING updated_at;
+	`
+
+// Upsert sets the hunt's boundary, replacing the one it had
+func (b *HuntBoundaryDB) Upsert() *response.Error {
+	lats := make([]float64, 0, len(b.Points))
+	lngs := make([]float64, 0, len(b.Points))
+	for _, p := range b.Points {
+		lats = append(lats, p.Latitude)
+		lngs = append(lngs, p.Longitude)
+	}
+
+	err := stmtMap["huntBoundaryUpsert"].QueryRow(
+		b.HuntID,
+		pq.Array(lats),
+		pq.Array(lngs),
+		b.RejectSubmissions,
+	).Scan(&b.UpdatedAt)
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if ok && pqErr.Constraint == "hunt_boundaries_hunt_id_fkey" {
+			return response.NewErrorf(
+				http.StatusBadRequest,
+				"hunt_id: hunt %d does not exist",
+				b.HuntID,
+			)
+		}
+		if ok && pqErr.Constraint == "boundary_is_polygon" {
+			return response.NewError(
+				http.StatusBadRequest,
+				"points: a boundary must have at least 3 points",
+			)
+		}
+
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error setting the boundary of hunt %d: %v",
+			b.HuntID,
+			err,
+		)
+	}
+
+	return nil
+}
+
+var huntBoundarySelectScript = `
+	SELECT latitudes, longitudes, reject_submissions, updated_at
+	FROM hunt_boundaries
+	WHERE hunt_id = $1;
+	`
+
+// GetHuntBoundary returns the boundary of the hunt with the given id. nil is
+// returned if the hunt has none.
+func GetHuntBoundary(huntID int) (*HuntBoundaryDB, *response.Error) {
+	b := HuntBoundaryDB{HuntID: huntID}
+	var lats, lngs []float64
+	err := stmtMap["huntBoundarySelect"].QueryRow(huntID).Scan(
+		pq.Array(&lats),
+		pq.Array(&lngs),
+		&b.RejectSubmissions,
+		&b.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting the boundary of hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+
+	b.Points = make([]geo.Point, 0, len(lats))
+	for i := range lats {
+		if i < len(lngs) {
+			b.Points = append(b.Points, geo.Point{Latitude: lats[i], Longitude: lngs[i]})
+		}
+	}
+
+	return &b, nil
+}
+
+var huntBoundaryDeleteScript = `
+	DELETE FROM hunt_boundaries
+	WHERE hunt_id = $1;
+	`
+
+// DeleteHuntBoundary removes the boundary of the hunt with the given id
+func DeleteHuntBoundary(huntID int) *response.Error {
+	res, err := stmtMap["huntBoundaryDelete"].Exec(huntID)
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error deleting the boundary of hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error deleting the boundary of hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+
+	if n < 1 {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"hunt_id: hunt %d does not have a boundary",
+			huntID,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+// +build unit
+
+package db_test
+
+import (
+	"testing"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/geo"
+)
+
+func TestHuntBoundaryValidate(t *testing.T) {
+	square := []geo.Point{
+		{Latitude: 40.0, Longitude: -74.0},
+		{Latitude: 40.0, Longitude: -73.9},
+		{Latitude: 40.1, Longitude: -73.9},
+		{Latitude: 40.1, Longitude: -74.0},
+	}
+
+	closed := db.HuntBoundaryDB{Points: append(append([]geo.Point{}, square...), square[0])}
+	if e := closed.Validate(nil); e != nil {
+		t.Fatalf("expected a closed polygon to be valid got %s", e.JSON())
+	}
+	if len(closed.Points) != len(square) {
+		t.Errorf("expected the repeated first point to be removed got %d points", len(closed.Points))
+	}
+
+	line := db.HuntBoundaryDB{Points: square[:2]}
+	if line.Validate(nil) == nil {
+		t.Errorf("expected a boundary with 2 points to be invalid")
+	}
+
+	invalid := db.HuntBoundaryDB{Points: append(append([]geo.Point{}, square[:3]...), geo.Point{Latitude: 91})}
+	if invalid.Validate(nil) == nil {
+		t.Errorf("expected a boundary with a latitude of 91 to be invalid")
+	}
+}
+
+func TestHuntBoundaryDistanceOutside(t *testing.T) {
+	b := db.HuntBoundaryDB{Points: []geo.Point{
+		{Latitude: 40.0, Longitude: -74.0},
+		{Latitude: 40.0, Longitude: -73.9},
+		{Latitude: 40.1, Longitude: -73.9},
+		{Latitude: 40.1, Longitude: -74.0},
+	}}
+
+	if d := b.DistanceOutside(40.05, -73.95); d != 0 {
+		t.Errorf("expected a point inside to be 0 meters outside got %.0f", d)
+	}
+
+	expected := geo.Distance(40.1, -73.95, 40.11, -73.95)
+	if d := b.DistanceOutside(40.11, -73.95); d < expected-10 || d > expected+10 {
+		t.Errorf("expected a point outside to be %.0f meters outside got %.0f", expected, d)
+	}
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// HuntEventDB is a representation of a row in the hunt_events table. Events
+// are streamed to the members of a hunt as they happen.
+//
+// swagger:model HuntEvent
+type HuntEventDB struct {
+
+	// The id of the event. Ids increase as events are published.
+	//
+	// required: true
+	ID int64 `json:"eventID"`
+
+	// The id of the hunt the event happened in
+	//
+	// required: true
+	HuntID int `json:"huntID"`
+
+	// The id of the team the event is about, if any
+	//
+	// required: false
+	TeamID int `json:"teamID,omitempty"`
+
+	// The type of the event, like submission, score, or announcement
+	//
+	// required: true
+	Type string `json:"type"`
+
+	// The details of the event, which depend on its type
+	//
+	// required: true
+	Data json.RawMessage `json:"data"`
+
+	// The time the event was published
+	//
+	// required: true
+	// swagger:strfmt date
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+var huntEventInsertScript = `
+	INSERT INTO hunt_events(hunt_id, team_id, type, data)
+	VALUES ($1, NULLIF($2, 0), $3, $4)
+	RETURNING id, created_at;
+	`
+
+// Insert stores the event and sets its id
+func (e *HuntEventDB) Insert() *response.Error {
+	err := stmtMap["huntEventInsert"].QueryRow(
+		e.HuntID,
+		e.TeamID,
+		e.Type,
+		[]byte(e.Data),
+	).Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error storing %s event for hunt %d: %v",
+			e.Type,
+			e.HuntID,
+			err,
+		)
+	}
+
+	return nil
+}
+
+var huntEventsAfterScript = `
+	SELECT id, hunt_id, COALESCE(team_id, 0), type, data, created_at
+	FROM hunt_events
+	WHERE hunt_id = $1 AND id > $2
+	ORDER BY id ASC
+	LIMIT $3;
+	`
+
+// GetHuntEventsAfter returns at most limit of the hunt's events that came
+// after the event with the given id, oldest first
+func GetHuntEventsAfter(huntID int, afterID int64, limit int) ([]*HuntEventDB, *response.Error) {
+	rows, err := stmtMap["huntEventsAfter"].Query(huntID, afterID, limit)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting events for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	events := make([]*HuntEventDB, 0)
+	for rows.Next() {
+		ev := HuntEventDB{}
+		var data []byte
+		err = rows.Scan(&ev.ID, &ev.HuntID, &ev.TeamID, &ev.Type, &data, &ev.CreatedAt)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error getting events for hunt %d: %v",
+				huntID,
+				err,
+			)
+			break
+		}
+
+		ev.Data = json.RawMessage(data)
+		events = append(events, &ev)
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error getting events for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+
+	return events, e.GetError()
+}
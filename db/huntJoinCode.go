@@ -0,0 +1,390 @@
+package db
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/lib/pq"
+)
+
+// HuntJoinCodeDB is a representation of a row in the hunt_join_codes table
+//
+// swagger:model HuntJoinCode
+type HuntJoinCodeDB struct {
+
+	// The id of the join code
+	//
+	// required: false
+	ID int `json:"joinCodeID" valid:"int,optional"`
+
+	// The id of the hunt the code joins
+	//
+	// required: false
+	HuntID int `json:"huntID" valid:"int,optional"`
+
+	// The code players enter to join the hunt. Codes are generated by
+	// the server.
+	//
+	// required: false
+	Code string `json:"code" valid:"-"`
+
+	// The id of the user that created the code
+	//
+	// required: false
+	CreatorID int `json:"creatorID" valid:"int,optional"`
+
+	// The number of times the code can be redeemed. Zero means there
+	// is no limit.
+	//
+	// required: false
+	MaxUses int `json:"maxUses" valid:"positive,optional"`
+
+	// The number of times the code has been redeemed
+	//
+	// required: false
+	Uses int `json:"uses" valid:"-"`
+
+	// The time the code expires. The zero value means the code never
+	// expires.
+	//
+	// required: false
+	// swagger:strfmt date
+	ExpiresAt time.Time `json:"expiresAt" valid:"timeNotPast,optional"`
+
+	// The time the code was created
+	//
+	// required: false
+	// swagger:strfmt date
+	CreatedAt time.Time `json:"createdAt" valid:"-"`
+}
+
+// Validate validates the struct
+func (c *HuntJoinCodeDB) Validate(r *http.Request) *response.Error {
+	_, err := govalidator.ValidateStruct(c)
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"error validating hunt join code: %v",
+			err,
+		)
+	}
+
+	return nil
+}
+
+// Expired returns whether or not the code can no longer be redeemed
+// because of its expiration time
+func (c *HuntJoinCodeDB) Expired() bool {
+	return !c.ExpiresAt.IsZero() && !c.ExpiresAt.After(time.Now())
+}
+
+// UsedUp returns whether or not the code has been redeemed its maximum
+// number of times
+func (c *HuntJoinCodeDB) UsedUp() bool {
+	return c.MaxUses != 0 && c.Uses >= c.MaxUses
+}
+
+// scan scans a hunt_join_codes row into the struct. The columns are expected
+// in the order used by huntJoinCodeSelectScript
+func (c *HuntJoinCodeDB) scan(row interface{ Scan(...interface{}) error }) error {
+	var maxUses sql.NullInt64
+	var expiresAt pq.NullTime
+
+	err := row.Scan(
+		&c.ID,
+		&c.HuntID,
+		&c.Code,
+		&c.CreatorID,
+		&maxUses,
+		&c.Uses,
+		&expiresAt,
+		&c.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	c.MaxUses = int(maxUses.Int64)
+	c.ExpiresAt = expiresAt.Time
+
+	return nil
+}
+
+var huntJoinCodeInsertScript = `
+	INSERT INTO hunt_join_codes(hunt_id, code, creator_id, max_uses, expires_at)
+	VALUES ($1, $2, $3, NULLIF($4, 0), $5)
+	RETURNING id, uses, created_at;
+	`
+
+// Insert inserts the join code into the db. The id, uses, and createdAt
+// fields are written back to the struct.
+func (c *HuntJoinCodeDB) Insert() *response.Error {
+	var expiresAt pq.NullTime
+	if !c.ExpiresAt.IsZero() {
+		expiresAt = pq.NullTime{Time: c.ExpiresAt, Valid: true}
+	}
+
+	err := stmtMap["huntJoinCodeInsert"].QueryRow(
+		c.HuntID,
+		c.Code,
+		c.CreatorID,
+		c.MaxUses,
+		expiresAt,
+	).Scan(&c.ID, &c.Uses, &c.CreatedAt)
+	if err != nil {
+		return c.ParseError(err, "insert")
+	}
+
+	return nil
+}
+
+var huntJoinCodeSelectScript = `
+	SELECT id, hunt_id, code, creator_id, max_uses, uses, expires_at, created_at
+	FROM hunt_join_codes
+	WHERE id = $1;
+	`
+
+// GetHuntJoinCode returns the join code with the given id
+func GetHuntJoinCode(id int) (*HuntJoinCodeDB, *response.Error) {
+	c := HuntJoinCodeDB{}
+	err := c.scan(stmtMap["huntJoinCodeSelect"].QueryRow(id))
+	if err == sql.ErrNoRows {
+		return nil, response.NewErrorf(
+			http.StatusBadRequest,
+			"joinCodeID: no join code with id %d",
+			id,
+		)
+	}
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting join code %d: %v",
+			id,
+			err,
+		)
+	}
+
+	return &c, nil
+}
+
+var huntJoinCodeByCodeScript = `
+	SELECT id, hunt_id, code, creator_id, max_uses, uses, expires_at, created_at
+	FROM hunt_join_codes
+	WHERE code = $1;
+	`
+
+// GetHuntJoinCodeByCode returns the join code with the given code
+func GetHuntJoinCodeByCode(code string) (*HuntJoinCodeDB, *response.Error) {
+	c := HuntJoinCodeDB{}
+	err := c.scan(stmtMap["huntJoinCodeByCode"].QueryRow(code))
+	if err == sql.ErrNoRows {
+		return nil, response.NewError(
+			http.StatusBadRequest,
+			"code: invalid join code",
+		)
+	}
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting join code: %v",
+			err,
+		)
+	}
+
+	return &c, nil
+}
+
+var huntJoinCodesForHuntScript = `
+	SELECT id, hunt_id, code, creator_id, max_uses, uses, expires_at, created_at
+	FROM hunt_join_codes
+	WHERE hunt_id = $1
+	ORDER BY created_at;
+	`
+
+// GetJoinCodesForHunt returns all the join codes for the given hunt. It
+// is possible to return both results and an error
+func GetJoinCodesForHunt(huntID int) ([]*HuntJoinCodeDB, *response.Error) {
+	rows, err := stmtMap["huntJoinCodesForHunt"].Query(huntID)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting join codes for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	codes := make([]*HuntJoinCodeDB, 0)
+	for rows.Next() {
+		c := HuntJoinCodeDB{}
+		err = c.scan(rows)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error getting join code for hunt %d: %v",
+				huntID,
+				err,
+			)
+			break
+		}
+		codes = append(codes, &c)
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error getting join code for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+
+	return codes, e.GetError()
+}
+
+var huntJoinCodeRedeemScript = `
+	UPDATE hunt_join_codes
+	SET uses = uses + 1
+	WHERE code = $1
+		AND (max_uses IS NULL OR uses < max_uses)
+		AND (expires_at IS NULL OR expires_at > NOW())
+	RETURNING id, hunt_id, code, creator_id, max_uses, uses, expires_at, created_at;
+	`
+
+// RedeemHuntJoinCode uses up one of the redemptions of the given code and
+// returns the redeemed code. The row is updated with a single statement so
+// concurrent redemptions can never exceed the code's max uses.
+func RedeemHuntJoinCode(code string) (*HuntJoinCodeDB, *response.Error) {
+	c := HuntJoinCodeDB{}
+	err := c.scan(stmtMap["huntJoinCodeRedeem"].QueryRow(code))
+	if err == nil {
+		return &c, nil
+	}
+
+	if err != sql.ErrNoRows {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error redeeming join code: %v",
+			err,
+		)
+	}
+
+	// nothing was updated so find out why
+	existing, e := GetHuntJoinCodeByCode(code)
+	if e != nil {
+		return nil, e
+	}
+
+	if existing.Expired() {
+		return nil, response.NewError(
+			http.StatusBadRequest,
+			"code: join code has expired",
+		)
+	}
+
+	return nil, response.NewError(
+		http.StatusBadRequest,
+		"code: join code has reached its maximum number of uses",
+	)
+}
+
+var huntJoinCodeReleaseScript = `
+	UPDATE hunt_join_codes
+	SET uses = uses - 1
+	WHERE id = $1 AND uses > 0;
+	`
+
+// ReleaseHuntJoinCode gives back a redemption of the join code with the given
+// id. It is used when joining the hunt fails after the code was redeemed.
+func ReleaseHuntJoinCode(id int) *response.Error {
+	_, err := stmtMap["huntJoinCodeRelease"].Exec(id)
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error releasing join code %d: %v",
+			id,
+			err,
+		)
+	}
+
+	return nil
+}
+
+var huntJoinCodeDeleteScript = `
+	DELETE FROM hunt_join_codes
+	WHERE id = $1 AND hunt_id = $2;
+	`
+
+// DeleteHuntJoinCode deletes the join code with the given id AND huntID
+func DeleteHuntJoinCode(id, huntID int) *response.Error {
+	res, err := stmtMap["huntJoinCodeDelete"].Exec(id, huntID)
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error deleting join code %d: %v",
+			id,
+			err,
+		)
+	}
+
+	numRows, err := res.RowsAffected()
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error deleting join code %d: %v",
+			id,
+			err,
+		)
+	}
+
+	if numRows < 1 {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"there is no join code with id %d and hunt id %d",
+			id,
+			huntID,
+		)
+	}
+
+	return nil
+}
+
+// ParseError maps a pq driver error to a response.Error
+func (c *HuntJoinCodeDB) ParseError(err error, op string) *response.Error {
+	pqErr, ok := err.(*pq.Error)
+	if ok {
+		if pqErr.Constraint != "" {
+			switch pqErr.Constraint {
+			case "hunt_join_codes_hunt_id_fkey":
+				return response.NewErrorf(
+					http.StatusBadRequest,
+					"huntID: hunt %d does not exist",
+					c.HuntID,
+				)
+			case "join_code_unique":
+				return response.NewErrorf(
+					http.StatusBadRequest,
+					"code: %s is already in use",
+					c.Code,
+				)
+			case "positive_max_uses":
+				return response.NewError(
+					http.StatusBadRequest,
+					"maxUses: must be positive",
+				)
+			}
+		}
+	}
+
+	return response.NewErrorf(
+		http.StatusInternalServerError,
+		"HuntJoinCode: db error on hunt_join_codes operation %s: %v",
+		op,
+		err,
+	)
+}
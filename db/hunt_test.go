@@ -0,0 +1,63 @@
+// +build unit
+
+package db_test
+
+import (
+	"testing"
+
+	"github.com/cljohnson4343/scavenge/db"
+)
+
+func TestGetTableColumnMapHuntDB(t *testing.T) {
+	caseStr := "zero case"
+	hunt := db.HuntDB{}
+
+	tblColMap := hunt.GetTableColumnMap()
+	if len(tblColMap["hunts"]) != 0 {
+		t.Errorf("%s: expected no columns", caseStr)
+	}
+
+	on, off := true, false
+	tables := []struct {
+		hunt     db.HuntDB
+		fieldStr string
+		length   int
+		expected interface{}
+	}{
+		{db.HuntDB{MaxTeams: 43}, "max_teams", 1, 43},
+		{db.HuntDB{MaxPlayersPerTeam: 4}, "max_players_per_team", 1, 4},
+		{db.HuntDB{SelfServiceTeams: &on}, "self_service_teams", 1, true},
+		{db.HuntDB{SelfServiceTeams: &off}, "self_service_teams", 1, false},
+		{db.HuntDB{MaxUploadSize: 1 << 20}, "max_upload_size", 1, int64(1 << 20)},
+	}
+
+	for _, c := range tables {
+		tblColMap = c.hunt.GetTableColumnMap()
+
+		if len(tblColMap["hunts"]) != c.length {
+			t.Errorf("%s: expected length %d but got %d", c.fieldStr, c.length, len(tblColMap["hunts"]))
+			continue
+		}
+
+		v, ok := tblColMap["hunts"][c.fieldStr]
+		if !ok {
+			t.Errorf("%s: expected a %s value", c.fieldStr, c.fieldStr)
+			continue
+		}
+		if v != c.expected {
+			t.Errorf("%s: expected %v but got %v", c.fieldStr, c.expected, v)
+		}
+	}
+}
+
+func TestHuntDBUploadLimit(t *testing.T) {
+	hunt := db.HuntDB{}
+	if limit := hunt.UploadLimit(); limit != db.DefaultMaxUploadSize {
+		t.Errorf("expected the default limit %d but got %d", db.DefaultMaxUploadSize, limit)
+	}
+
+	hunt.MaxUploadSize = 1 << 20
+	if limit := hunt.UploadLimit(); limit != 1<<20 {
+		t.Errorf("expected the hunt's limit %d but got %d", 1<<20, limit)
+	}
+}
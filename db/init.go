@@ -57,53 +57,134 @@ func init() {
 var stmtMap = map[string]*sql.Stmt{}
 
 var scriptMap = map[string]string{
-	"huntInvitationDelete":    huntInvitationDeleteScript,
-	"huntInvitationInsert":    huntInvitationInsertScript,
-	"huntInvitationSelect":    huntInvitationSelectScript,
-	"huntInvitationsByUserID": huntInvitationsByUserIDScript,
-	"huntInvitationsForHunt":  huntInvitationsForHuntScript,
-	"huntGetByCreatorAndName": huntGetByCreatorAndNameScript,
-	"huntsByUserIDSelect":     huntsByUserIDSelectScript,
-	"huntSelect":              huntSelectScript,
-	"huntDelete":              huntDeleteScript,
-	"huntInsert":              huntInsertScript,
-	"huntsSelect":             huntsSelectScript,
-	"itemSelect":              itemSelectScript,
-	"itemDelete":              itemDeleteScript,
-	"itemInsert":              itemInsertScript,
-	"itemsSelect":             itemsSelectScript,
-	"locationsForTeam":        locationsForTeamScript,
-	"locationInsert":          locationInsertScript,
-	"locationDelete":          locationDeleteScript,
-	"mediaMetasForTeam":       mediaMetasForTeamScript,
-	"mediaMetaInsert":         mediaMetaInsertScript,
-	"mediaMetaDelete":         mediaMetaDeleteScript,
-	"permissionInsert":        permissionInsertScript,
-	"permissionsForUser":      permissionsForUserScript,
-	"playerAddToHunt":         playerAddToHuntScript,
-	"playerRemoveFromHunt":    playerRemoveFromHuntScript,
-	"playersGetForHunt":       playersGetForHuntScript,
-	"roleInsert":              roleInsertScript,
-	"roleRemove":              roleRemoveScript,
-	"rolesDeleteByRegex":      rolesDeleteByRegexScript,
-	"rolesForUser":            rolesForUserScript,
-	"sessionInsert":           sessionInsertScript,
-	"sessionGetForUser":       sessionGetForUserScript,
-	"sessionGet":              sessionGetScript,
-	"sessionDelete":           sessionDeleteScript,
-	"teamSelect":              teamSelectScript,
-	"teamDelete":              teamDeleteScript,
-	"teamInsert":              teamInsertScript,
-	"teamsSelect":             teamsSelectScript,
-	"teamsWithHuntIDSelect":   teamsWithHuntIDSelectScript,
-	"teamPoints":              teamPointsScript,
-	"teamAddPlayer":           teamAddPlayerScript,
-	"teamRemovePlayer":        teamRemovePlayerScript,
-	"teamGetPlayers":          teamGetPlayersScript,
-	"userInsert":              userInsertScript,
-	"userGet":                 userGetScript,
-	"userGetByUsername":       userGetByUsernameScript,
-	"userDelete":              userDeleteScript,
+	"blobKeysInUse":              blobKeysInUseScript,
+	"blobOutboxDelete":           blobOutboxDeleteScript,
+	"blobOutboxFail":             blobOutboxFailScript,
+	"blobOutboxSelect":           blobOutboxSelectScript,
+	"boundaryAlertInsert":        boundaryAlertInsertScript,
+	"boundaryAlertResolve":       boundaryAlertResolveScript,
+	"boundaryAlertsForHunt":      boundaryAlertsForHuntScript,
+	"boundaryAlertsForTeam":      boundaryAlertsForTeamScript,
+	"hintDelete":                 hintDeleteScript,
+	"hintInsert":                 hintInsertScript,
+	"hintsForItem":               hintsForItemScript,
+	"hintsUnlockedForTeam":       hintsUnlockedForTeamScript,
+	"hintUnlockInsert":           hintUnlockInsertScript,
+	"huntBoundaryDelete":         huntBoundaryDeleteScript,
+	"huntBoundarySelect":         huntBoundarySelectScript,
+	"huntBoundaryUpsert":         huntBoundaryUpsertScript,
+	"huntEventInsert":            huntEventInsertScript,
+	"huntEventsAfter":            huntEventsAfterScript,
+	"huntInvitationDelete":       huntInvitationDeleteScript,
+	"huntInvitationInsert":       huntInvitationInsertScript,
+	"huntInvitationSelect":       huntInvitationSelectScript,
+	"huntInvitationsByUserID":    huntInvitationsByUserIDScript,
+	"huntInvitationsForHunt":     huntInvitationsForHuntScript,
+	"huntJoinCodeByCode":         huntJoinCodeByCodeScript,
+	"huntJoinCodeDelete":         huntJoinCodeDeleteScript,
+	"huntJoinCodeInsert":         huntJoinCodeInsertScript,
+	"huntJoinCodeRedeem":         huntJoinCodeRedeemScript,
+	"huntJoinCodeRelease":        huntJoinCodeReleaseScript,
+	"huntJoinCodeSelect":         huntJoinCodeSelectScript,
+	"huntJoinCodesForHunt":       huntJoinCodesForHuntScript,
+	"huntGetByCreatorAndName":    huntGetByCreatorAndNameScript,
+	"huntsByUserIDSelect":        huntsByUserIDSelectScript,
+	"huntSelect":                 huntSelectScript,
+	"huntDelete":                 huntDeleteScript,
+	"huntInsert":                 huntInsertScript,
+	"huntsSelect":                huntsSelectScript,
+	"huntsNearSelect":            huntsNearSelectScript,
+	"itemClaimInsert":            itemClaimInsertScript,
+	"itemDependenciesDelete":     itemDependenciesDeleteScript,
+	"itemDependencyInsert":       itemDependencyInsertScript,
+	"itemSelect":                 itemSelectScript,
+	"itemDelete":                 itemDeleteScript,
+	"itemInsert":                 itemInsertScript,
+	"itemUpsert":                 itemUpsertScript,
+	"itemsSelect":                itemsSelectScript,
+	"itemsCompletedByTeam":       itemsCompletedByTeamScript,
+	"itemSubmissionsForHunt":     itemSubmissionsForHuntScript,
+	"locationsForTeam":           locationsForTeamScript,
+	"locationsForTeamBetween":    locationsForTeamBetweenScript,
+	"locationsForHuntBetween":    locationsForHuntBetweenScript,
+	"locationInsert":             locationInsertScript,
+	"locationsInsert":            locationsInsertScript,
+	"locationDelete":             locationDeleteScript,
+	"locationPreviousForTeam":    locationPreviousForTeamScript,
+	"locationsLatestForHunt":     locationsLatestForHuntScript,
+	"mediaCommentCountsForHunt":  mediaCommentCountsForHuntScript,
+	"mediaCommentDelete":         mediaCommentDeleteScript,
+	"mediaCommentInsert":         mediaCommentInsertScript,
+	"mediaCommentsForMedia":      mediaCommentsForMediaScript,
+	"mediaForHunt":               mediaForHuntScript,
+	"mediaHunt":                  mediaHuntScript,
+	"mediaMetasForTeam":          mediaMetasForTeamScript,
+	"mediaMetaInsert":            mediaMetaInsertScript,
+	"mediaMetaDelete":            mediaMetaDeleteScript,
+	"mediaReactionCountsForHunt": mediaReactionCountsForHuntScript,
+	"mediaReactionDelete":        mediaReactionDeleteScript,
+	"mediaReactionInsert":        mediaReactionInsertScript,
+	"mediaVoteDelete":            mediaVoteDeleteScript,
+	"mediaVoteInsert":            mediaVoteInsertScript,
+	"mediaVotesByUser":           mediaVotesByUserScript,
+	"mediaVoteTallies":           mediaVoteTalliesScript,
+	"permissionInsert":           permissionInsertScript,
+	"permissionsForUser":         permissionsForUserScript,
+	"playerAddToHunt":            playerAddToHuntScript,
+	"playerAssignTeam":           playerAssignTeamScript,
+	"playerIsInHunt":             playerIsInHuntScript,
+	"playerRemoveFromHunt":       playerRemoveFromHuntScript,
+	"playersGetForHunt":          playersGetForHuntScript,
+	"roleInsert":                 roleInsertScript,
+	"roleRemove":                 roleRemoveScript,
+	"rolesDeleteByRegex":         rolesDeleteByRegexScript,
+	"rolesForUser":               rolesForUserScript,
+	"rolesRemoveByRegex":         rolesRemoveByRegexScript,
+	"scoreAdjustmentInsert":      scoreAdjustmentInsertScript,
+	"scoreAdjustmentsForTeam":    scoreAdjustmentsForTeamScript,
+	"sessionInsert":              sessionInsertScript,
+	"sessionGetForUser":          sessionGetForUserScript,
+	"sessionGet":                 sessionGetScript,
+	"sessionDelete":              sessionDeleteScript,
+	"similarMedia":               similarMediaScript,
+	"submissionFlagDelete":       submissionFlagDeleteScript,
+	"submissionFlagInsert":       submissionFlagInsertScript,
+	"submissionFlagsForHunt":     submissionFlagsForHuntScript,
+	"teamSelect":                 teamSelectScript,
+	"teamDelete":                 teamDeleteScript,
+	"teamInsert":                 teamInsertScript,
+	"teamsSelect":                teamsSelectScript,
+	"teamsWithHuntIDSelect":      teamsWithHuntIDSelectScript,
+	"teamPoints":                 teamPointsScript,
+	"teamPointsByCategory":       teamPointsByCategoryScript,
+	"teamAddPlayer":              teamAddPlayerScript,
+	"teamRemovePlayer":           teamRemovePlayerScript,
+	"teamGetPlayers":             teamGetPlayersScript,
+	"teamJoinCode":               teamJoinCodeScript,
+	"teamByJoinCode":             teamByJoinCodeScript,
+	"teamForPlayer":              teamForPlayerScript,
+	"thumbnailInsert":            thumbnailInsertScript,
+	"thumbnailsForTeam":          thumbnailsForTeamScript,
+	"uploadConfirm":              uploadConfirmScript,
+	"uploadInsert":               uploadInsertScript,
+	"uploadSelect":               uploadSelectScript,
+	"uploadSelectByKey":          uploadSelectByKeyScript,
+	"uploadsDeleteExpired":       uploadsDeleteExpiredScript,
+	"userInsert":                 userInsertScript,
+	"userGet":                    userGetScript,
+	"userGetByUsername":          userGetByUsernameScript,
+	"userDelete":                 userDeleteScript,
+	"userMessageDelete":          userMessageDeleteScript,
+	"userMessageInsert":          userMessageInsertScript,
+	"userMessagesForUser":        userMessagesForUserScript,
+	"votingRoundFinalize":        votingRoundFinalizeScript,
+	"votingRoundSelect":          votingRoundSelectScript,
+	"votingRoundUpsert":          votingRoundUpsertScript,
+	"waitlistEntryDelete":        waitlistEntryDeleteScript,
+	"waitlistEntryInsert":        waitlistEntryInsertScript,
+	"waitlistEntrySelect":        waitlistEntrySelectScript,
+	"waitlistForHunt":            waitlistForHuntScript,
+	"waitlistPromote":            waitlistPromoteScript,
 }
 
 func initStatements(database *sql.DB) error {
@@ -2,16 +2,30 @@ package db
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/asaskevich/govalidator"
+	"github.com/cljohnson4343/scavenge/geo"
 	"github.com/cljohnson4343/scavenge/pgsql"
 	"github.com/cljohnson4343/scavenge/request"
 	"github.com/cljohnson4343/scavenge/response"
+	"github.com/lib/pq"
 )
 
 // ItemTbl is the name of the items db table
 const ItemTbl string = "items"
 
+// the item types. Photo and video items are claimed by uploading media, the
+// others have their own submission.
+const (
+	ItemTypePhoto   = "photo"
+	ItemTypeVideo   = "video"
+	ItemTypeText    = "text"
+	ItemTypeCheckIn = "checkin"
+	ItemTypeGPS     = "gps"
+)
+
 // ItemDB is the data representation of a row from items
 //
 // swagger:model item
@@ -38,34 +52,219 @@ type ItemDB struct {
 	// minimum: 1
 	// default: 1
 	Points int `json:"points,omitempty" valid:"positive,optional"`
+
+	// the type of the item: photo, video, text, checkin, or gps
+	//
+	// default: photo
+	Type string `json:"type,omitempty" valid:"in(photo|video|text|checkin|gps),optional"`
+
+	// the accepted answers for a text item. Answers are matched ignoring
+	// case and extra whitespace.
+	//
+	// required: false
+	Answers []string `json:"answers,omitempty" valid:"-"`
+
+	// the code for a checkin item, usually printed as a QR code
+	//
+	// maximum length: 64
+	// required: false
+	CheckInCode string `json:"checkInCode,omitempty" valid:"stringlength(1|64),optional"`
+
+	// the latitude of the item's target point. Required for gps items, where
+	// the target is the checkpoint, and optional for every other type.
+	//
+	// required: false
+	Latitude float32 `json:"latitude,omitempty" valid:"latitude,optional"`
+
+	// the longitude of the item's target point
+	//
+	// required: false
+	Longitude float32 `json:"longitude,omitempty" valid:"longitude,optional"`
+
+	// how close, in meters, a submission has to be to the target point
+	//
+	// required: false
+	Radius int `json:"radius,omitempty" valid:"positive,optional"`
+
+	// whether submissions from outside the radius are rejected. If false
+	// they are accepted but flagged for the hunt's owner.
+	//
+	// required: false
+	EnforceGeofence *bool `json:"enforceGeofence,omitempty" valid:"-"`
+
+	// the time the item becomes visible to teams
+	//
+	// required: false
+	// swagger:strfmt date
+	VisibleFrom time.Time `json:"visibleFrom" valid:"-"`
+
+	// the time the item is hidden from teams again
+	//
+	// required: false
+	// swagger:strfmt date
+	VisibleUntil time.Time `json:"visibleUntil" valid:"-"`
+
+	// the ids of the items a team has to complete before this item is
+	// visible to it. Set with the item's dependencies endpoint.
+	//
+	// required: false
+	DependsOn []int `json:"dependsOn,omitempty" valid:"-"`
+
+	// the category the item is grouped under
+	//
+	// maximum length: 64
+	// required: false
+	Category string `json:"category,omitempty" valid:"stringlength(1|64),optional"`
+
+	// free form tags for filtering the item. Tags are stored lower case.
+	//
+	// required: false
+	Tags []string `json:"tags,omitempty" valid:"-"`
+
+	// how hard the item is, from 1 to 5
+	//
+	// minimum: 1
+	// maximum: 5
+	// required: false
+	Difficulty int `json:"difficulty,omitempty" valid:"-"`
+}
+
+const (
+	// maxItemTags is the most tags an item can have
+	maxItemTags = 20
+
+	// maxTagLength is the longest a single tag can be
+	maxTagLength = 32
+)
+
+// NormalizeTags lower cases and trims the tags and removes blank and
+// repeated ones
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	return normalized
+}
+
+// setVisibility sets the visibility fields from their nullable db values
+func (i *ItemDB) setVisibility(from, until pq.NullTime, dependsOn pq.Int64Array) {
+	i.VisibleFrom = from.Time
+	i.VisibleUntil = until.Time
+
+	i.DependsOn = make([]int, 0, len(dependsOn))
+	for _, id := range dependsOn {
+		i.DependsOn = append(i.DependsOn, int(id))
+	}
 }
 
 var itemSelectScript = `
-	SELECT hunt_id, id, name, points
+	SELECT hunt_id, id, name, points, item_type, COALESCE(answers, '{}'),
+		COALESCE(checkin_code, ''), COALESCE(latitude, 0), COALESCE(longitude, 0),
+		COALESCE(radius, 0), enforce_geofence, visible_from, visible_until,
+		COALESCE((
+			SELECT array_agg(d.depends_on_id ORDER BY d.depends_on_id)
+			FROM item_dependencies d
+			WHERE d.item_id = items.id
+		), '{}'), COALESCE(category, ''), tags, COALESCE(difficulty, 0)
 	FROM items
 	WHERE id = $1;`
 
 // GetItem returns the item with the given id
 func GetItem(id int) (*ItemDB, *response.Error) {
 	item := ItemDB{}
-
-	err := stmtMap["itemSelect"].QueryRow(id).Scan(&item.HuntID, &item.ID, &item.Name, &item.Points)
+	var from, until pq.NullTime
+	var dependsOn pq.Int64Array
+
+	err := stmtMap["itemSelect"].QueryRow(id).Scan(
+		&item.HuntID,
+		&item.ID,
+		&item.Name,
+		&item.Points,
+		&item.Type,
+		pq.Array(&item.Answers),
+		&item.CheckInCode,
+		&item.Latitude,
+		&item.Longitude,
+		&item.Radius,
+		&item.EnforceGeofence,
+		&from,
+		&until,
+		&dependsOn,
+		&item.Category,
+		pq.Array(&item.Tags),
+		&item.Difficulty,
+	)
 	if err != nil {
 		return nil, response.NewErrorf(http.StatusInternalServerError, "error getting item with id %d: %s", id, err.Error())
 	}
+	item.setVisibility(from, until, dependsOn)
 
 	return &item, nil
 }
 
 var itemInsertScript = `
-	INSERT INTO items(hunt_id, name, points)
-	VALUES ($1, $2, $3)
-	RETURNING id;
+	INSERT INTO items(hunt_id, name, points, item_type, answers, checkin_code, 
+		latitude, longitude, radius, enforce_geofence, visible_from, visible_until,
+		category, tags, difficulty)
+	VALUES ($1, $2, $3, COALESCE(NULLIF($4, ''), 'photo'), $5, NULLIF($6, ''), 
+		$7, $8, NULLIF($9, 0), COALESCE($10, false), $11, $12, NULLIF($13, ''), 
+		$14, NULLIF($15, 0))
+	RETURNING id, item_type, enforce_geofence;
 	`
 
+// insertArgs returns the arguments of the item insert and upsert scripts
+func (i *ItemDB) insertArgs() []interface{} {
+	// 0 is a valid coordinate so the point of an item without a target is
+	// stored as NULL
+	var lat, lng interface{}
+	if i.HasTarget() {
+		lat, lng = i.Latitude, i.Longitude
+	}
+
+	var answers interface{}
+	if len(i.Answers) > 0 {
+		answers = pq.Array(i.Answers)
+	}
+
+	var from, until pq.NullTime
+	if !i.VisibleFrom.IsZero() {
+		from = pq.NullTime{Time: i.VisibleFrom, Valid: true}
+	}
+	if !i.VisibleUntil.IsZero() {
+		until = pq.NullTime{Time: i.VisibleUntil, Valid: true}
+	}
+
+	return []interface{}{
+		i.HuntID,
+		i.Name,
+		i.Points,
+		i.Type,
+		answers,
+		i.CheckInCode,
+		lat,
+		lng,
+		i.Radius,
+		i.EnforceGeofence,
+		from,
+		until,
+		i.Category,
+		pq.Array(NormalizeTags(i.Tags)),
+		i.Difficulty,
+	}
+}
+
 // Insert inserts the item into the items table
 func (i *ItemDB) Insert() *response.Error {
-	err := stmtMap["itemInsert"].QueryRow(i.HuntID, i.Name, i.Points).Scan(&i.ID)
+	err := stmtMap["itemInsert"].QueryRow(i.insertArgs()...).Scan(&i.ID, &i.Type, &i.EnforceGeofence)
 	if err != nil {
 		return response.NewErrorf(http.StatusInternalServerError, "error inserting item: %s", err.Error())
 	}
@@ -74,7 +273,14 @@ func (i *ItemDB) Insert() *response.Error {
 }
 
 var itemsSelectScript = `
-	SELECT hunt_id, id, name, points
+	SELECT hunt_id, id, name, points, item_type, COALESCE(answers, '{}'),
+		COALESCE(checkin_code, ''), COALESCE(latitude, 0), COALESCE(longitude, 0),
+		COALESCE(radius, 0), enforce_geofence, visible_from, visible_until,
+		COALESCE((
+			SELECT array_agg(d.depends_on_id ORDER BY d.depends_on_id)
+			FROM item_dependencies d
+			WHERE d.item_id = items.id
+		), '{}'), COALESCE(category, ''), tags, COALESCE(difficulty, 0)
 	FROM items
 	WHERE hunt_id = $1;`
 
@@ -91,7 +297,27 @@ func GetItemsWithHuntID(huntID int) ([]*ItemDB, *response.Error) {
 
 	for rows.Next() {
 		item := ItemDB{}
-		err := rows.Scan(&item.HuntID, &item.ID, &item.Name, &item.Points)
+		var from, until pq.NullTime
+		var dependsOn pq.Int64Array
+		err := rows.Scan(
+			&item.HuntID,
+			&item.ID,
+			&item.Name,
+			&item.Points,
+			&item.Type,
+			pq.Array(&item.Answers),
+			&item.CheckInCode,
+			&item.Latitude,
+			&item.Longitude,
+			&item.Radius,
+			&item.EnforceGeofence,
+			&from,
+			&until,
+			&dependsOn,
+			&item.Category,
+			pq.Array(&item.Tags),
+			&item.Difficulty,
+		)
 		if err != nil {
 			e.Addf(
 				http.StatusInternalServerError,
@@ -101,6 +327,7 @@ func GetItemsWithHuntID(huntID int) ([]*ItemDB, *response.Error) {
 			)
 			break
 		}
+		item.setVisibility(from, until, dependsOn)
 		items = append(items, &item)
 	}
 
@@ -149,9 +376,182 @@ func (i *ItemDB) Validate(r *http.Request) *response.Error {
 		e.Add(http.StatusBadRequest, structErr.Error())
 	}
 
+	if i.Difficulty < 0 || i.Difficulty > 5 {
+		e.Add(http.StatusBadRequest, "difficulty: must be between 1 and 5")
+	}
+
+	if len(i.Tags) > maxItemTags {
+		e.Addf(http.StatusBadRequest, "tags: an item can have at most %d tags", maxItemTags)
+	}
+	for _, tag := range i.Tags {
+		if len(tag) > maxTagLength {
+			e.Addf(http.StatusBadRequest, "tags: %s is longer than %d characters", tag, maxTagLength)
+			break
+		}
+	}
+
+	if !i.VisibleFrom.IsZero() && !i.VisibleUntil.IsZero() && !i.VisibleFrom.Before(i.VisibleUntil) {
+		e.Add(http.StatusBadRequest, "visibleUntil: must be after visibleFrom")
+	}
+
+	switch i.Type {
+	case ItemTypeText:
+		valid := 0
+		for _, a := range i.Answers {
+			if normalizeAnswer(a) != "" {
+				valid++
+			}
+		}
+		if valid == 0 || valid != len(i.Answers) {
+			e.Add(http.StatusBadRequest, "answers: a text item needs at least one answer and answers can not be blank")
+		}
+	case ItemTypeCheckIn:
+		if strings.TrimSpace(i.CheckInCode) == "" {
+			e.Add(http.StatusBadRequest, "checkInCode: a checkin item needs a code")
+		}
+	case ItemTypeGPS:
+		if i.Latitude == 0 && i.Longitude == 0 {
+			e.Add(http.StatusBadRequest, "latitude: a gps item needs a checkpoint")
+		}
+		if i.Radius < 1 {
+			e.Add(http.StatusBadRequest, "radius: a gps item needs a radius of at least 1 meter")
+		}
+	default:
+		hasPoint := i.Latitude != 0 || i.Longitude != 0
+		if hasPoint != (i.Radius > 0) {
+			e.Add(http.StatusBadRequest, "radius: a target needs both a point and a radius")
+		}
+	}
+
 	return e.GetError()
 }
 
+// normalizeAnswer lower cases the answer and collapses its whitespace so
+// answers can be compared without worrying about formatting
+func normalizeAnswer(answer string) string {
+	return strings.Join(strings.Fields(strings.ToLower(answer)), " ")
+}
+
+// MatchesAnswer returns whether or not the answer matches one of the
+// item's accepted answers
+func (i *ItemDB) MatchesAnswer(answer string) bool {
+	given := normalizeAnswer(answer)
+	if given == "" {
+		return false
+	}
+
+	for _, a := range i.Answers {
+		if normalizeAnswer(a) == given {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchesCheckInCode returns whether or not the code is the item's check in
+// code. Surrounding whitespace and case are ignored since codes are often
+// typed in by hand when a QR code won't scan.
+func (i *ItemDB) MatchesCheckInCode(code string) bool {
+	if i.CheckInCode == "" {
+		return false
+	}
+
+	return strings.EqualFold(strings.TrimSpace(code), strings.TrimSpace(i.CheckInCode))
+}
+
+// HasTarget returns whether or not the item has a target point that
+// submissions are checked against
+func (i *ItemDB) HasTarget() bool {
+	return i.Radius > 0
+}
+
+// RejectsOutsideTarget returns whether or not submissions from outside the
+// item's radius are rejected rather than flagged
+func (i *ItemDB) RejectsOutsideTarget() bool {
+	return i.EnforceGeofence != nil && *i.EnforceGeofence
+}
+
+// DistanceTo returns the distance, in meters, from the given point to the
+// item's target point
+func (i *ItemDB) DistanceTo(latitude, longitude float64) float64 {
+	return geo.Distance(
+		float64(i.Latitude),
+		float64(i.Longitude),
+		latitude,
+		longitude,
+	)
+}
+
+// VisibleTo returns whether or not a team that has completed the given items
+// can see the item at the given time. completed maps each item to whether
+// its submission was approved, see GetCompletedItemIDs. Items a team has
+// completed are always visible to it, but only approved items unlock the
+// items that depend on them.
+func (i *ItemDB) VisibleTo(completed map[int]bool, now time.Time) bool {
+	if _, ok := completed[i.ID]; ok {
+		return true
+	}
+
+	if !i.VisibleFrom.IsZero() && now.Before(i.VisibleFrom) {
+		return false
+	}
+
+	if !i.VisibleUntil.IsZero() && !now.Before(i.VisibleUntil) {
+		return false
+	}
+
+	for _, id := range i.DependsOn {
+		if !completed[id] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ClaimedWithMedia returns whether or not the item is claimed by uploading
+// media rather than by its own submission
+func (i *ItemDB) ClaimedWithMedia() bool {
+	return i.Type == "" || i.Type == ItemTypePhoto || i.Type == ItemTypeVideo
+}
+
+// HideSecrets clears the fields that would give away how to claim the item,
+// i.e. the answers, the check in code, and the target point
+func (i *ItemDB) HideSecrets() {
+	i.Answers = nil
+	i.CheckInCode = ""
+	i.Latitude = 0
+	i.Longitude = 0
+}
+
+// SecretKeys returns the json keys of the set fields that decide how the
+// item is claimed, i.e. the answers, the check in code, and the target point
+// and geofence
+func (i *ItemDB) SecretKeys() []string {
+	keys := make([]string, 0)
+	if i.Answers != nil {
+		keys = append(keys, "answers")
+	}
+	if i.CheckInCode != "" {
+		keys = append(keys, "checkInCode")
+	}
+	if i.Latitude != 0 {
+		keys = append(keys, "latitude")
+	}
+	if i.Longitude != 0 {
+		keys = append(keys, "longitude")
+	}
+	if i.Radius != 0 {
+		keys = append(keys, "radius")
+	}
+	if i.EnforceGeofence != nil {
+		keys = append(keys, "enforceGeofence")
+	}
+
+	return keys
+}
+
 // GetTableColumnMap maps all non-zero field in the ItemDB to their corresponding db table, column,
 //  and value
 func (i *ItemDB) GetTableColumnMap() pgsql.TableColumnMap {
@@ -177,6 +577,54 @@ func (i *ItemDB) GetTableColumnMap() pgsql.TableColumnMap {
 		t[ItemTbl]["points"] = i.Points
 	}
 
+	if i.Type != zeroed.Type {
+		t[ItemTbl]["item_type"] = i.Type
+	}
+
+	if len(i.Answers) > 0 {
+		t[ItemTbl]["answers"] = pq.Array(i.Answers)
+	}
+
+	if i.CheckInCode != zeroed.CheckInCode {
+		t[ItemTbl]["checkin_code"] = i.CheckInCode
+	}
+
+	if i.Latitude != zeroed.Latitude {
+		t[ItemTbl]["latitude"] = i.Latitude
+	}
+
+	if i.Longitude != zeroed.Longitude {
+		t[ItemTbl]["longitude"] = i.Longitude
+	}
+
+	if i.Radius != zeroed.Radius {
+		t[ItemTbl]["radius"] = i.Radius
+	}
+
+	if i.EnforceGeofence != nil {
+		t[ItemTbl]["enforce_geofence"] = *i.EnforceGeofence
+	}
+
+	if !i.VisibleFrom.IsZero() {
+		t[ItemTbl]["visible_from"] = i.VisibleFrom
+	}
+
+	if !i.VisibleUntil.IsZero() {
+		t[ItemTbl]["visible_until"] = i.VisibleUntil
+	}
+
+	if i.Category != zeroed.Category {
+		t[ItemTbl]["category"] = i.Category
+	}
+
+	if len(i.Tags) > 0 {
+		t[ItemTbl]["tags"] = pq.Array(NormalizeTags(i.Tags))
+	}
+
+	if i.Difficulty != zeroed.Difficulty {
+		t[ItemTbl]["difficulty"] = i.Difficulty
+	}
+
 	return t
 }
 
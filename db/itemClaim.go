@@ -0,0 +1,274 @@
+package db
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/lib/pq"
+)
+
+// ItemClaimDB is a representation of a row in the item_claims table. A claim
+// is how a team gets the points for a text, checkin, or gps item.
+//
+// swagger:model ItemClaim
+type ItemClaimDB struct {
+
+	// The id of the claim
+	//
+	// required: false
+	ID int `json:"claimID" valid:"int,optional"`
+
+	// The id of the item that was claimed
+	//
+	// required: true
+	ItemID int `json:"itemID" valid:"int"`
+
+	// The id of the team that claimed the item
+	//
+	// required: true
+	TeamID int `json:"teamID" valid:"int"`
+
+	// The id of the user that made the claim
+	//
+	// required: true
+	UserID int `json:"userID" valid:"int"`
+
+	// The answer or code the item was claimed with
+	//
+	// required: false
+	Answer string `json:"answer,omitempty" valid:"-"`
+
+	// The latitude the gps item was claimed from
+	//
+	// required: false
+	Latitude float32 `json:"latitude,omitempty" valid:"-"`
+
+	// The longitude the gps item was claimed from
+	//
+	// required: false
+	Longitude float32 `json:"longitude,omitempty" valid:"-"`
+
+	// The time the item was claimed
+	//
+	// required: false
+	// swagger:strfmt date
+	ClaimedAt time.Time `json:"claimedAt" valid:"-"`
+}
+
+// Validate is a dummy function because ItemClaimDB model is server
+// generated and not meant to be posted by clients
+func (c *ItemClaimDB) Validate(r *http.Request) *response.Error {
+	return nil
+}
+
+var itemClaimInsertScript = `
+	INSERT INTO item_claims(item_id, team_id, user_id, answer, latitude, longitude)
+	VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6)
+	RETURNING id, claimed_at;
+	`
+
+// Insert records the claim. A team can only claim an item once.
+func (c *ItemClaimDB) Insert() *response.Error {
+	// a claim only has a point if it was made for a gps item
+	var lat, lng interface{}
+	if c.Latitude != 0 || c.Longitude != 0 {
+		lat, lng = c.Latitude, c.Longitude
+	}
+
+	err := stmtMap["itemClaimInsert"].QueryRow(
+		c.ItemID,
+		c.TeamID,
+		c.UserID,
+		c.Answer,
+		lat,
+		lng,
+	).Scan(&c.ID, &c.ClaimedAt)
+	if err != nil {
+		return c.ParseError(err, "insert")
+	}
+
+	return nil
+}
+
+var itemsCompletedByTeamScript = `
+	SELECT s.item_id, bool_or(NOT s.flagged)
+	FROM (
+		SELECT m.item_id, EXISTS (
+			SELECT 1 FROM submission_flags f WHERE f.media_id = m.id
+		) AS flagged
+		FROM media m
+		WHERE m.team_id = $1 AND m.item_id IS NOT NULL
+		UNION ALL
+		SELECT c.item_id, EXISTS (
+			SELECT 1 FROM submission_flags f WHERE f.claim_id = c.id
+		) AS flagged
+		FROM item_claims c
+		WHERE c.team_id = $1
+	) s
+	GROUP BY s.item_id;
+	`
+
+// GetCompletedItemIDs returns the ids of the items the team has completed,
+// either by uploading media for them or by claiming them. An item maps to
+// true once one of its submissions has no flags left to review, and to
+// false while all of them are still flagged.
+func GetCompletedItemIDs(teamID int) (map[int]bool, *response.Error) {
+	rows, err := stmtMap["itemsCompletedByTeam"].Query(teamID)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting completed items for team %d: %v",
+			teamID,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	ids := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		var approved bool
+		err = rows.Scan(&id, &approved)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error getting completed items for team %d: %v",
+				teamID,
+				err,
+			)
+			break
+		}
+
+		ids[id] = approved
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error getting completed items for team %d: %v",
+			teamID,
+			err,
+		)
+	}
+
+	return ids, e.GetError()
+}
+
+// ItemSubmissionDB is when a team first completed an item, either by
+// uploading media for it or by claiming it
+//
+// swagger:model ItemSubmission
+type ItemSubmissionDB struct {
+
+	// The id of the team
+	TeamID int `json:"teamID"`
+
+	// The id of the item
+	ItemID int `json:"itemID"`
+
+	// The name of the item
+	ItemName string `json:"itemName"`
+
+	// When the item was first completed
+	SubmittedAt time.Time `json:"submittedAt"`
+}
+
+var itemSubmissionsForHuntScript = `
+	SELECT s.team_id, s.item_id, i.name, MIN(s.submitted_at)
+	FROM (
+		SELECT m.team_id, m.item_id, l.time_stamp AS submitted_at
+		FROM media m
+		INNER JOIN locations l ON l.id = m.location_id
+		WHERE m.item_id IS NOT NULL
+		UNION ALL
+		SELECT team_id, item_id, claimed_at
+		FROM item_claims
+	) s
+	INNER JOIN items i ON i.id = s.item_id
+	INNER JOIN teams t ON t.id = s.team_id
+	WHERE t.hunt_id = $1 AND ($2 = 0 OR s.team_id = $2)
+	GROUP BY s.team_id, s.item_id, i.name
+	ORDER BY s.team_id, MIN(s.submitted_at), s.item_id;
+	`
+
+// GetItemSubmissions returns when each item of the hunt with the given id
+// was first completed by the team with the given id, or by every team if
+// teamID is 0, ordered by team and then time
+func GetItemSubmissions(huntID, teamID int) ([]*ItemSubmissionDB, *response.Error) {
+	rows, err := stmtMap["itemSubmissionsForHunt"].Query(huntID, teamID)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting item submissions for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	subs := make([]*ItemSubmissionDB, 0)
+	for rows.Next() {
+		s := ItemSubmissionDB{}
+		err = rows.Scan(&s.TeamID, &s.ItemID, &s.ItemName, &s.SubmittedAt)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error getting item submissions for hunt %d: %v",
+				huntID,
+				err,
+			)
+			break
+		}
+
+		subs = append(subs, &s)
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error getting item submissions for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+
+	return subs, e.GetError()
+}
+
+// ParseError maps a pq driver error to a response.Error
+func (c *ItemClaimDB) ParseError(err error, op string) *response.Error {
+	pqErr, ok := err.(*pq.Error)
+	if ok {
+		switch pqErr.Constraint {
+		case "team_claims_item_once":
+			return response.NewErrorf(
+				http.StatusBadRequest,
+				"item_id: team %d has already claimed item %d",
+				c.TeamID,
+				c.ItemID,
+			)
+		case "item_claims_item_id_fkey":
+			return response.NewErrorf(
+				http.StatusBadRequest,
+				"item_id: item %d does not exist",
+				c.ItemID,
+			)
+		case "item_claims_team_id_fkey":
+			return response.NewErrorf(
+				http.StatusBadRequest,
+				"team_id: team %d does not exist",
+				c.TeamID,
+			)
+		}
+	}
+
+	return response.NewErrorf(
+		http.StatusInternalServerError,
+		"error executing item claim %s: %v",
+		op,
+		err,
+	)
+}
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"net/http"
+
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/lib/pq"
+)
+
+var itemDependenciesDeleteScript = `
+	DELETE FROM item_dependencies
+	WHERE item_id = $1;
+	`
+
+var itemDependencyInsertScript = `
+	INSERT INTO item_dependencies(item_id, depends_on_id)
+	SELECT $1, i.id
+	FROM items i
+	WHERE i.id = $2 AND i.hunt_id = (SELECT hunt_id FROM items WHERE id = $1);
+	`
+
+// SetItemDependencies replaces the items the given item depends on. Every
+// item has to be part of the same hunt.
+func SetItemDependencies(itemID int, dependsOn []int) *response.Error {
+	tx, err := db.Begin()
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error beginning a transaction: %v",
+			err,
+		)
+	}
+
+	rollback := func(e *response.Error) *response.Error {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return response.NewErrorf(
+				http.StatusInternalServerError,
+				"error rolling back tx: %v",
+				rollbackErr,
+			)
+		}
+
+		return e
+	}
+
+	_, err = tx.Stmt(stmtMap["itemDependenciesDelete"]).Exec(itemID)
+	if err != nil {
+		return rollback(response.NewErrorf(
+			http.StatusInternalServerError,
+			"error clearing dependencies for item %d: %v",
+			itemID,
+			err,
+		))
+	}
+
+	insStmt := tx.Stmt(stmtMap["itemDependencyInsert"])
+	for _, id := range dependsOn {
+		res, err := insStmt.Exec(itemID, id)
+		if err != nil {
+			pqErr, ok := err.(*pq.Error)
+			if ok && pqErr.Constraint == "item_not_own_dependency" {
+				return rollback(response.NewErrorf(
+					http.StatusBadRequest,
+					"dependsOn: item %d can not depend on itself",
+					itemID,
+				))
+			}
+
+			return rollback(response.NewErrorf(
+				http.StatusInternalServerError,
+				"error adding dependency %d to item %d: %v",
+				id,
+				itemID,
+				err,
+			))
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return rollback(response.NewErrorf(
+				http.StatusInternalServerError,
+				"error adding dependency %d to item %d: %v",
+				id,
+				itemID,
+				err,
+			))
+		}
+
+		if n < 1 {
+			return rollback(response.NewErrorf(
+				http.StatusBadRequest,
+				"dependsOn: item %d is not part of the same hunt as item %d",
+				id,
+				itemID,
+			))
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error committing transaction for item dependencies: %v",
+			err,
+		)
+	}
+
+	return nil
+}
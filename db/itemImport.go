@@ -0,0 +1,92 @@
+package db
+
+import (
+	"net/http"
+
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+var itemUpsertScript = `
+	INSERT INTO items(hunt_id, name, points, item_type, answers, checkin_code,
+		latitude, longitude, radius, enforce_geofence, visible_from, visible_until,
+		category, tags, difficulty)
+	VALUES ($1, $2, $3, COALESCE(NULLIF($4, ''), 'photo'), $5, NULLIF($6, ''),
+		$7, $8, NULLIF($9, 0), COALESCE($10, false), $11, $12, NULLIF($13, ''),
+		$14, NULLIF($15, 0))
+	ON CONFLICT ON CONSTRAINT items_in_same_hunt_name DO UPDATE SET
+		points = EXCLUDED.points,
+		item_type = EXCLUDED.item_type,
+		answers = EXCLUDED.answers,
+		checkin_code = EXCLUDED.checkin_code,
+		latitude = EXCLUDED.latitude,
+		longitude = EXCLUDED.longitude,
+		radius = EXCLUDED.radius,
+		enforce_geofence = EXCLUDED.enforce_geofence,
+		visible_from = EXCLUDED.visible_from,
+		visible_until = EXCLUDED.visible_until,
+		category = EXCLUDED.category,
+		tags = EXCLUDED.tags,
+		difficulty = EXCLUDED.difficulty
+	RETURNING id, item_type, enforce_geofence, (xmax = 0);
+	`
+
+// ImportItems creates or updates the given items in a single transaction.
+// Items are matched to the hunt's existing items by name and a matched item
+// is replaced by the imported one. Nothing is saved if any item fails.
+func ImportItems(items []*ItemDB) (created int, updated int, e *response.Error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, 0, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error beginning a transaction: %v",
+			err,
+		)
+	}
+
+	rollback := func(e *response.Error) *response.Error {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return response.NewErrorf(
+				http.StatusInternalServerError,
+				"error rolling back tx: %v",
+				rollbackErr,
+			)
+		}
+
+		return e
+	}
+
+	stmt := tx.Stmt(stmtMap["itemUpsert"])
+	for _, item := range items {
+		var inserted bool
+		err = stmt.QueryRow(item.insertArgs()...).Scan(
+			&item.ID,
+			&item.Type,
+			&item.EnforceGeofence,
+			&inserted,
+		)
+		if err != nil {
+			return 0, 0, rollback(response.NewErrorf(
+				http.StatusInternalServerError,
+				"error importing item %s: %v",
+				item.Name,
+				err,
+			))
+		}
+
+		if inserted {
+			created++
+		} else {
+			updated++
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, 0, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error committing transaction for item import: %v",
+			err,
+		)
+	}
+
+	return created, updated, nil
+}
@@ -4,7 +4,9 @@ package db_test
 
 import (
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/cljohnson4343/scavenge/db"
 )
@@ -64,6 +66,11 @@ func TestGetTableColumnMap(t *testing.T) {
 		{db.ItemDB{ID: 43}, "id", 1, 43},
 		{db.ItemDB{Name: "Chris Johnson"}, "name", 1, "Chris Johnson"},
 		{db.ItemDB{Points: 43}, "points", 1, 43},
+		{db.ItemDB{Type: "gps"}, "item_type", 1, "gps"},
+		{db.ItemDB{CheckInCode: "QR43"}, "checkin_code", 1, "QR43"},
+		{db.ItemDB{Latitude: 43}, "latitude", 1, float32(43)},
+		{db.ItemDB{Longitude: 43}, "longitude", 1, float32(43)},
+		{db.ItemDB{Radius: 43}, "radius", 1, 43},
 	}
 
 	for _, v := range tables {
@@ -81,3 +88,245 @@ func TestGetTableColumnMap(t *testing.T) {
 
 	}
 }
+
+func TestValidateItemTypes(t *testing.T) {
+	cases := []struct {
+		name  string
+		item  db.ItemDB
+		key   string
+		valid bool
+	}{
+		{name: "default type", item: db.ItemDB{Name: "item"}, valid: true},
+		{name: "unknown type", item: db.ItemDB{Name: "item", Type: "riddle"}, key: "type"},
+		{
+			name:  "text item",
+			item:  db.ItemDB{Name: "item", Type: "text", Answers: []string{"a map", "the map"}},
+			valid: true,
+		},
+		{name: "text item without answers", item: db.ItemDB{Name: "item", Type: "text"}, key: "answers"},
+		{
+			name: "text item with a blank answer",
+			item: db.ItemDB{Name: "item", Type: "text", Answers: []string{"a map", "  "}},
+			key:  "answers",
+		},
+		{
+			name:  "checkin item",
+			item:  db.ItemDB{Name: "item", Type: "checkin", CheckInCode: "QR43"},
+			valid: true,
+		},
+		{name: "checkin item without a code", item: db.ItemDB{Name: "item", Type: "checkin"}, key: "checkInCode"},
+		{
+			name:  "gps item",
+			item:  db.ItemDB{Name: "item", Type: "gps", Latitude: 40.7, Longitude: -74, Radius: 25},
+			valid: true,
+		},
+		{
+			name:  "photo item with a target",
+			item:  db.ItemDB{Name: "item", Latitude: 40.7, Longitude: -74, Radius: 25},
+			valid: true,
+		},
+		{name: "target without a radius", item: db.ItemDB{Name: "item", Latitude: 40.7, Longitude: -74}, key: "radius"},
+		{name: "gps item without a point", item: db.ItemDB{Name: "item", Type: "gps", Radius: 25}, key: "latitude"},
+		{
+			name: "gps item without a radius",
+			item: db.ItemDB{Name: "item", Type: "gps", Latitude: 40.7, Longitude: -74},
+			key:  "radius",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e := c.item.Validate(r)
+			if c.valid {
+				if e != nil {
+					t.Errorf("expected no error got %s", e.JSON())
+				}
+				return
+			}
+
+			if e == nil {
+				t.Fatalf("expected a %s error got nil", c.key)
+			}
+
+			if _, ok := e.ErrorsByKey()[c.key]; !ok {
+				t.Errorf("expected a %s error got %s", c.key, e.JSON())
+			}
+		})
+	}
+}
+
+func TestMatchesAnswer(t *testing.T) {
+	item := db.ItemDB{Type: "text", Answers: []string{"The Map", "a  treasure map"}}
+
+	cases := []struct {
+		answer  string
+		matches bool
+	}{
+		{"the map", true},
+		{"  THE   MAP ", true},
+		{"A Treasure Map", true},
+		{"map", false},
+		{"", false},
+		{"   ", false},
+	}
+
+	for _, c := range cases {
+		if got := item.MatchesAnswer(c.answer); got != c.matches {
+			t.Errorf("%q: expected %v got %v", c.answer, c.matches, got)
+		}
+	}
+}
+
+func TestMatchesCheckInCode(t *testing.T) {
+	item := db.ItemDB{Type: "checkin", CheckInCode: "QR43"}
+
+	if !item.MatchesCheckInCode(" qr43 ") {
+		t.Errorf("expected code to match ignoring case and whitespace")
+	}
+
+	if item.MatchesCheckInCode("QR44") {
+		t.Errorf("expected code to not match")
+	}
+
+	noCode := db.ItemDB{Type: "checkin"}
+	if noCode.MatchesCheckInCode("") {
+		t.Errorf("expected an item without a code to never match")
+	}
+}
+
+func TestHideSecrets(t *testing.T) {
+	item := db.ItemDB{
+		Name:        "item",
+		Type:        "gps",
+		Answers:     []string{"answer"},
+		CheckInCode: "QR43",
+		Latitude:    40.7,
+		Longitude:   -74,
+		Radius:      25,
+	}
+
+	item.HideSecrets()
+
+	if item.Answers != nil || item.CheckInCode != "" || item.Latitude != 0 || item.Longitude != 0 {
+		t.Errorf("expected secrets to be hidden got %+v", item)
+	}
+
+	if item.Radius != 25 || item.Type != "gps" {
+		t.Errorf("expected type and radius to be kept got %+v", item)
+	}
+}
+
+func TestVisibleTo(t *testing.T) {
+	now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name      string
+		item      db.ItemDB
+		completed map[int]bool
+		visible   bool
+	}{
+		{name: "no conditions", item: db.ItemDB{ID: 1}, visible: true},
+		{name: "dependency not completed", item: db.ItemDB{ID: 2, DependsOn: []int{1}}},
+		{
+			name:      "dependency completed",
+			item:      db.ItemDB{ID: 2, DependsOn: []int{1}},
+			completed: map[int]bool{1: true},
+			visible:   true,
+		},
+		{
+			name:      "dependency flagged",
+			item:      db.ItemDB{ID: 2, DependsOn: []int{1}},
+			completed: map[int]bool{1: false},
+		},
+		{
+			name:      "one of two dependencies completed",
+			item:      db.ItemDB{ID: 3, DependsOn: []int{1, 2}},
+			completed: map[int]bool{1: true},
+		},
+		{name: "before window", item: db.ItemDB{ID: 1, VisibleFrom: now.Add(time.Minute)}},
+		{name: "window opens now", item: db.ItemDB{ID: 1, VisibleFrom: now}, visible: true},
+		{name: "after window", item: db.ItemDB{ID: 1, VisibleUntil: now}},
+		{
+			name:    "inside window",
+			item:    db.ItemDB{ID: 1, VisibleFrom: now.Add(-time.Hour), VisibleUntil: now.Add(time.Hour)},
+			visible: true,
+		},
+		{
+			name:      "completed after window",
+			item:      db.ItemDB{ID: 1, VisibleUntil: now.Add(-time.Hour)},
+			completed: map[int]bool{1: true},
+			visible:   true,
+		},
+		{
+			name:      "flagged after window",
+			item:      db.ItemDB{ID: 1, VisibleUntil: now.Add(-time.Hour)},
+			completed: map[int]bool{1: false},
+			visible:   true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.item.VisibleTo(c.completed, now); got != c.visible {
+				t.Errorf("expected %v got %v", c.visible, got)
+			}
+		})
+	}
+}
+
+func TestValidateVisibleWindow(t *testing.T) {
+	now := time.Now()
+
+	item := db.ItemDB{Name: "item", VisibleFrom: now.Add(time.Hour), VisibleUntil: now}
+	e := item.Validate(r)
+	if e == nil {
+		t.Fatalf("expected a visibleUntil error got nil")
+	}
+	if _, ok := e.ErrorsByKey()["visibleUntil"]; !ok {
+		t.Errorf("expected a visibleUntil error got %s", e.JSON())
+	}
+
+	item.VisibleUntil = now.Add(2 * time.Hour)
+	if e = item.Validate(r); e != nil {
+		t.Errorf("expected no error got %s", e.JSON())
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	got := db.NormalizeTags([]string{" Park ", "park", "", "Night", "  "})
+	expected := []string{"park", "night"}
+
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("expected %v got %v", expected, got)
+		}
+	}
+}
+
+func TestValidateDifficultyAndTags(t *testing.T) {
+	item := db.ItemDB{Name: "item", Difficulty: 6}
+	e := item.Validate(r)
+	if e == nil {
+		t.Fatalf("expected a difficulty error got nil")
+	}
+	if _, ok := e.ErrorsByKey()["difficulty"]; !ok {
+		t.Errorf("expected a difficulty error got %s", e.JSON())
+	}
+
+	item = db.ItemDB{Name: "item", Tags: []string{strings.Repeat("a", 33)}}
+	e = item.Validate(r)
+	if e == nil {
+		t.Fatalf("expected a tags error got nil")
+	}
+	if _, ok := e.ErrorsByKey()["tags"]; !ok {
+		t.Errorf("expected a tags error got %s", e.JSON())
+	}
+
+	item = db.ItemDB{Name: "item", Category: "landmarks", Tags: []string{"park"}, Difficulty: 3}
+	if e = item.Validate(r); e != nil {
+		t.Errorf("expected no error got %s", e.JSON())
+	}
+}
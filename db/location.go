@@ -1,6 +1,8 @@
 package db
 
 import (
+	"database/sql"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -87,6 +89,98 @@ func GetLocationsForTeam(teamID int) ([]*LocationDB, *response.Error) {
 	return locs, e.GetError()
 }
 
+var locationsForTeamBetweenScript = `
+	SELECT team_id, id, latitude, longitude, time_stamp
+	FROM locations
+	WHERE team_id = $1
+		AND ($2::timestamp IS NULL OR time_stamp >= $2)
+		AND ($3::timestamp IS NULL OR time_stamp <= $3)
+	ORDER BY time_stamp, id;`
+
+var locationsForHuntBetweenScript = `
+	SELECT l.team_id, l.id, l.latitude, l.longitude, l.time_stamp
+	FROM locations l
+	INNER JOIN teams t ON t.id = l.team_id
+	WHERE t.hunt_id = $1
+		AND ($2::timestamp IS NULL OR l.time_stamp >= $2)
+		AND ($3::timestamp IS NULL OR l.time_stamp <= $3)
+	ORDER BY l.team_id, l.time_stamp, l.id;`
+
+// nullTime returns a null time for the zero time
+func nullTime(t time.Time) pq.NullTime {
+	return pq.NullTime{Time: t.UTC(), Valid: !t.IsZero()}
+}
+
+// queryLocations returns the locations selected by the query with the given
+// args
+func queryLocations(query string, desc string, args ...interface{}) ([]*LocationDB, *response.Error) {
+	rows, err := stmtMap[query].Query(args...)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting locations for %s: %v",
+			desc,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	locs := make([]*LocationDB, 0)
+	for rows.Next() {
+		l := LocationDB{}
+		err := rows.Scan(&l.TeamID, &l.ID, &l.Latitude, &l.Longitude, &l.TimeStamp)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error getting locations for %s: %v",
+				desc,
+				err,
+			)
+			break
+		}
+
+		locs = append(locs, &l)
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error getting locations for %s: %v",
+			desc,
+			err,
+		)
+	}
+
+	return locs, e.GetError()
+}
+
+// GetLocationsForTeamBetween returns the locations of the team with the
+// given id from the given time to the given time, ordered by time. A zero
+// time leaves that end of the range open.
+func GetLocationsForTeamBetween(teamID int, from, to time.Time) ([]*LocationDB, *response.Error) {
+	return queryLocations(
+		"locationsForTeamBetween",
+		fmt.Sprintf("team %d", teamID),
+		teamID,
+		nullTime(from),
+		nullTime(to),
+	)
+}
+
+// GetLocationsForHuntBetween returns the locations of every team in the hunt
+// with the given id from the given time to the given time, ordered by team
+// and then time. A zero time leaves that end of the range open.
+func GetLocationsForHuntBetween(huntID int, from, to time.Time) ([]*LocationDB, *response.Error) {
+	return queryLocations(
+		"locationsForHuntBetween",
+		fmt.Sprintf("hunt %d", huntID),
+		huntID,
+		nullTime(from),
+		nullTime(to),
+	)
+}
+
 var locationInsertScript = `
 	INSERT INTO locations(team_id, latitude, longitude, time_stamp)
 	VALUES ($1, $2, $3, $4)
@@ -112,6 +206,179 @@ func (l *LocationDB) Insert(teamID int) *response.Error {
 	return nil
 }
 
+var locationsInsertScript = `
+	INSERT INTO locations(team_id, latitude, longitude, time_stamp)
+	SELECT $1, f.latitude, f.longitude, f.time_stamp
+	FROM unnest($2::real[], $3::real[], $4::timestamp[]) AS f(latitude, longitude, time_stamp)
+	ON CONFLICT ON CONSTRAINT team_same_loc_and_time DO NOTHING
+	RETURNING id, time_stamp;`
+
+// locationTimeFormat formats the time stamp of a location the way it is
+// stored, to the microsecond without a time zone
+const locationTimeFormat = "2006-01-02 15:04:05.000000"
+
+// InsertLocations inserts the locations of the team with the given id in a
+// single statement. A team can only have one location at a time, so
+// locations with the time of one of the team's stored locations, or of an
+// earlier location in locs, are skipped. The ids of the inserted locations
+// are written into them and whether or not each location was inserted is
+// returned.
+func InsertLocations(teamID int, locs []*LocationDB) ([]bool, *response.Error) {
+	inserted := make([]bool, len(locs))
+	indexes := make(map[string]int, len(locs))
+	lats := make([]float32, 0, len(locs))
+	lngs := make([]float32, 0, len(locs))
+	times := make([]string, 0, len(locs))
+
+	for i, l := range locs {
+		if l.TeamID != teamID {
+			return nil, response.NewErrorf(
+				http.StatusBadRequest,
+				"team_id: team_id must match the URL team id %d",
+				teamID,
+			)
+		}
+
+		key := l.TimeStamp.Round(time.Microsecond).Format(locationTimeFormat)
+		if _, ok := indexes[key]; ok {
+			continue
+		}
+		indexes[key] = i
+
+		lats = append(lats, l.Latitude)
+		lngs = append(lngs, l.Longitude)
+		times = append(times, key)
+	}
+
+	if len(times) == 0 {
+		return inserted, nil
+	}
+
+	rows, err := stmtMap["locationsInsert"].Query(
+		teamID,
+		pq.Array(lats),
+		pq.Array(lngs),
+		pq.Array(times),
+	)
+	if err != nil {
+		l := LocationDB{TeamID: teamID}
+		return nil, l.ParseError(err, "batch insert")
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	for rows.Next() {
+		var id int
+		var timeStamp time.Time
+		if err = rows.Scan(&id, &timeStamp); err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error inserting locations for team %d: %v",
+				teamID,
+				err,
+			)
+			break
+		}
+
+		if i, ok := indexes[timeStamp.Format(locationTimeFormat)]; ok {
+			locs[i].ID = id
+			inserted[i] = true
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		l := LocationDB{TeamID: teamID}
+		e.AddError(l.ParseError(err, "batch insert"))
+	}
+
+	return inserted, e.GetError()
+}
+
+var locationPreviousForTeamScript = `
+	SELECT team_id, id, latitude, longitude, time_stamp
+	FROM locations
+	WHERE team_id = $1 AND time_stamp < $2
+	ORDER BY time_stamp DESC
+	LIMIT 1;`
+
+// GetPreviousLocation returns the last location the team with the given id
+// reported before the given time. nil is returned if there is none.
+func GetPreviousLocation(teamID int, before time.Time) (*LocationDB, *response.Error) {
+	l := LocationDB{}
+	err := stmtMap["locationPreviousForTeam"].QueryRow(teamID, before).Scan(
+		&l.TeamID,
+		&l.ID,
+		&l.Latitude,
+		&l.Longitude,
+		&l.TimeStamp,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting previous location for team %d: %v",
+			teamID,
+			err,
+		)
+	}
+
+	return &l, nil
+}
+
+var locationsLatestForHuntScript = `
+	SELECT DISTINCT ON (l.team_id) l.team_id, l.id, l.latitude, l.longitude, l.time_stamp
+	FROM locations l
+	INNER JOIN teams t ON t.id = l.team_id
+	WHERE t.hunt_id = $1
+	ORDER BY l.team_id, l.time_stamp DESC, l.id DESC;`
+
+// GetLatestLocationsForHunt returns the last location reported by each team
+// in the hunt with the given id. It is possible to return both results and an
+// error.
+func GetLatestLocationsForHunt(huntID int) ([]*LocationDB, *response.Error) {
+	rows, err := stmtMap["locationsLatestForHunt"].Query(huntID)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting latest locations for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	locs := make([]*LocationDB, 0)
+	for rows.Next() {
+		l := LocationDB{}
+		err := rows.Scan(&l.TeamID, &l.ID, &l.Latitude, &l.Longitude, &l.TimeStamp)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error getting latest locations for hunt %d: %v",
+				huntID,
+				err,
+			)
+			break
+		}
+
+		locs = append(locs, &l)
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error getting latest locations for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+
+	return locs, e.GetError()
+}
+
 var locationDeleteScript = `
 	DELETE FROM locations
 	WHERE id = $1 AND team_id = $2;`
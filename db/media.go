@@ -1,7 +1,9 @@
 package db
 
 import (
+	"database/sql"
 	"net/http"
+	"time"
 
 	"github.com/asaskevich/govalidator"
 	"github.com/cljohnson4343/scavenge/response"
@@ -35,6 +37,41 @@ type MediaMetaDB struct {
 	// maximum length: 2083
 	// minimum length: 3
 	URL string `json:"url" valid:"url"`
+
+	// When the photo was taken, read from its EXIF data. Only hunt owners
+	// can see it.
+	//
+	// required: false
+	// swagger:strfmt date
+	TakenAt time.Time `json:"takenAt" valid:"-"`
+
+	// Where the photo was taken, read from its EXIF data. Only hunt owners
+	// can see it.
+	//
+	// required: false
+	TakenLatitude float32 `json:"takenLatitude,omitempty" valid:"-"`
+
+	// Where the photo was taken, read from its EXIF data. Only hunt owners
+	// can see it.
+	//
+	// required: false
+	TakenLongitude float32 `json:"takenLongitude,omitempty" valid:"-"`
+
+	// The scaled down copies of the photo, largest first
+	//
+	// required: false
+	Thumbnails []*ThumbnailDB `json:"thumbnails,omitempty" valid:"-"`
+
+	// The difference hash of the photo, 0 if the media is not a photo
+	PHash int64 `json:"-" valid:"-"`
+}
+
+// HideCaptureDetails removes the EXIF capture time and location, which only
+// hunt owners can see
+func (m *MediaMetaDB) HideCaptureDetails() {
+	m.TakenAt = time.Time{}
+	m.TakenLatitude = 0
+	m.TakenLongitude = 0
 }
 
 // Validate validates the struct
@@ -58,7 +95,9 @@ var mediaMetasForTeamScript = `
 		FROM locations l
 		WHERE l.team_id = $1
 	), media_for_team AS (
-		SELECT m.id, m.team_id, COALESCE(m.item_id, 0), m.url, m.location_id
+		SELECT m.id, m.team_id, COALESCE(m.item_id, 0), m.url, m.location_id,
+			m.taken_at, COALESCE(m.taken_latitude, 0), 
+			COALESCE(m.taken_longitude, 0)
 		FROM media m
 		WHERE m.team_id = $1
 	)
@@ -68,7 +107,8 @@ var mediaMetasForTeamScript = `
 	`
 
 // GetMediaMetasForTeam returns all the meta information for all media files associated w/
-// this team. A result with both media meta objects and an error is possible
+// this team, including their thumbnails. A result with both media meta objects and an
+// error is possible
 func GetMediaMetasForTeam(teamID int) ([]*MediaMetaDB, *response.Error) {
 	rows, err := stmtMap["mediaMetasForTeam"].Query(teamID)
 	if err != nil {
@@ -86,6 +126,7 @@ func GetMediaMetasForTeam(teamID int) ([]*MediaMetaDB, *response.Error) {
 
 	for rows.Next() {
 		m := MediaMetaDB{}
+		var takenAt pq.NullTime
 
 		err = rows.Scan(
 			&m.ID,
@@ -93,6 +134,9 @@ func GetMediaMetasForTeam(teamID int) ([]*MediaMetaDB, *response.Error) {
 			&m.ItemID,
 			&m.URL,
 			&m.Location.ID,
+			&takenAt,
+			&m.TakenLatitude,
+			&m.TakenLongitude,
 			&m.Location.Latitude,
 			&m.Location.Longitude,
 			&m.Location.TimeStamp,
@@ -108,6 +152,9 @@ func GetMediaMetasForTeam(teamID int) ([]*MediaMetaDB, *response.Error) {
 			)
 			break
 		}
+		if takenAt.Valid {
+			m.TakenAt = takenAt.Time
+		}
 		metas = append(metas, &m)
 	}
 
@@ -120,6 +167,14 @@ func GetMediaMetasForTeam(teamID int) ([]*MediaMetaDB, *response.Error) {
 		)
 	}
 
+	thumbnails, thumbErr := GetThumbnailsForTeam(teamID)
+	if thumbErr != nil {
+		e.AddError(thumbErr)
+	}
+	for _, m := range metas {
+		m.Thumbnails = thumbnails[m.ID]
+	}
+
 	return metas, e.GetError()
 }
 
@@ -138,8 +193,10 @@ var mediaMetaInsertScript = `
 		UNION ALL 
 		SELECT locations_id FROM loc_ins
 	)
-	INSERT INTO media(team_id, item_id, location_id, url)
-	VALUES ($5, NULLIF($6, 0), (SELECT locations_id FROM loc), $7)
+	INSERT INTO media(team_id, item_id, location_id, url, taken_at, 
+		taken_latitude, taken_longitude, phash)
+	VALUES ($5, NULLIF($6, 0), (SELECT locations_id FROM loc), $7, $8, $9, $10, 
+		NULLIF($11, 0))
 	RETURNING location_id, id media_id;
 	`
 
@@ -154,9 +211,20 @@ func (m *MediaMetaDB) Insert(teamID int) *response.Error {
 		)
 	}
 
+	var takenAt pq.NullTime
+	if !m.TakenAt.IsZero() {
+		takenAt = pq.NullTime{Time: m.TakenAt, Valid: true}
+	}
+
+	// 0 is a valid coordinate so a missing capture location is stored as NULL
+	var takenLat, takenLng interface{}
+	if m.TakenLatitude != 0 || m.TakenLongitude != 0 {
+		takenLat, takenLng = m.TakenLatitude, m.TakenLongitude
+	}
+
 	err := stmtMap["mediaMetaInsert"].QueryRow(m.TeamID, m.Location.Latitude,
 		m.Location.Longitude, m.Location.TimeStamp, m.TeamID, m.ItemID,
-		m.URL).Scan(&m.Location.ID, &m.ID)
+		m.URL, takenAt, takenLat, takenLng, m.PHash).Scan(&m.Location.ID, &m.ID)
 	if err != nil {
 		return m.ParseError(err, "insert")
 	}
@@ -172,6 +240,7 @@ var mediaMetaDeleteScript = `
 
 // DeleteMedia deletes the row from the media table but leaves the location data in
 // the location table. If you want to delete both then delete the associated Location row.
+// The media's file and thumbnails are queued in the blob outbox to be deleted from storage.
 func DeleteMedia(mediaID, teamID int) (string, *response.Error) {
 	var url string
 	err := stmtMap["mediaMetaDelete"].QueryRow(mediaID, teamID).Scan(&url)
@@ -188,18 +257,31 @@ func DeleteMedia(mediaID, teamID int) (string, *response.Error) {
 }
 
 var teamPointsScript = `
-	WITH media_for_team AS (
+	WITH items_for_team AS (
 		SELECT item_id
 		FROM media
 		WHERE media.team_id = $1 AND media.item_id IS NOT NULL
+		UNION
+		SELECT item_id
+		FROM item_claims
+		WHERE item_claims.team_id = $1
 	)
-		SELECT COALESCE(SUM(i.points), 0)
-		FROM media_for_team m
+		SELECT COALESCE(SUM(i.points), 0) - (
+			SELECT COALESCE(SUM(hu.cost), 0)
+			FROM hint_unlocks hu
+			WHERE hu.team_id = $1
+		) + (
+			SELECT COALESCE(SUM(sa.points), 0)
+			FROM score_adjustments sa
+			WHERE sa.team_id = $1
+		)
+		FROM items_for_team m
 		INNER JOIN items i ON m.item_id = i.id; 
 	`
 
 // GetTeamPoints returns the integer number of points the team with the given
-// id has accumulated thus far
+// id has accumulated thus far, less the cost of the hints it has unlocked and
+// plus its score adjustments
 func GetTeamPoints(teamID int) (int, *response.Error) {
 	var pts int
 	err := stmtMap["teamPoints"].QueryRow(teamID).Scan(&pts)
@@ -210,6 +292,82 @@ func GetTeamPoints(teamID int) (int, *response.Error) {
 	return pts, nil
 }
 
+// CategoryPointsDB is the number of points a team has earned, and the number
+// of items it has completed, in one item category
+type CategoryPointsDB struct {
+	// the category, empty for items without one
+	Category string `json:"category"`
+
+	// the points earned from the category's items
+	Points int `json:"points"`
+
+	// the number of the category's items that were completed
+	Items int `json:"items"`
+}
+
+var teamPointsByCategoryScript = `
+	WITH items_for_team AS (
+		SELECT item_id
+		FROM media
+		WHERE media.team_id = $1 AND media.item_id IS NOT NULL
+		UNION
+		SELECT item_id
+		FROM item_claims
+		WHERE item_claims.team_id = $1
+	)
+		SELECT COALESCE(i.category, ''), COALESCE(SUM(i.points), 0), COUNT(*)
+		FROM items_for_team m
+		INNER JOIN items i ON m.item_id = i.id
+		GROUP BY COALESCE(i.category, '')
+		ORDER BY COALESCE(i.category, '');
+	`
+
+// GetTeamPointsByCategory returns the points the team with the given id has
+// earned from completed items, broken down by item category. Hint costs are
+// not included.
+func GetTeamPointsByCategory(teamID int) ([]*CategoryPointsDB, *response.Error) {
+	rows, err := stmtMap["teamPointsByCategory"].Query(teamID)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting category points for team %d: %v",
+			teamID,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	categories := make([]*CategoryPointsDB, 0)
+
+	for rows.Next() {
+		c := CategoryPointsDB{}
+
+		err = rows.Scan(&c.Category, &c.Points, &c.Items)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error getting category points for team %d: %v",
+				teamID,
+				err,
+			)
+			break
+		}
+		categories = append(categories, &c)
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error getting category points for team %d: %v",
+			teamID,
+			err,
+		)
+	}
+
+	return categories, e.GetError()
+}
+
 // ParseError maps a pq driver error to a response.Error
 func (m *MediaMetaDB) ParseError(err error, op string) *response.Error {
 	pqErr, ok := err.(*pq.Error)
@@ -240,3 +398,202 @@ func (m *MediaMetaDB) ParseError(err error, op string) *response.Error {
 
 	return m.Location.ParseError(err, op)
 }
+
+// SimilarMediaDB is a photo in a hunt that looks like another photo
+type SimilarMediaDB struct {
+	// The id of the media row
+	MediaID int
+
+	// The id of the team that uploaded the photo
+	TeamID int
+
+	// The id of the item the photo was uploaded for, 0 if none
+	ItemID int
+
+	// How many bits the hashes of the photos differ by
+	Distance int
+}
+
+var similarMediaScript = `
+	WITH hunt_media AS (
+		SELECT m.id, m.team_id, COALESCE(m.item_id, 0) item_id, 
+			length(replace((m.phash # $2)::bit(64)::text, '0', '')) distance
+		FROM media m
+		INNER JOIN teams t ON t.id = m.team_id
+		WHERE t.hunt_id = (SELECT hunt_id FROM teams WHERE id = $1) 
+			AND m.phash IS NOT NULL
+			AND m.id <> $5
+	)
+	SELECT id, team_id, item_id, distance
+	FROM hunt_media
+	WHERE distance <= $3
+		AND NOT (team_id = $1 AND item_id = $4)
+	ORDER BY distance, id
+	LIMIT 10;
+	`
+
+// GetSimilarMedia returns the photos in the team's hunt whose hashes differ
+// from the given hash by at most maxDistance bits, closest first. The media
+// with the given id, which is 0 if it has not been stored yet, and the
+// team's own uploads for the same item, which is 0 for none, are left out
+// since the team uploading an item again is not a duplicate.
+func GetSimilarMedia(teamID, itemID, mediaID int, hash int64, maxDistance int) ([]*SimilarMediaDB, *response.Error) {
+	rows, err := stmtMap["similarMedia"].Query(teamID, hash, maxDistance, itemID, mediaID)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting similar media for team %d: %v",
+			teamID,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	similar := make([]*SimilarMediaDB, 0)
+	for rows.Next() {
+		m := SimilarMediaDB{}
+		err = rows.Scan(&m.MediaID, &m.TeamID, &m.ItemID, &m.Distance)
+		if err != nil {
+			return nil, response.NewErrorf(
+				http.StatusInternalServerError,
+				"error getting similar media for team %d: %v",
+				teamID,
+				err,
+			)
+		}
+
+		similar = append(similar, &m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting similar media for team %d: %v",
+			teamID,
+			err,
+		)
+	}
+
+	return similar, nil
+}
+
+// HuntMediaDB is a media file submitted in a hunt along with the names of
+// its team and item
+type HuntMediaDB struct {
+	MediaMetaDB
+
+	// The name of the team that uploaded the media
+	TeamName string `json:"teamName"`
+
+	// The name of the media's item, empty if it has none
+	ItemName string `json:"itemName,omitempty"`
+
+	// The points the media's item is worth, 0 if it has no item
+	Points int `json:"points"`
+}
+
+var mediaForHuntScript = `
+	SELECT m.id, m.team_id, t.name, COALESCE(m.item_id, 0), COALESCE(i.name, ''), 
+		COALESCE(i.points, 0), m.url, l.id, l.latitude, l.longitude, l.time_stamp, 
+		m.taken_at, COALESCE(m.taken_latitude, 0), COALESCE(m.taken_longitude, 0)
+	FROM media m
+	INNER JOIN teams t ON t.id = m.team_id
+	INNER JOIN locations l ON l.id = m.location_id
+	LEFT JOIN items i ON i.id = m.item_id
+	WHERE t.hunt_id = $1
+	ORDER BY t.name, t.id, i.name NULLS LAST, m.id;
+	`
+
+// GetMediaForHunt returns every media file submitted in the hunt, ordered by
+// team and then item
+func GetMediaForHunt(huntID int) ([]*HuntMediaDB, *response.Error) {
+	rows, err := stmtMap["mediaForHunt"].Query(huntID)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting media for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	medias := make([]*HuntMediaDB, 0)
+	for rows.Next() {
+		m := HuntMediaDB{}
+		var takenAt pq.NullTime
+
+		err = rows.Scan(
+			&m.ID,
+			&m.TeamID,
+			&m.TeamName,
+			&m.ItemID,
+			&m.ItemName,
+			&m.Points,
+			&m.URL,
+			&m.Location.ID,
+			&m.Location.Latitude,
+			&m.Location.Longitude,
+			&m.Location.TimeStamp,
+			&takenAt,
+			&m.TakenLatitude,
+			&m.TakenLongitude,
+		)
+		if err != nil {
+			return nil, response.NewErrorf(
+				http.StatusInternalServerError,
+				"error getting media for hunt %d: %v",
+				huntID,
+				err,
+			)
+		}
+
+		m.Location.TeamID = m.TeamID
+		if takenAt.Valid {
+			m.TakenAt = takenAt.Time
+		}
+		medias = append(medias, &m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting media for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+
+	return medias, nil
+}
+
+var mediaHuntScript = `
+	SELECT t.hunt_id, m.team_id
+	FROM media m
+	INNER JOIN teams t ON t.id = m.team_id
+	WHERE m.id = $1;
+	`
+
+// GetMediaHunt returns the ids of the hunt and team the media with the given
+// id was uploaded in
+func GetMediaHunt(mediaID int) (int, int, *response.Error) {
+	var huntID, teamID int
+	err := stmtMap["mediaHunt"].QueryRow(mediaID).Scan(&huntID, &teamID)
+	if err == sql.ErrNoRows {
+		return 0, 0, response.NewErrorf(
+			http.StatusBadRequest,
+			"media_id: media %d does not exist",
+			mediaID,
+		)
+	}
+	if err != nil {
+		return 0, 0, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting the hunt of media %d: %v",
+			mediaID,
+			err,
+		)
+	}
+
+	return huntID, teamID, nil
+}
@@ -0,0 +1,240 @@
+package db
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/lib/pq"
+)
+
+// MediaCommentDB is a representation of a row in the media_comments table
+//
+// swagger:model MediaComment
+type MediaCommentDB struct {
+
+	// The id of the comment
+	//
+	// required: false
+	ID int `json:"commentID" valid:"int,optional"`
+
+	// The id of the media the comment is on
+	//
+	// required: false
+	MediaID int `json:"mediaID" valid:"int,optional"`
+
+	// The id of the user that left the comment
+	//
+	// required: false
+	UserID int `json:"userID" valid:"int,optional"`
+
+	// The username of the user that left the comment
+	//
+	// required: false
+	Username string `json:"username" valid:"-"`
+
+	// The comment
+	//
+	// maximum length: 1000
+	// required: true
+	Body string `json:"body" valid:"-"`
+
+	// The time the comment was left
+	//
+	// required: false
+	// swagger:strfmt date
+	CreatedAt time.Time `json:"createdAt" valid:"-"`
+}
+
+// Validate validates the comment
+func (c *MediaCommentDB) Validate(r *http.Request) *response.Error {
+	c.Body = strings.TrimSpace(c.Body)
+	if c.Body == "" || len(c.Body) > 1000 {
+		return response.NewError(
+			http.StatusBadRequest,
+			"body: a comment must be between 1 and 1000 characters",
+		)
+	}
+
+	return nil
+}
+
+var mediaCommentInsertScript = `
+	INSERT INTO media_comments(media_id, user_id, body)
+	VALUES ($1, $2, $3)
+	RETURNING id, created_at, (SELECT username FROM users WHERE id = $2);
+	`
+
+// Insert stores the comment
+func (c *MediaCommentDB) Insert() *response.Error {
+	err := stmtMap["mediaCommentInsert"].QueryRow(
+		c.MediaID,
+		c.UserID,
+		c.Body,
+	).Scan(&c.ID, &c.CreatedAt, &c.Username)
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if ok && pqErr.Constraint == "media_comments_media_id_fkey" {
+			return response.NewErrorf(
+				http.StatusBadRequest,
+				"media_id: media %d does not exist",
+				c.MediaID,
+			)
+		}
+
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error commenting on media %d: %v",
+			c.MediaID,
+			err,
+		)
+	}
+
+	return nil
+}
+
+var mediaCommentsForMediaScript = `
+	SELECT c.id, c.media_id, c.user_id, u.username, c.body, c.created_at
+	FROM media_comments c
+	INNER JOIN users u ON u.id = c.user_id
+	WHERE c.media_id = $1
+	ORDER BY c.created_at ASC, c.id ASC;
+	`
+
+// GetCommentsForMedia returns the comments on the given media, oldest first
+func GetCommentsForMedia(mediaID int) ([]*MediaCommentDB, *response.Error) {
+	rows, err := stmtMap["mediaCommentsForMedia"].Query(mediaID)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting comments for media %d: %v",
+			mediaID,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	comments := make([]*MediaCommentDB, 0)
+	for rows.Next() {
+		c := MediaCommentDB{}
+		err = rows.Scan(&c.ID, &c.MediaID, &c.UserID, &c.Username, &c.Body, &c.CreatedAt)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error getting comments for media %d: %v",
+				mediaID,
+				err,
+			)
+			break
+		}
+
+		comments = append(comments, &c)
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error getting comments for media %d: %v",
+			mediaID,
+			err,
+		)
+	}
+
+	return comments, e.GetError()
+}
+
+var mediaCommentCountsForHuntScript = `
+	SELECT c.media_id, COUNT(*)
+	FROM media_comments c
+	INNER JOIN media m ON m.id = c.media_id
+	INNER JOIN teams t ON t.id = m.team_id
+	WHERE t.hunt_id = $1
+	GROUP BY c.media_id;
+	`
+
+// GetCommentCountsForHunt returns the number of comments on each media
+// file in the given hunt, by media id
+func GetCommentCountsForHunt(huntID int) (map[int]int, *response.Error) {
+	rows, err := stmtMap["mediaCommentCountsForHunt"].Query(huntID)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting comment counts for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	counts := make(map[int]int)
+	for rows.Next() {
+		var mediaID, count int
+		err = rows.Scan(&mediaID, &count)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error getting comment counts for hunt %d: %v",
+				huntID,
+				err,
+			)
+			break
+		}
+
+		counts[mediaID] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error getting comment counts for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+
+	return counts, e.GetError()
+}
+
+var mediaCommentDeleteScript = `
+	DELETE FROM media_comments
+	WHERE id = $1 AND media_id = $2 AND (user_id = $3 OR $4);
+	`
+
+// DeleteMediaComment deletes the comment with the given id AND mediaID. Only
+// the comment's author can delete it unless anyUser is true.
+func DeleteMediaComment(commentID, mediaID, userID int, anyUser bool) *response.Error {
+	res, err := stmtMap["mediaCommentDelete"].Exec(commentID, mediaID, userID, anyUser)
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error deleting comment %d: %v",
+			commentID,
+			err,
+		)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error deleting comment %d: %v",
+			commentID,
+			err,
+		)
+	}
+
+	if n < 1 {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"comment_id: user %d has no comment with id %d on media %d",
+			userID,
+			commentID,
+			mediaID,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package db
+
+import (
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/lib/pq"
+)
+
+// maxEmojiRunes is the most code points an emoji can be made of. Emoji made
+// by joining several emoji, like families, use up to 7.
+const maxEmojiRunes = 8
+
+// IsEmoji returns whether or not the string is a single emoji, which may be
+// built from several code points like skin tone modifiers, variation
+// selectors, and zero width joiners
+func IsEmoji(s string) bool {
+	if s == "" || utf8.RuneCountInString(s) > maxEmojiRunes {
+		return false
+	}
+
+	symbols := 0
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.So, r):
+			symbols++
+		case unicode.In(r, unicode.Sk, unicode.Mn, unicode.Me, unicode.Cf):
+			// modifiers, variation selectors, keycaps, and joiners
+		default:
+			return false
+		}
+	}
+
+	return symbols > 0
+}
+
+// MediaReactionDB is a representation of a row in the media_reactions table
+//
+// swagger:model MediaReaction
+type MediaReactionDB struct {
+
+	// The id of the media the reaction is on
+	//
+	// required: false
+	MediaID int `json:"mediaID" valid:"int,optional"`
+
+	// The id of the user that reacted
+	//
+	// required: false
+	UserID int `json:"userID" valid:"int,optional"`
+
+	// The emoji the user reacted with
+	//
+	// required: true
+	Emoji string `json:"emoji" valid:"-"`
+}
+
+// Validate validates the reaction
+func (m *MediaReactionDB) Validate(r *http.Request) *response.Error {
+	m.Emoji = strings.TrimSpace(m.Emoji)
+	if !IsEmoji(m.Emoji) {
+		return response.NewError(
+			http.StatusBadRequest,
+			"emoji: a reaction must be a single emoji",
+		)
+	}
+
+	return nil
+}
+
+var mediaReactionInsertScript = `
+	INSERT INTO media_reactions(media_id, user_id, emoji)
+	VALUES ($1, $2, $3)
+	ON CONFLICT DO NOTHING;
+	`
+
+// Insert stores the reaction. Reacting with the same emoji twice is not an
+// error.
+func (m *MediaReactionDB) Insert() *response.Error {
+	_, err := stmtMap["mediaReactionInsert"].Exec(m.MediaID, m.UserID, m.Emoji)
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if ok && pqErr.Constraint == "media_reactions_media_id_fkey" {
+			return response.NewErrorf(
+				http.StatusBadRequest,
+				"media_id: media %d does not exist",
+				m.MediaID,
+			)
+		}
+
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error reacting to media %d: %v",
+			m.MediaID,
+			err,
+		)
+	}
+
+	return nil
+}
+
+var mediaReactionDeleteScript = `
+	DELETE FROM media_reactions
+	WHERE media_id = $1 AND user_id = $2 AND emoji = $3;
+	`
+
+// DeleteMediaReaction removes the user's reaction with the given emoji from
+// the media
+func DeleteMediaReaction(mediaID, userID int, emoji string) *response.Error {
+	res, err := stmtMap["mediaReactionDelete"].Exec(mediaID, userID, emoji)
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error deleting reaction to media %d: %v",
+			mediaID,
+			err,
+		)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error deleting reaction to media %d: %v",
+			mediaID,
+			err,
+		)
+	}
+
+	if n < 1 {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"emoji: user %d has not reacted to media %d with %s",
+			userID,
+			mediaID,
+			emoji,
+		)
+	}
+
+	return nil
+}
+
+// ReactionCountDB is the number of users that reacted to a media file with
+// an emoji
+type ReactionCountDB struct {
+	// the emoji
+	Emoji string `json:"emoji"`
+
+	// the number of users that reacted with the emoji
+	Count int `json:"count"`
+
+	// whether or not the requesting user reacted with the emoji
+	Reacted bool `json:"reacted"`
+}
+
+var mediaReactionCountsForHuntScript = `
+	SELECT r.media_id, r.emoji, COUNT(*), BOOL_OR(r.user_id = $2)
+	FROM media_reactions r
+	INNER JOIN media m ON m.id = r.media_id
+	INNER JOIN teams t ON t.id = m.team_id
+	WHERE t.hunt_id = $1
+	GROUP BY r.media_id, r.emoji
+	ORDER BY r.media_id, COUNT(*) DESC, MIN(r.created_at);
+	`
+
+// GetReactionCountsForHunt returns the reactions to each media file in the
+// given hunt, by media id, most used first. Reacted is set on the reactions
+// the given user made.
+func GetReactionCountsForHunt(huntID, userID int) (map[int][]*ReactionCountDB, *response.Error) {
+	rows, err := stmtMap["mediaReactionCountsForHunt"].Query(huntID, userID)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting reactions for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	reactions := make(map[int][]*ReactionCountDB)
+	for rows.Next() {
+		var mediaID int
+		c := ReactionCountDB{}
+		err = rows.Scan(&mediaID, &c.Emoji, &c.Count, &c.Reacted)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error getting reactions for hunt %d: %v",
+				huntID,
+				err,
+			)
+			break
+		}
+
+		reactions[mediaID] = append(reactions[mediaID], &c)
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error getting reactions for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+
+	return reactions, e.GetError()
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"net/http"
+
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// ThumbnailDB is a representation of a row in the media_thumbnails table
+//
+// swagger:model Thumbnail
+type ThumbnailDB struct {
+
+	// The id of the media the thumbnail is a copy of
+	//
+	// required: true
+	MediaID int `json:"mediaID" valid:"int"`
+
+	// The side, in pixels, of the square the thumbnail fits in
+	//
+	// required: true
+	Size int `json:"size" valid:"-"`
+
+	// The width of the thumbnail in pixels
+	//
+	// required: true
+	Width int `json:"width" valid:"-"`
+
+	// The height of the thumbnail in pixels
+	//
+	// required: true
+	Height int `json:"height" valid:"-"`
+
+	// The url where the thumbnail can be retrieved
+	//
+	// required: true
+	URL string `json:"url" valid:"-"`
+}
+
+var thumbnailInsertScript = `
+	INSERT INTO media_thumbnails(media_id, size, width, height, url)
+	VALUES ($1, $2, $3, $4, $5);
+	`
+
+// Insert stores the thumbnail
+func (t *ThumbnailDB) Insert() *response.Error {
+	_, err := stmtMap["thumbnailInsert"].Exec(t.MediaID, t.Size, t.Width, t.Height, t.URL)
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error inserting thumbnail for media %d: %v",
+			t.MediaID,
+			err,
+		)
+	}
+
+	return nil
+}
+
+var thumbnailsForTeamScript = `
+	SELECT t.media_id, t.size, t.width, t.height, t.url
+	FROM media_thumbnails t
+	INNER JOIN media m ON m.id = t.media_id
+	WHERE m.team_id = $1
+	ORDER BY t.media_id, t.size DESC;
+	`
+
+// GetThumbnailsForTeam returns the thumbnails of the team's media mapped by
+// media id, largest first
+func GetThumbnailsForTeam(teamID int) (map[int][]*ThumbnailDB, *response.Error) {
+	rows, err := stmtMap["thumbnailsForTeam"].Query(teamID)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting thumbnails for team %d: %v",
+			teamID,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	thumbnails := make(map[int][]*ThumbnailDB)
+	for rows.Next() {
+		t := ThumbnailDB{}
+		err = rows.Scan(&t.MediaID, &t.Size, &t.Width, &t.Height, &t.URL)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error getting thumbnails for team %d: %v",
+				teamID,
+				err,
+			)
+			break
+		}
+
+		thumbnails[t.MediaID] = append(thumbnails[t.MediaID], &t)
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error getting thumbnails for team %d: %v",
+			teamID,
+			err,
+		)
+	}
+
+	return thumbnails, e.GetError()
+}
@@ -1,9 +1,12 @@
 package db
 
 import (
+	"database/sql"
+	"fmt"
 	"net/http"
 
 	"github.com/cljohnson4343/scavenge/response"
+	"github.com/lib/pq"
 )
 
 // A PlayerDB is a user that has joined a particular hunt.
@@ -118,9 +121,102 @@ func (p *PlayerDB) AddToHunt() *response.Error {
 		p.TeamID,
 	).Scan(&p.TeamID)
 	if err != nil {
+		team := TeamDB{ID: p.TeamID, HuntID: p.HuntID}
+		return team.ParseError(err, "add player to hunt")
+	}
+
+	return nil
+}
+
+var playerIsInHuntScript = `
+	SELECT EXISTS(
+		SELECT 1
+		FROM users_hunts
+		WHERE hunt_id = $1 AND user_id = $2
+	);
+`
+
+// IsHuntPlayer returns whether or not the given user has joined the given hunt
+func IsHuntPlayer(huntID, userID int) (bool, *response.Error) {
+	var isPlayer bool
+	err := stmtMap["playerIsInHunt"].QueryRow(huntID, userID).Scan(&isPlayer)
+	if err != nil {
+		return false, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error checking if user %d is in hunt %d: %v",
+			userID,
+			huntID,
+			err,
+		)
+	}
+
+	return isPlayer, nil
+}
+
+var playerAssignTeamScript = `
+	SELECT COALESCE(ins_team_player(uh.hunt_id, uh.user_id, $3, uh.id), 0)
+	FROM users_hunts uh
+	WHERE uh.hunt_id = $1 AND uh.user_id = $2;
+`
+
+// AssignPlayersToTeams puts each of the given players on its TeamID team in
+// a single transaction, so either every player is assigned or none are.
+// The players must have already joined the hunt and can not be on another
+// team, since moving them would leave them with their old team's roles.
+func AssignPlayersToTeams(huntID int, players []*PlayerDB) *response.Error {
+	tx, err := db.Begin()
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error beginning a transaction: %v",
+			err,
+		)
+	}
+
+	assignStmt := tx.Stmt(stmtMap["playerAssignTeam"])
+	teamForPlayerStmt := tx.Stmt(stmtMap["teamForPlayer"])
+
+	for _, p := range players {
+		var teamID int
+		err := teamForPlayerStmt.QueryRow(huntID, p.ID).Scan(&teamID)
+		if err == nil && teamID != 0 && teamID != p.TeamID {
+			err = fmt.Errorf("player %d is already on team %d", p.ID, teamID)
+		}
+		if err == nil {
+			err = assignStmt.QueryRow(huntID, p.ID, p.TeamID).Scan(&teamID)
+		}
+		if err == nil && teamID != p.TeamID {
+			err = fmt.Errorf("team %d is not part of hunt %d", p.TeamID, huntID)
+		}
+		if err == sql.ErrNoRows {
+			err = fmt.Errorf("player %d has not joined hunt %d", p.ID, huntID)
+		}
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return response.NewErrorf(
+					http.StatusInternalServerError,
+					"error rolling back tx: %v",
+					rollbackErr,
+				)
+			}
+
+			if pqErr, ok := err.(*pq.Error); ok {
+				team := TeamDB{ID: p.TeamID, HuntID: huntID}
+				return team.ParseError(pqErr, "assign player to team")
+			}
+
+			return response.NewErrorf(
+				http.StatusBadRequest,
+				"assignments: %v",
+				err,
+			)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
 		return response.NewErrorf(
 			http.StatusInternalServerError,
-			"error adding player to hunt in AddToHunt: %v",
+			"error committing transaction for assigning players: %v",
 			err,
 		)
 	}
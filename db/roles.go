@@ -258,6 +258,29 @@ func RemoveRole(roleID, userID int) *response.Error {
 	return nil
 }
 
+var rolesRemoveByRegexScript = `
+	DELETE FROM users_roles ur
+	USING roles r
+	WHERE ur.role_id = r.id AND ur.user_id = $1 AND r.name ~ $2;
+	`
+
+// RemoveRolesByRegex removes the roles whose names match the given regex
+// from the given user. It is not an error if the user has none of them.
+func RemoveRolesByRegex(userID int, regex string) *response.Error {
+	_, err := stmtMap["rolesRemoveByRegex"].Exec(userID, regex)
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error removing roles matching %s from user %d: %v",
+			regex,
+			userID,
+			err,
+		)
+	}
+
+	return nil
+}
+
 var rolesDeleteByRegexScript = `
 	DELETE FROM roles
 	WHERE name ~ $1;
@@ -2,7 +2,26 @@ DROP TABLE IF EXISTS users_roles CASCADE;
 DROP TABLE IF EXISTS users_teams CASCADE;
 DROP TABLE IF EXISTS users_hunts CASCADE;
 DROP TABLE IF EXISTS hunt_invitations CASCADE;
+DROP TABLE IF EXISTS hunt_join_codes CASCADE;
+DROP TABLE IF EXISTS hunt_waitlist CASCADE;
+DROP TABLE IF EXISTS user_messages CASCADE;
 DROP TABLE IF EXISTS users_sessions CASCADE;
+DROP TABLE IF EXISTS item_dependencies CASCADE;
+DROP TABLE IF EXISTS boundary_alerts CASCADE;
+DROP TABLE IF EXISTS hunt_boundaries CASCADE;
+DROP TABLE IF EXISTS hunt_events CASCADE;
+DROP TABLE IF EXISTS score_adjustments CASCADE;
+DROP TABLE IF EXISTS media_votes CASCADE;
+DROP TABLE IF EXISTS voting_rounds CASCADE;
+DROP TABLE IF EXISTS media_reactions CASCADE;
+DROP TABLE IF EXISTS media_comments CASCADE;
+DROP TABLE IF EXISTS hint_unlocks CASCADE;
+DROP TABLE IF EXISTS item_hints CASCADE;
+DROP TABLE IF EXISTS submission_flags CASCADE;
+DROP TABLE IF EXISTS item_claims CASCADE;
+DROP TABLE IF EXISTS blob_outbox CASCADE;
+DROP TABLE IF EXISTS uploads CASCADE;
+DROP TABLE IF EXISTS media_thumbnails CASCADE;
 DROP TABLE IF EXISTS media CASCADE;
 DROP TABLE IF EXISTS locations CASCADE;
 DROP TABLE IF EXISTS items CASCADE;
@@ -11,8 +30,12 @@ DROP TABLE IF EXISTS hunts CASCADE;
 DROP TABLE IF EXISTS permissions CASCADE;
 DROP TABLE IF EXISTS roles CASCADE;
 DROP TABLE IF EXISTS users CASCADE;
+DROP FUNCTION IF EXISTS enqueue_deleted_url() CASCADE;
+DROP FUNCTION IF EXISTS enqueue_deleted_upload() CASCADE;
 
 CREATE EXTENSION IF NOT EXISTS plpgsql;
+CREATE EXTENSION IF NOT EXISTS cube;
+CREATE EXTENSION IF NOT EXISTS earthdistance;
 
 /*
     This table represents a user. 
@@ -69,6 +92,9 @@ CREATE TABLE hunts (
     id              serial,
     name            varchar(255) NOT NULL,
     max_teams       smallint NOT NULL CONSTRAINT positive_num_teams CHECK (max_teams > 0),
+    max_players_per_team smallint CONSTRAINT positive_max_players CHECK (max_players_per_team > 0),
+    self_service_teams boolean NOT NULL DEFAULT false,
+    max_upload_size bigint CONSTRAINT positive_max_upload_size CHECK (max_upload_size > 0),
     start_time      timestamp NOT NULL,
     end_time        timestamp NOT NULL,
     latitude        real NOT NULL,
@@ -81,6 +107,7 @@ CREATE TABLE hunts (
     PRIMARY KEY(id),
     FOREIGN KEY (creator_id) REFERENCES users(id) ON DELETE CASCADE
 );
+CREATE INDEX hunts_earth_location_idx ON hunts USING gist (ll_to_earth(latitude, longitude));
 
 /*
     This table represents a team for a specific hunt.
@@ -95,12 +122,19 @@ CREATE TABLE teams (
     id              serial,
     hunt_id         int NOT NULL,
     name            varchar(255) NOT NULL CHECK (length(name) > 0),
+    join_code       varchar(16),
     CONSTRAINT teams_in_same_hunt_name UNIQUE(hunt_id, name),
+    CONSTRAINT team_join_code_unique UNIQUE(join_code),
     PRIMARY KEY(id),
     FOREIGN KEY (hunt_id) REFERENCES hunts(id) ON DELETE CASCADE
 );
 CREATE INDEX teams_huntid_asc ON teams(hunt_id ASC);
 
+/*
+    ins_team inserts a team for the given hunt. The hunt row is locked so
+    concurrent inserts can not push the hunt past its max_teams. A full hunt
+    raises a check_violation for the hunt_max_teams constraint.
+*/
 CREATE OR REPLACE FUNCTION ins_team(_hunt_id int, _team_name varchar(255), OUT _id int)
 AS $func$
 DECLARE
@@ -108,11 +142,13 @@ DECLARE
 BEGIN
     SELECT h.max_teams FROM hunts h WHERE h.id = _hunt_id FOR UPDATE INTO _max_teams;  -- write lock
 
+    IF (SELECT count(*) FROM teams t WHERE t.hunt_id = _hunt_id) >= _max_teams THEN
+        RAISE EXCEPTION 'hunt % has reached its maximum number of teams', _hunt_id
+        USING ERRCODE = 'check_violation', CONSTRAINT = 'hunt_max_teams';
+    END IF;
+
     INSERT INTO teams(hunt_id, name)
-    SELECT _hunt_id, _team_name 
-    FROM teams
-    WHERE  teams.hunt_id = _hunt_id
-    HAVING count(*) < _max_teams
+    VALUES (_hunt_id, _team_name)
     RETURNING teams.id
     INTO _id;
 
@@ -153,11 +189,59 @@ CREATE TABLE items (
     hunt_id         int NOT NULL,
     name            varchar(255) NOT NULL CHECK (length(name) > 0),
     points          int DEFAULT 1 CHECK (points > 0),
+    item_type       varchar(16) NOT NULL DEFAULT 'photo',
+    answers         text[],
+    checkin_code    varchar(64),
+    latitude        real,
+    longitude       real,
+    radius          int,
+    enforce_geofence boolean NOT NULL DEFAULT false,
+    visible_from    timestamp,
+    visible_until   timestamp,
+    category        varchar(64),
+    tags            text[] NOT NULL DEFAULT '{}',
+    difficulty      smallint CHECK (difficulty BETWEEN 1 AND 5),
     CONSTRAINT items_in_same_hunt_name UNIQUE(hunt_id, name),
+    CONSTRAINT visible_window_order CHECK (visible_from < visible_until),
+    CONSTRAINT valid_item_type CHECK (
+        item_type IN ('photo', 'video', 'text', 'checkin', 'gps')
+    ),
+    CONSTRAINT text_item_has_answers CHECK (
+        item_type <> 'text' OR cardinality(answers) > 0
+    ),
+    CONSTRAINT checkin_item_has_code CHECK (
+        item_type <> 'checkin' OR length(checkin_code) > 0
+    ),
+    CONSTRAINT gps_item_has_point CHECK (
+        item_type <> 'gps' OR 
+        (latitude IS NOT NULL AND longitude IS NOT NULL AND radius > 0)
+    ),
+    CONSTRAINT item_target_complete CHECK (
+        (latitude IS NULL AND longitude IS NULL AND radius IS NULL) OR
+        (latitude IS NOT NULL AND longitude IS NOT NULL AND radius > 0)
+    ),
     PRIMARY KEY(id),
     FOREIGN KEY (hunt_id) REFERENCES hunts(id) ON DELETE CASCADE 
 );
 CREATE INDEX items_huntid_asc ON items(hunt_id ASC);
+CREATE INDEX items_hunt_category_asc ON items(hunt_id ASC, category ASC);
+
+/*
+    This table is used to store the dependencies between items. An
+    item is hidden from a team until the team has completed every
+    item it depends on.
+
+    relations:
+        many to many--items can depend on many items
+*/
+CREATE TABLE item_dependencies (
+    item_id         int NOT NULL,
+    depends_on_id   int NOT NULL,
+    CONSTRAINT item_not_own_dependency CHECK (item_id <> depends_on_id),
+    PRIMARY KEY(item_id, depends_on_id),
+    FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE,
+    FOREIGN KEY (depends_on_id) REFERENCES items(id) ON DELETE CASCADE
+);
 
 /*
     This table is used to store the team specific media info.
@@ -178,6 +262,10 @@ CREATE TABLE media (
     item_id         int,
     location_id     int NOT NULL,
     url             varchar(2083) NOT NULL CHECK (length(url) > 3),
+    taken_at        timestamp,
+    taken_latitude  real,
+    taken_longitude real,
+    phash           bigint,
     CONSTRAINT media_for_same_item UNIQUE(item_id),
     PRIMARY KEY(id),
     FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
@@ -186,6 +274,404 @@ CREATE TABLE media (
 );
 CREATE INDEX media_teams_and_loc_asc ON media(team_id ASC, location_id ASC);
 
+/*
+    This table represents a scaled down copy of a media photo.
+
+    relations:
+        many to one--a media row can have many thumbnails
+*/
+CREATE TABLE media_thumbnails (
+    media_id        int NOT NULL,
+    size            int NOT NULL,
+    width           int NOT NULL,
+    height          int NOT NULL,
+    url             varchar(2083) NOT NULL CHECK (length(url) > 3),
+    PRIMARY KEY(media_id, size),
+    FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE
+);
+
+/*
+    This table represents a slot for uploading media straight to storage. A
+    slot is confirmed once the upload is done and its media row is created.
+
+    relations:
+        many to one--a team can have many uploads
+        many to one--a user can have many uploads
+*/
+CREATE TABLE uploads (
+    id              serial,
+    team_id         int NOT NULL,
+    user_id         int NOT NULL,
+    key             varchar(255) NOT NULL,
+    content_type    varchar(255) NOT NULL,
+    size            bigint NOT NULL CHECK (size > 0),
+    expires_at      timestamp NOT NULL,
+    confirmed_at    timestamp,
+    created_at      timestamp NOT NULL DEFAULT NOW(),
+    CONSTRAINT upload_key_unique UNIQUE(key),
+    PRIMARY KEY(id),
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX uploads_team_asc ON uploads(team_id ASC);
+
+/*
+    This table is used to store the keys of the blobs that have to be
+    deleted from storage. The triggers below add a row whenever a media,
+    thumbnail, or unconfirmed upload row is deleted, including when it
+    is deleted because its hunt or team was, so deleting a row never
+    leaves its blob behind. Only unconfirmed uploads are queued since a
+    confirmed upload's file is stored again for its media row and its
+    own blob is deleted then. A row is removed once its blob is deleted.
+*/
+CREATE TABLE blob_outbox (
+    id              serial,
+    key             varchar(2083) NOT NULL,
+    attempts        int NOT NULL DEFAULT 0,
+    created_at      timestamp NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id)
+);
+
+CREATE FUNCTION enqueue_deleted_url() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO blob_outbox(key) VALUES (regexp_replace(OLD.url, '^.*/', ''));
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION enqueue_deleted_upload() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO blob_outbox(key) VALUES (OLD.key);
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER media_blob_delete AFTER DELETE ON media
+    FOR EACH ROW EXECUTE PROCEDURE enqueue_deleted_url();
+CREATE TRIGGER media_thumbnails_blob_delete AFTER DELETE ON media_thumbnails
+    FOR EACH ROW EXECUTE PROCEDURE enqueue_deleted_url();
+
+CREATE TRIGGER uploads_blob_delete AFTER DELETE ON uploads
+    FOR EACH ROW WHEN (OLD.confirmed_at IS NULL)
+    EXECUTE PROCEDURE enqueue_deleted_upload();
+
+/*
+    This table is used to store the items teams have claimed without
+    uploading media, i.e. text, checkin, and gps items. Each row
+    records the answer, code, or point the claim was made with.
+
+    relations:
+        many to one--claims can have the same team
+        many to one--claims can have the same item
+        many to one--claims can have the same user
+*/
+CREATE TABLE item_claims (
+    id              serial,
+    item_id         int NOT NULL,
+    team_id         int NOT NULL,
+    user_id         int NOT NULL,
+    answer          text,
+    latitude        real,
+    longitude       real,
+    claimed_at      timestamp NOT NULL DEFAULT NOW(),
+    CONSTRAINT team_claims_item_once UNIQUE(team_id, item_id),
+    PRIMARY KEY(id),
+    FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE,
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX item_claims_team_asc ON item_claims(team_id ASC);
+
+/*
+    This table is used to store the submissions that look like
+    cheating so the hunt's owner can review them. A submission is
+    flagged when it was made from outside its item's radius, when
+    the team would have had to travel impossibly fast to make it, or
+    when its photo looks like another photo uploaded in the same hunt.
+    match_media_id is the other photo of a duplicate_photo flag.
+
+    relations:
+        many to one--flags can have the same team
+        many to one--flags can have the same item
+        one to one--flags will have at most one media row
+        one to one--flags will have at most one item claim
+*/
+CREATE TABLE submission_flags (
+    id              serial,
+    team_id         int NOT NULL,
+    item_id         int,
+    media_id        int,
+    claim_id        int,
+    match_media_id  int,
+    reason          varchar(32) NOT NULL,
+    detail          text NOT NULL DEFAULT '',
+    latitude        real NOT NULL,
+    longitude       real NOT NULL,
+    created_at      timestamp NOT NULL DEFAULT NOW(),
+    CONSTRAINT valid_flag_reason CHECK (
+        reason IN ('outside_geofence', 'impossible_travel', 'duplicate_photo', 'out_of_bounds')
+    ),
+    PRIMARY KEY(id),
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
+    FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE,
+    FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE,
+    FOREIGN KEY (claim_id) REFERENCES item_claims(id) ON DELETE CASCADE,
+    FOREIGN KEY (match_media_id) REFERENCES media(id) ON DELETE SET NULL
+);
+CREATE INDEX submission_flags_team_asc ON submission_flags(team_id ASC);
+
+/*
+    This table is used to store the hints for each item. Hints are
+    revealed in order of position, either once reveal_after minutes
+    have passed since the hunt started or when a team unlocks them
+    for cost points.
+
+    relations:
+        many to one--hints can have the same item
+*/
+CREATE TABLE item_hints (
+    id              serial,
+    item_id         int NOT NULL,
+    position        smallint NOT NULL CHECK (position > 0),
+    body            text NOT NULL CHECK (length(body) > 0),
+    cost            int NOT NULL DEFAULT 0 CHECK (cost >= 0),
+    reveal_after    int CHECK (reveal_after > 0),
+    CONSTRAINT hint_position_unique UNIQUE(item_id, position),
+    PRIMARY KEY(id),
+    FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE
+);
+
+/*
+    This table is used to store the hints each team has unlocked. The
+    cost is copied from the hint when it is unlocked and subtracted
+    from the team's points.
+
+    relations:
+        many to one--unlocks can have the same team
+        many to one--unlocks can have the same hint
+*/
+CREATE TABLE hint_unlocks (
+    id              serial,
+    hint_id         int NOT NULL,
+    team_id         int NOT NULL,
+    cost            int NOT NULL DEFAULT 0,
+    unlocked_at     timestamp NOT NULL DEFAULT NOW(),
+    CONSTRAINT team_unlocks_hint_once UNIQUE(team_id, hint_id),
+    PRIMARY KEY(id),
+    FOREIGN KEY (hint_id) REFERENCES item_hints(id) ON DELETE CASCADE,
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE
+);
+CREATE INDEX hint_unlocks_team_asc ON hint_unlocks(team_id ASC);
+
+/*
+    This table is used to store the comments hunt participants leave
+    on media.
+
+    relations:
+        many to one--comments can have the same media row
+        many to one--comments can have the same user
+*/
+CREATE TABLE media_comments (
+    id              serial,
+    media_id        int NOT NULL,
+    user_id         int NOT NULL,
+    body            text NOT NULL CHECK (length(body) BETWEEN 1 AND 1000),
+    created_at      timestamp NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id),
+    FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX media_comments_media_asc ON media_comments(media_id ASC);
+
+/*
+    This table is used to store the emoji reactions hunt participants
+    leave on media. A user can react to a media row with each emoji
+    once.
+
+    relations:
+        many to one--reactions can have the same media row
+        many to one--reactions can have the same user
+*/
+CREATE TABLE media_reactions (
+    media_id        int NOT NULL,
+    user_id         int NOT NULL,
+    emoji           varchar(32) NOT NULL,
+    created_at      timestamp NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(media_id, user_id, emoji),
+    FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+/*
+    This table is used to store the best photo voting round of a hunt.
+    Players vote once the hunt has ended and until ends_at. When the
+    round is finalized the teams of the winners most voted media are
+    awarded bonus_points each.
+
+    relations:
+        one to one--a hunt has at most one voting round
+*/
+CREATE TABLE voting_rounds (
+    hunt_id             int NOT NULL,
+    ends_at             timestamp NOT NULL,
+    winners             smallint NOT NULL DEFAULT 3 CHECK (winners > 0),
+    bonus_points        int NOT NULL CHECK (bonus_points > 0),
+    votes_per_player    smallint NOT NULL DEFAULT 3 CHECK (votes_per_player > 0),
+    finalized_at        timestamp,
+    PRIMARY KEY(hunt_id),
+    FOREIGN KEY (hunt_id) REFERENCES hunts(id) ON DELETE CASCADE
+);
+
+/*
+    This table is used to store the best photo votes. A user can vote
+    for a media row once.
+
+    relations:
+        many to one--votes can have the same voting round
+        many to one--votes can have the same media row
+        many to one--votes can have the same user
+*/
+CREATE TABLE media_votes (
+    hunt_id         int NOT NULL,
+    media_id        int NOT NULL,
+    user_id         int NOT NULL,
+    created_at      timestamp NOT NULL DEFAULT NOW(),
+    CONSTRAINT user_votes_media_once PRIMARY KEY(media_id, user_id),
+    FOREIGN KEY (hunt_id) REFERENCES voting_rounds(hunt_id) ON DELETE CASCADE,
+    FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX media_votes_hunt_user ON media_votes(hunt_id, user_id);
+
+/*
+    ins_media_vote records the user's vote for the media in the hunt's
+    voting round and returns whether the vote was counted. The voting round
+    row is locked so concurrent votes can not use more than its
+    votes_per_player. false is returned when the round has been finalized
+    or the user has no votes left.
+*/
+CREATE OR REPLACE FUNCTION ins_media_vote(_hunt_id int, _media_id int, _user_id int)
+RETURNS boolean
+AS $func$
+DECLARE
+    _votes_per_player int;
+BEGIN
+    SELECT v.votes_per_player 
+    FROM voting_rounds v 
+    WHERE v.hunt_id = _hunt_id AND v.finalized_at IS NULL 
+    FOR UPDATE 
+    INTO _votes_per_player;  -- write lock
+
+    IF _votes_per_player IS NULL THEN
+        RETURN false;
+    END IF;
+
+    IF (SELECT count(*) FROM media_votes mv WHERE mv.hunt_id = _hunt_id AND mv.user_id = _user_id) >= _votes_per_player THEN
+        RETURN false;
+    END IF;
+
+    INSERT INTO media_votes(hunt_id, media_id, user_id)
+    VALUES (_hunt_id, _media_id, _user_id);
+
+    RETURN true;
+END; $func$
+LANGUAGE plpgsql;
+
+/*
+    This table is used to store the points a team is awarded, or loses,
+    outside of completing items, like the bonus for a best photo. The
+    points are added to the team's points.
+
+    relations:
+        many to one--adjustments can have the same team
+        many to one--adjustments can have the same media row
+*/
+CREATE TABLE score_adjustments (
+    id              serial,
+    team_id         int NOT NULL,
+    points          int NOT NULL,
+    reason          varchar(255) NOT NULL,
+    media_id        int,
+    created_at      timestamp NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id),
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
+    FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE SET NULL
+);
+CREATE INDEX score_adjustments_team_asc ON score_adjustments(team_id ASC);
+
+/*
+    This table is used to store the events streamed to the members of a
+    hunt, like submissions, score changes, and announcements. Clients
+    that reconnect to the stream are sent the events after the id of the
+    last event they received. team_id is the team the event is about, if
+    any. It is not a foreign key so the events of deleted teams are kept.
+
+    relations:
+        many to one--events can have the same hunt
+*/
+CREATE TABLE hunt_events (
+    id              bigserial,
+    hunt_id         int NOT NULL,
+    team_id         int,
+    type            varchar(32) NOT NULL,
+    data            jsonb NOT NULL DEFAULT '{}',
+    created_at      timestamp NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id),
+    FOREIGN KEY (hunt_id) REFERENCES hunts(id) ON DELETE CASCADE
+);
+CREATE INDEX hunt_events_hunt_id ON hunt_events(hunt_id, id);
+
+/*
+    This table is used to store the area a hunt is played in. The boundary
+    is a polygon whose vertices are the pairs of latitudes and longitudes,
+    in order. Teams that report a location outside of it raise an alert,
+    and submissions made outside of it are flagged, or rejected if
+    reject_submissions is set.
+
+    relations:
+        one to one--a hunt can have a single boundary
+*/
+CREATE TABLE hunt_boundaries (
+    hunt_id             int NOT NULL,
+    latitudes           double precision[] NOT NULL,
+    longitudes          double precision[] NOT NULL,
+    reject_submissions  boolean NOT NULL DEFAULT false,
+    updated_at          timestamp NOT NULL DEFAULT NOW(),
+    CONSTRAINT boundary_is_polygon CHECK (
+        array_length(latitudes, 1) >= 3 AND
+        array_length(latitudes, 1) = array_length(longitudes, 1)
+    ),
+    PRIMARY KEY(hunt_id),
+    FOREIGN KEY (hunt_id) REFERENCES hunts(id) ON DELETE CASCADE
+);
+
+/*
+    This table is used to store the times a team left its hunt's boundary.
+    The alert is open, returned_at is NULL, until the team reports a
+    location inside the boundary again. A team can only have one open
+    alert. location_id is the location the team was first seen outside at,
+    and distance is how far outside it was, in meters.
+
+    relations:
+        many to one--alerts can have the same team
+        one to one--an alert can have a single location
+*/
+CREATE TABLE boundary_alerts (
+    id              serial,
+    team_id         int NOT NULL,
+    location_id     int,
+    latitude        real NOT NULL,
+    longitude       real NOT NULL,
+    distance        real NOT NULL,
+    left_at         timestamp NOT NULL,
+    returned_at     timestamp,
+    PRIMARY KEY(id),
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
+    FOREIGN KEY (location_id) REFERENCES locations(id) ON DELETE SET NULL
+);
+CREATE UNIQUE INDEX boundary_alerts_one_open ON boundary_alerts(team_id)
+    WHERE returned_at IS NULL;
+
 /*
     This table is used to store the roles.
 
@@ -320,6 +806,32 @@ CREATE TABLE hunt_invitations (
 );
 CREATE INDEX hunt_invitations_email_idx ON hunt_invitations(email ASC);
 
+/*
+    This table is used to store the join codes for a hunt. Anyone that knows
+    a join code, or has a link signed for it, can join the hunt until the code
+    expires or has been used max_uses times. A NULL max_uses or expires_at
+    means there is no limit.
+
+    relations:
+        many to one--There can be many join codes associated with a hunt.
+*/
+CREATE TABLE hunt_join_codes (
+    id                  serial,
+    hunt_id             int NOT NULL,
+    code                varchar(16) NOT NULL,
+    creator_id          int NOT NULL,
+    max_uses            int CONSTRAINT positive_max_uses CHECK (max_uses > 0),
+    uses                int NOT NULL DEFAULT 0,
+    expires_at          timestamp,
+    created_at          timestamp DEFAULT NOW(),
+
+    PRIMARY KEY(id),
+    CONSTRAINT join_code_unique UNIQUE(code),
+    FOREIGN KEY (hunt_id) REFERENCES hunts(id) ON DELETE CASCADE,
+    FOREIGN KEY (creator_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX hunt_join_codes_huntid_asc ON hunt_join_codes(hunt_id ASC);
+
 /* 
     This table associates users that have joined a hunt with the hunt
     they joined.
@@ -357,6 +869,17 @@ BEGIN
     INTO _team_id_out;
 
     IF _team_id IN (SELECT t.id AS team FROM teams t WHERE t.hunt_id = _hunt_id) THEN
+        IF _team_id_out IS DISTINCT FROM _team_id THEN
+            -- lock the team so concurrent adds can not push it past capacity
+            PERFORM t.id FROM teams t WHERE t.id = _team_id FOR UPDATE;
+
+            IF (SELECT count(*) FROM users_teams ut WHERE ut.team_id = _team_id) >= 
+                (SELECT h.max_players_per_team FROM hunts h WHERE h.id = _hunt_id) THEN
+                RAISE EXCEPTION 'team % has reached its maximum number of players', _team_id
+                USING ERRCODE = 'check_violation', CONSTRAINT = 'team_at_capacity';
+            END IF;
+        END IF;
+
         IF _team_id_out != 0 THEN 
 
             DELETE FROM users_teams ut
@@ -437,6 +960,7 @@ CREATE TABLE users_teams (
     
     PRIMARY KEY(id), 
     CONSTRAINT users_on_same_team UNIQUE(team_id, user_id),
+    CONSTRAINT one_team_per_hunt UNIQUE(users_hunts_id),
     FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
     FOREIGN KEY (users_hunts_id) REFERENCES users_hunts(id) ON DELETE CASCADE
@@ -462,3 +986,149 @@ CREATE TABLE users_roles (
 );
 CREATE INDEX users_roles_user_id_idx ON users_roles(user_id ASC);
 CREATE INDEX users_roles_role_id_idx ON users_roles(role_id ASC);
+
+/*
+    This table is used to store the waitlist for full hunts and teams. An
+    entry with a team_name is waiting for a spot to create that team in the
+    hunt. An entry with a team_id is waiting for a spot on that team.
+    Entries are promoted, oldest first, by promote_waitlist.
+
+    relations:
+        many to one--There can be many waitlist entries associated with a hunt.
+        many to one--There can be many waitlist entries associated with a team.
+*/
+CREATE TABLE hunt_waitlist (
+    id                  serial,
+    hunt_id             int NOT NULL,
+    user_id             int NOT NULL,
+    team_id             int,
+    team_name           varchar(255) CHECK (length(team_name) > 0),
+    created_at          timestamp DEFAULT NOW(),
+
+    PRIMARY KEY(id),
+    CONSTRAINT waitlist_team_or_name CHECK ((team_id IS NULL) <> (team_name IS NULL)),
+    CONSTRAINT user_waitlisted_once UNIQUE(hunt_id, user_id),
+    FOREIGN KEY (hunt_id) REFERENCES hunts(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE
+);
+CREATE INDEX hunt_waitlist_huntid_asc ON hunt_waitlist(hunt_id ASC, created_at ASC);
+
+/*
+    promote_waitlist fills the open team and player spots of the given hunt
+    from its waitlist and returns the promoted entries. The hunt row is
+    locked like in ins_team, and the entries are locked with SKIP LOCKED so
+    an entry is only ever promoted once. The user of a team entry is added
+    to the hunt as a player of the new team. A team entry whose name was
+    taken while it waited is removed and returned with a _team_id of 0, and
+    its user is added to the hunt without a team. Entries whose user joined
+    a team while waiting are removed without being promoted, so nobody is
+    ever moved off of a team by the waitlist.
+*/
+CREATE OR REPLACE FUNCTION promote_waitlist(_hunt_id int)
+RETURNS TABLE(_entry_id int, _user_id int, _team_id int, _team_name varchar(255))
+AS $func$
+DECLARE
+    _max_teams int;
+    _max_players int;
+    _entry hunt_waitlist%ROWTYPE;
+BEGIN
+    SELECT h.max_teams, h.max_players_per_team 
+    FROM hunts h 
+    WHERE h.id = _hunt_id 
+    FOR UPDATE 
+    INTO _max_teams, _max_players;  -- write lock
+
+    FOR _entry IN
+        SELECT * 
+        FROM hunt_waitlist w 
+        WHERE w.hunt_id = _hunt_id AND w.team_name IS NOT NULL 
+        ORDER BY w.created_at, w.id
+        FOR UPDATE SKIP LOCKED
+    LOOP
+        IF EXISTS (
+            SELECT 1
+            FROM users_teams ut
+            INNER JOIN teams t
+                ON t.id = ut.team_id AND t.hunt_id = _hunt_id
+            WHERE ut.user_id = _entry.user_id
+        ) THEN
+            DELETE FROM hunt_waitlist w WHERE w.id = _entry.id;
+            CONTINUE;
+        END IF;
+
+        EXIT WHEN (SELECT count(*) FROM teams t WHERE t.hunt_id = _hunt_id) >= _max_teams;
+
+        _team_id := NULL;
+        INSERT INTO teams(hunt_id, name)
+        VALUES (_hunt_id, _entry.team_name)
+        ON CONFLICT DO NOTHING
+        RETURNING id
+        INTO _team_id;
+
+        -- the user joins the hunt even when the name was taken so the join
+        -- code or invitation they waited with is not wasted
+        PERFORM ins_hunt_player(_hunt_id, _entry.user_id, _team_id);
+
+        DELETE FROM hunt_waitlist w WHERE w.id = _entry.id;
+
+        _entry_id := _entry.id;
+        _user_id := _entry.user_id;
+        _team_id := COALESCE(_team_id, 0);
+        _team_name := _entry.team_name;
+        RETURN NEXT;
+    END LOOP;
+
+    FOR _entry IN
+        SELECT * 
+        FROM hunt_waitlist w 
+        WHERE w.hunt_id = _hunt_id AND w.team_id IS NOT NULL 
+        ORDER BY w.created_at, w.id
+        FOR UPDATE SKIP LOCKED
+    LOOP
+        IF EXISTS (
+            SELECT 1
+            FROM users_teams ut
+            INNER JOIN teams t
+                ON t.id = ut.team_id AND t.hunt_id = _hunt_id
+            WHERE ut.user_id = _entry.user_id
+        ) THEN
+            DELETE FROM hunt_waitlist w WHERE w.id = _entry.id;
+            CONTINUE;
+        END IF;
+
+        CONTINUE WHEN (SELECT count(*) FROM users_teams ut WHERE ut.team_id = _entry.team_id) >= _max_players;
+
+        PERFORM ins_hunt_player(_hunt_id, _entry.user_id, _entry.team_id);
+
+        DELETE FROM hunt_waitlist w WHERE w.id = _entry.id;
+
+        _entry_id := _entry.id;
+        _user_id := _entry.user_id;
+        _team_id := _entry.team_id;
+        _team_name := NULL;
+        RETURN NEXT;
+    END LOOP;
+
+END; $func$
+LANGUAGE plpgsql;
+
+/*
+    This table is used to store messages for users, e.g. letting a user 
+    know they were promoted off of a waitlist.
+
+    relations:
+        many to one--There can be many messages associated with a user.
+*/
+CREATE TABLE user_messages (
+    id                  serial,
+    user_id             int NOT NULL,
+    hunt_id             int,
+    body                text NOT NULL,
+    created_at          timestamp DEFAULT NOW(),
+
+    PRIMARY KEY(id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (hunt_id) REFERENCES hunts(id) ON DELETE CASCADE
+);
+CREATE INDEX user_messages_userid_asc ON user_messages(user_id ASC, created_at ASC);
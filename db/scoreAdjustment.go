@@ -0,0 +1,105 @@
+package db
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// ScoreAdjustmentDB is a representation of a row in the score_adjustments
+// table. An adjustment adds points to, or takes points from, a team outside
+// of completing items.
+//
+// swagger:model ScoreAdjustment
+type ScoreAdjustmentDB struct {
+
+	// The id of the adjustment
+	//
+	// required: false
+	ID int `json:"adjustmentID" valid:"int,optional"`
+
+	// The id of the team the adjustment is for
+	//
+	// required: true
+	TeamID int `json:"teamID" valid:"int"`
+
+	// The points added to the team's points, negative if the team lost
+	// points
+	//
+	// required: true
+	Points int `json:"points" valid:"-"`
+
+	// Why the team's points were adjusted
+	//
+	// required: true
+	Reason string `json:"reason" valid:"-"`
+
+	// The id of the media the adjustment was made for, if any
+	//
+	// required: false
+	MediaID int `json:"mediaID,omitempty" valid:"int,optional"`
+
+	// The time the adjustment was made
+	//
+	// required: false
+	// swagger:strfmt date
+	CreatedAt time.Time `json:"createdAt" valid:"-"`
+}
+
+var scoreAdjustmentInsertScript = `
+	INSERT INTO score_adjustments(team_id, points, reason, media_id)
+	VALUES ($1, $2, $3, NULLIF($4, 0))
+	RETURNING id, created_at;
+	`
+
+var scoreAdjustmentsForTeamScript = `
+	SELECT id, team_id, points, reason, COALESCE(media_id, 0), created_at
+	FROM score_adjustments
+	WHERE team_id = $1
+	ORDER BY created_at ASC, id ASC;
+	`
+
+// GetScoreAdjustmentsForTeam returns the adjustments to the team's points,
+// oldest first
+func GetScoreAdjustmentsForTeam(teamID int) ([]*ScoreAdjustmentDB, *response.Error) {
+	rows, err := stmtMap["scoreAdjustmentsForTeam"].Query(teamID)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting score adjustments for team %d: %v",
+			teamID,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	adjustments := make([]*ScoreAdjustmentDB, 0)
+	for rows.Next() {
+		a := ScoreAdjustmentDB{}
+		err = rows.Scan(&a.ID, &a.TeamID, &a.Points, &a.Reason, &a.MediaID, &a.CreatedAt)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error getting score adjustments for team %d: %v",
+				teamID,
+				err,
+			)
+			break
+		}
+
+		adjustments = append(adjustments, &a)
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error getting score adjustments for team %d: %v",
+			teamID,
+			err,
+		)
+	}
+
+	return adjustments, e.GetError()
+}
@@ -0,0 +1,219 @@
+package db
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// the reasons a submission can be flagged
+const (
+	FlagOutsideGeofence  = "outside_geofence"
+	FlagImpossibleTravel = "impossible_travel"
+	FlagDuplicatePhoto   = "duplicate_photo"
+	FlagOutOfBounds      = "out_of_bounds"
+)
+
+// SubmissionFlagDB is a representation of a row in the submission_flags
+// table. A flag marks a media upload or item claim that looks like cheating.
+//
+// swagger:model SubmissionFlag
+type SubmissionFlagDB struct {
+
+	// The id of the flag
+	//
+	// required: false
+	ID int `json:"flagID" valid:"int,optional"`
+
+	// The id of the team that made the submission
+	//
+	// required: true
+	TeamID int `json:"teamID" valid:"int"`
+
+	// The id of the item the submission was for, if any
+	//
+	// required: false
+	ItemID int `json:"itemID,omitempty" valid:"int,optional"`
+
+	// The id of the flagged media, if the submission was an upload
+	//
+	// required: false
+	MediaID int `json:"mediaID,omitempty" valid:"int,optional"`
+
+	// The id of the flagged item claim, if the submission was a claim
+	//
+	// required: false
+	ClaimID int `json:"claimID,omitempty" valid:"int,optional"`
+
+	// The id of the media the flagged photo looks like, if it was flagged as
+	// a duplicate
+	//
+	// required: false
+	MatchMediaID int `json:"matchMediaID,omitempty" valid:"int,optional"`
+
+	// Why the submission was flagged: outside_geofence, impossible_travel,
+	// duplicate_photo, or out_of_bounds
+	//
+	// required: true
+	Reason string `json:"reason" valid:"-"`
+
+	// A description of what was wrong with the submission
+	//
+	// required: false
+	Detail string `json:"detail" valid:"-"`
+
+	// The latitude the submission was made from
+	//
+	// required: true
+	Latitude float32 `json:"latitude" valid:"-"`
+
+	// The longitude the submission was made from
+	//
+	// required: true
+	Longitude float32 `json:"longitude" valid:"-"`
+
+	// The time the submission was flagged
+	//
+	// required: false
+	// swagger:strfmt date
+	CreatedAt time.Time `json:"createdAt" valid:"-"`
+}
+
+// Validate is a dummy function because SubmissionFlagDB model is server
+// generated and not meant to be posted by clients
+func (f *SubmissionFlagDB) Validate(r *http.Request) *response.Error {
+	return nil
+}
+
+var submissionFlagInsertScript = `
+	INSERT INTO submission_flags(team_id, item_id, media_id, claim_id, 
+		match_media_id, reason, detail, latitude, longitude)
+	VALUES ($1, NULLIF($2, 0), NULLIF($3, 0), NULLIF($4, 0), NULLIF($5, 0), $6, 
+		$7, $8, $9)
+	RETURNING id, created_at;
+	`
+
+// Insert stores the flag
+func (f *SubmissionFlagDB) Insert() *response.Error {
+	err := stmtMap["submissionFlagInsert"].QueryRow(
+		f.TeamID,
+		f.ItemID,
+		f.MediaID,
+		f.ClaimID,
+		f.MatchMediaID,
+		f.Reason,
+		f.Detail,
+		f.Latitude,
+		f.Longitude,
+	).Scan(&f.ID, &f.CreatedAt)
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error flagging submission for team %d: %v",
+			f.TeamID,
+			err,
+		)
+	}
+
+	return nil
+}
+
+var submissionFlagsForHuntScript = `
+	SELECT f.id, f.team_id, COALESCE(f.item_id, 0), COALESCE(f.media_id, 0), 
+		COALESCE(f.claim_id, 0), COALESCE(f.match_media_id, 0), f.reason, f.detail, f.latitude, f.longitude, 
+		f.created_at
+	FROM submission_flags f
+	INNER JOIN teams t ON t.id = f.team_id
+	WHERE t.hunt_id = $1
+	ORDER BY f.created_at DESC, f.id DESC;
+	`
+
+// GetSubmissionFlagsForHunt returns the flagged submissions of the teams in
+// the given hunt, newest first
+func GetSubmissionFlagsForHunt(huntID int) ([]*SubmissionFlagDB, *response.Error) {
+	rows, err := stmtMap["submissionFlagsForHunt"].Query(huntID)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting flags for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	flags := make([]*SubmissionFlagDB, 0)
+	for rows.Next() {
+		f := SubmissionFlagDB{}
+		err = rows.Scan(
+			&f.ID,
+			&f.TeamID,
+			&f.ItemID,
+			&f.MediaID,
+			&f.ClaimID,
+			&f.MatchMediaID,
+			&f.Reason,
+			&f.Detail,
+			&f.Latitude,
+			&f.Longitude,
+			&f.CreatedAt,
+		)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error getting flags for hunt %d: %v",
+				huntID,
+				err,
+			)
+			break
+		}
+
+		flags = append(flags, &f)
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error getting flags for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+
+	return flags, e.GetError()
+}
+
+var submissionFlagDeleteScript = `
+	DELETE FROM submission_flags f
+	USING teams t
+	WHERE f.id = $1 AND f.team_id = t.id AND t.hunt_id = $2
+	RETURNING f.team_id;
+	`
+
+// DeleteSubmissionFlag dismisses the flag with the given id AND huntID and
+// returns the id of the team whose submission was flagged
+func DeleteSubmissionFlag(flagID, huntID int) (int, *response.Error) {
+	var teamID int
+	err := stmtMap["submissionFlagDelete"].QueryRow(flagID, huntID).Scan(&teamID)
+	if err == sql.ErrNoRows {
+		return 0, response.NewErrorf(
+			http.StatusBadRequest,
+			"flag_id: there is no flag with id %d in hunt %d",
+			flagID,
+			huntID,
+		)
+	}
+	if err != nil {
+		return 0, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error deleting flag %d: %v",
+			flagID,
+			err,
+		)
+	}
+
+	return teamID, nil
+}
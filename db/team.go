@@ -373,6 +373,85 @@ func TeamRemovePlayer(teamID int, playerID int) *response.Error {
 	return nil
 }
 
+var teamJoinCodeScript = `
+	UPDATE teams
+	SET join_code = COALESCE(join_code, $2)
+	WHERE id = $1
+	RETURNING join_code;`
+
+// TeamJoinCode returns the join code for the team with the given id. If
+// the team does not have a join code yet then it is given newCode.
+func TeamJoinCode(teamID int, newCode string) (string, *response.Error) {
+	var code string
+	err := stmtMap["teamJoinCode"].QueryRow(teamID, newCode).Scan(&code)
+	if err == sql.ErrNoRows {
+		return "", response.NewErrorf(
+			http.StatusBadRequest,
+			"team_id: no team with id %d",
+			teamID,
+		)
+	}
+	if err != nil {
+		team := TeamDB{ID: teamID}
+		return "", team.ParseError(err, "join code")
+	}
+
+	return code, nil
+}
+
+var teamByJoinCodeScript = `
+	SELECT hunt_id, name, id
+	FROM teams
+	WHERE join_code = $1;`
+
+// GetTeamByJoinCode returns the team with the given join code
+func GetTeamByJoinCode(code string) (*TeamDB, *response.Error) {
+	team := TeamDB{}
+	err := stmtMap["teamByJoinCode"].QueryRow(code).Scan(&team.HuntID, &team.Name, &team.ID)
+	if err == sql.ErrNoRows {
+		return nil, response.NewError(
+			http.StatusBadRequest,
+			"code: invalid team code",
+		)
+	}
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting team by join code: %v",
+			err,
+		)
+	}
+
+	return &team, nil
+}
+
+var teamForPlayerScript = `
+	SELECT COALESCE((
+		SELECT ut.team_id
+		FROM users_teams ut
+		INNER JOIN teams t
+			ON t.id = ut.team_id AND t.hunt_id = $1
+		WHERE ut.user_id = $2
+	), 0);`
+
+// TeamIDForPlayer returns the id of the team the user is on in the given
+// hunt or 0 if the user is not on a team
+func TeamIDForPlayer(huntID, userID int) (int, *response.Error) {
+	var teamID int
+	err := stmtMap["teamForPlayer"].QueryRow(huntID, userID).Scan(&teamID)
+	if err != nil {
+		return 0, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting team for user %d in hunt %d: %v",
+			userID,
+			huntID,
+			err,
+		)
+	}
+
+	return teamID, nil
+}
+
 // ParseError maps a pq driver error to a response.Error that contains the information a
 // client needs to know.
 func (t *TeamDB) ParseError(err error, op string) *response.Error {
@@ -403,6 +482,28 @@ func (t *TeamDB) ParseError(err error, op string) *response.Error {
 					http.StatusBadRequest,
 					"user_id: user being added does not exist",
 				)
+			case "team_join_code_unique":
+				return response.NewError(
+					http.StatusBadRequest,
+					"code: team code is already in use",
+				)
+			case "one_team_per_hunt":
+				return response.NewError(
+					http.StatusBadRequest,
+					"user_id: a player can only be on one team per hunt",
+				)
+			case "hunt_max_teams":
+				return response.NewErrorf(
+					http.StatusBadRequest,
+					"hunt_id: hunt %d has reached its maximum number of teams",
+					t.HuntID,
+				)
+			case "team_at_capacity":
+				return response.NewErrorf(
+					http.StatusBadRequest,
+					"team_id: team %d has reached its maximum number of players",
+					t.ID,
+				)
 			}
 		}
 	}
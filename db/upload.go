@@ -0,0 +1,239 @@
+package db
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/lib/pq"
+)
+
+// UploadDB is a representation of a row in the uploads table. An upload is
+// a slot a team uploads a media file straight to storage with.
+//
+// swagger:model Upload
+type UploadDB struct {
+
+	// The id of the upload
+	//
+	// required: false
+	ID int `json:"uploadID" valid:"int,optional"`
+
+	// The id of the team the upload is for
+	//
+	// required: true
+	TeamID int `json:"teamID" valid:"int"`
+
+	// The id of the user that requested the upload
+	//
+	// required: true
+	UserID int `json:"userID" valid:"int"`
+
+	// The storage key the file is uploaded to
+	//
+	// required: true
+	Key string `json:"key" valid:"-"`
+
+	// The content type the file has to be uploaded with
+	//
+	// required: true
+	ContentType string `json:"contentType" valid:"-"`
+
+	// The size, in bytes, of the file
+	//
+	// required: true
+	Size int64 `json:"size" valid:"-"`
+
+	// When the upload url stops working
+	//
+	// required: true
+	// swagger:strfmt date
+	ExpiresAt time.Time `json:"expiresAt" valid:"-"`
+
+	// When the upload was confirmed
+	//
+	// required: false
+	// swagger:strfmt date
+	ConfirmedAt time.Time `json:"confirmedAt,omitempty" valid:"-"`
+}
+
+// Validate is a dummy function because UploadDB model is server
+// generated and not meant to be posted by clients
+func (u *UploadDB) Validate(r *http.Request) *response.Error {
+	return nil
+}
+
+var uploadInsertScript = `
+	INSERT INTO uploads(team_id, user_id, key, content_type, size, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id;
+	`
+
+// Insert stores the upload
+func (u *UploadDB) Insert() *response.Error {
+	err := stmtMap["uploadInsert"].QueryRow(
+		u.TeamID,
+		u.UserID,
+		u.Key,
+		u.ContentType,
+		u.Size,
+		u.ExpiresAt,
+	).Scan(&u.ID)
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error inserting upload for team %d: %v",
+			u.TeamID,
+			err,
+		)
+	}
+
+	return nil
+}
+
+var uploadSelectScript = `
+	SELECT id, team_id, user_id, key, content_type, size, expires_at,
+		confirmed_at
+	FROM uploads
+	WHERE id = $1 AND team_id = $2;
+	`
+
+// scanUpload scans a row of the columns selected by uploadSelectScript
+func scanUpload(row *sql.Row) (*UploadDB, error) {
+	u := UploadDB{}
+	var confirmedAt pq.NullTime
+
+	err := row.Scan(
+		&u.ID,
+		&u.TeamID,
+		&u.UserID,
+		&u.Key,
+		&u.ContentType,
+		&u.Size,
+		&u.ExpiresAt,
+		&confirmedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if confirmedAt.Valid {
+		u.ConfirmedAt = confirmedAt.Time
+	}
+
+	return &u, nil
+}
+
+// GetUpload returns the upload with the given id AND teamID
+func GetUpload(uploadID, teamID int) (*UploadDB, *response.Error) {
+	u, err := scanUpload(stmtMap["uploadSelect"].QueryRow(uploadID, teamID))
+	if err == sql.ErrNoRows {
+		return nil, response.NewErrorf(
+			http.StatusNotFound,
+			"upload: team %d does not have upload %d",
+			teamID,
+			uploadID,
+		)
+	}
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting upload %d: %v",
+			uploadID,
+			err,
+		)
+	}
+
+	return u, nil
+}
+
+var uploadSelectByKeyScript = `
+	SELECT id, team_id, user_id, key, content_type, size, expires_at,
+		confirmed_at
+	FROM uploads
+	WHERE key = $1;
+	`
+
+// GetUploadByKey returns the upload whose file is uploaded to the given
+// storage key
+func GetUploadByKey(key string) (*UploadDB, *response.Error) {
+	u, err := scanUpload(stmtMap["uploadSelectByKey"].QueryRow(key))
+	if err == sql.ErrNoRows {
+		return nil, response.NewErrorf(
+			http.StatusNotFound,
+			"key: there is no upload for %s",
+			key,
+		)
+	}
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting the upload for %s: %v",
+			key,
+			err,
+		)
+	}
+
+	return u, nil
+}
+
+var uploadConfirmScript = `
+	UPDATE uploads
+	SET confirmed_at = NOW()
+	WHERE id = $1 AND confirmed_at IS NULL
+	RETURNING confirmed_at;
+	`
+
+// Confirm marks the upload as confirmed. An upload can only be confirmed
+// once.
+func (u *UploadDB) Confirm() *response.Error {
+	err := stmtMap["uploadConfirm"].QueryRow(u.ID).Scan(&u.ConfirmedAt)
+	if err == sql.ErrNoRows {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"upload: upload %d has already been confirmed",
+			u.ID,
+		)
+	}
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error confirming upload %d: %v",
+			u.ID,
+			err,
+		)
+	}
+
+	return nil
+}
+
+var uploadsDeleteExpiredScript = `
+	DELETE FROM uploads
+	WHERE confirmed_at IS NULL AND expires_at <= $1;
+	`
+
+// DeleteExpiredUploads removes the unconfirmed uploads that expired before
+// the given time and returns how many were removed. Their blobs are queued
+// in the blob outbox.
+func DeleteExpiredUploads(expiredBefore time.Time) (int64, *response.Error) {
+	res, err := stmtMap["uploadsDeleteExpired"].Exec(expiredBefore)
+	if err != nil {
+		return 0, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error deleting expired uploads: %v",
+			err,
+		)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error deleting expired uploads: %v",
+			err,
+		)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,163 @@
+package db
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// UserMessageDB is a representation of a row in the user_messages table
+//
+// swagger:model UserMessage
+type UserMessageDB struct {
+
+	// The id of the message
+	//
+	// required: false
+	ID int `json:"messageID" valid:"int,optional"`
+
+	// The id of the user the message is for
+	//
+	// required: false
+	UserID int `json:"userID" valid:"int,optional"`
+
+	// The id of the hunt the message is about, if any
+	//
+	// required: false
+	HuntID int `json:"huntID,omitempty" valid:"int,optional"`
+
+	// The message
+	//
+	// required: false
+	Body string `json:"body" valid:"-"`
+
+	// The time the message was sent
+	//
+	// required: false
+	// swagger:strfmt date
+	CreatedAt time.Time `json:"createdAt" valid:"-"`
+}
+
+// Validate is a dummy function because UserMessageDB model is server
+// generated and not meant to be posted by clients
+func (m *UserMessageDB) Validate(r *http.Request) *response.Error {
+	return nil
+}
+
+var userMessageInsertScript = `
+	INSERT INTO user_messages(user_id, hunt_id, body)
+	VALUES ($1, NULLIF($2, 0), $3)
+	RETURNING id, created_at;
+	`
+
+// Insert sends the message to the user
+func (m *UserMessageDB) Insert() *response.Error {
+	err := stmtMap["userMessageInsert"].QueryRow(
+		m.UserID,
+		m.HuntID,
+		m.Body,
+	).Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error sending message to user %d: %v",
+			m.UserID,
+			err,
+		)
+	}
+
+	return nil
+}
+
+var userMessagesForUserScript = `
+	SELECT id, user_id, hunt_id, body, created_at
+	FROM user_messages
+	WHERE user_id = $1
+	ORDER BY created_at DESC, id DESC;
+	`
+
+// GetMessagesForUser returns the messages for the given user, newest first.
+// It is possible to return both results and an error
+func GetMessagesForUser(userID int) ([]*UserMessageDB, *response.Error) {
+	rows, err := stmtMap["userMessagesForUser"].Query(userID)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting messages for user %d: %v",
+			userID,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	messages := make([]*UserMessageDB, 0)
+	for rows.Next() {
+		m := UserMessageDB{}
+		var huntID sql.NullInt64
+		err = rows.Scan(&m.ID, &m.UserID, &huntID, &m.Body, &m.CreatedAt)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error getting message for user %d: %v",
+				userID,
+				err,
+			)
+			break
+		}
+		m.HuntID = int(huntID.Int64)
+		messages = append(messages, &m)
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error getting message for user %d: %v",
+			userID,
+			err,
+		)
+	}
+
+	return messages, e.GetError()
+}
+
+var userMessageDeleteScript = `
+	DELETE FROM user_messages
+	WHERE id = $1 AND user_id = $2;
+	`
+
+// DeleteUserMessage deletes the message with the given id AND userID
+func DeleteUserMessage(id, userID int) *response.Error {
+	res, err := stmtMap["userMessageDelete"].Exec(id, userID)
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error deleting message %d: %v",
+			id,
+			err,
+		)
+	}
+
+	numRows, err := res.RowsAffected()
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error deleting message %d: %v",
+			id,
+			err,
+		)
+	}
+
+	if numRows < 1 {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"there is no message with id %d for user %d",
+			id,
+			userID,
+		)
+	}
+
+	return nil
+}
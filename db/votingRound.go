@@ -0,0 +1,461 @@
+package db
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/lib/pq"
+)
+
+// the defaults used when a voting round is set up without them
+const (
+	DefaultVotingWinners  = 3
+	DefaultVotesPerPlayer = 3
+)
+
+// VotingRoundDB is a representation of a row in the voting_rounds table. A
+// voting round lets the players of a hunt vote for the best photos once the
+// hunt has ended.
+//
+// swagger:model VotingRound
+type VotingRoundDB struct {
+
+	// The id of the hunt the voting round is for
+	//
+	// required: false
+	HuntID int `json:"huntID" valid:"int,optional"`
+
+	// The time voting closes
+	//
+	// required: true
+	// swagger:strfmt date
+	EndsAt time.Time `json:"endsAt" valid:"-"`
+
+	// The number of most voted media whose teams are awarded the bonus.
+	// Media tied with the last winner win too.
+	//
+	// minimum: 1
+	// required: false
+	Winners int `json:"winners" valid:"-"`
+
+	// The points awarded to the team of each winning media
+	//
+	// minimum: 1
+	// required: true
+	BonusPoints int `json:"bonusPoints" valid:"-"`
+
+	// The number of media each player can vote for
+	//
+	// minimum: 1
+	// required: false
+	VotesPerPlayer int `json:"votesPerPlayer" valid:"-"`
+
+	// The time the votes were counted and the bonuses awarded, the zero
+	// time if they have not been yet
+	//
+	// required: false
+	// swagger:strfmt date
+	FinalizedAt time.Time `json:"finalizedAt,omitempty" valid:"-"`
+}
+
+// Validate validates the voting round. Winners and votes per player that
+// are not given are set to their defaults.
+func (v *VotingRoundDB) Validate(r *http.Request) *response.Error {
+	e := response.NewNilError()
+
+	if v.EndsAt.IsZero() {
+		e.Add(http.StatusBadRequest, "endsAt: the time voting closes is required")
+	}
+
+	if v.Winners < 0 || v.Winners > 100 {
+		e.Add(http.StatusBadRequest, "winners: must be between 1 and 100")
+	}
+	if v.Winners == 0 {
+		v.Winners = DefaultVotingWinners
+	}
+
+	if v.BonusPoints < 1 {
+		e.Add(http.StatusBadRequest, "bonusPoints: must be a positive number")
+	}
+
+	if v.VotesPerPlayer < 0 || v.VotesPerPlayer > 100 {
+		e.Add(http.StatusBadRequest, "votesPerPlayer: must be between 1 and 100")
+	}
+	if v.VotesPerPlayer == 0 {
+		v.VotesPerPlayer = DefaultVotesPerPlayer
+	}
+
+	return e.GetError()
+}
+
+// Finalized returns whether or not the votes have been counted
+func (v *VotingRoundDB) Finalized() bool {
+	return !v.FinalizedAt.IsZero()
+}
+
+var votingRoundUpsertScript = `
+	INSERT INTO voting_rounds(hunt_id, ends_at, winners, bonus_points,
+		votes_per_player)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (hunt_id) DO UPDATE
+	SET ends_at = EXCLUDED.ends_at,
+		winners = EXCLUDED.winners,
+		bonus_points = EXCLUDED.bonus_points,
+		votes_per_player = EXCLUDED.votes_per_player
+	WHERE voting_rounds.finalized_at IS NULL
+	RETURNING hunt_id;
+	`
+
+// Upsert sets up the hunt's voting round or changes it if it has not been
+// finalized
+func (v *VotingRoundDB) Upsert() *response.Error {
+	err := stmtMap["votingRoundUpsert"].QueryRow(
+		v.HuntID,
+		v.EndsAt,
+		v.Winners,
+		v.BonusPoints,
+		v.VotesPerPlayer,
+	).Scan(&v.HuntID)
+	if err == sql.ErrNoRows {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"voting: the voting round for hunt %d has been finalized",
+			v.HuntID,
+		)
+	}
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if ok && pqErr.Constraint == "voting_rounds_hunt_id_fkey" {
+			return response.NewErrorf(
+				http.StatusBadRequest,
+				"hunt_id: hunt %d does not exist",
+				v.HuntID,
+			)
+		}
+
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error setting up voting for hunt %d: %v",
+			v.HuntID,
+			err,
+		)
+	}
+
+	return nil
+}
+
+var votingRoundSelectScript = `
+	SELECT hunt_id, ends_at, winners, bonus_points, votes_per_player,
+		finalized_at
+	FROM voting_rounds
+	WHERE hunt_id = $1;
+	`
+
+// GetVotingRound returns the voting round of the given hunt
+func GetVotingRound(huntID int) (*VotingRoundDB, *response.Error) {
+	v := VotingRoundDB{}
+	var finalizedAt pq.NullTime
+	err := stmtMap["votingRoundSelect"].QueryRow(huntID).Scan(
+		&v.HuntID,
+		&v.EndsAt,
+		&v.Winners,
+		&v.BonusPoints,
+		&v.VotesPerPlayer,
+		&finalizedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, response.NewErrorf(
+			http.StatusBadRequest,
+			"voting: hunt %d does not have a voting round",
+			huntID,
+		)
+	}
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting the voting round for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+
+	if finalizedAt.Valid {
+		v.FinalizedAt = finalizedAt.Time
+	}
+
+	return &v, nil
+}
+
+var mediaVoteInsertScript = `
+	SELECT ins_media_vote($1, $2, $3);
+	`
+
+// InsertMediaVote records the user's vote for the media in the hunt's voting
+// round. A user can not vote for the same media twice or use more votes
+// than the round allows, even with concurrent requests.
+func InsertMediaVote(huntID, mediaID, userID int) *response.Error {
+	var counted bool
+	err := stmtMap["mediaVoteInsert"].QueryRow(huntID, mediaID, userID).Scan(&counted)
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if ok && pqErr.Constraint == "user_votes_media_once" {
+			return response.NewErrorf(
+				http.StatusBadRequest,
+				"media_id: user %d has already voted for media %d",
+				userID,
+				mediaID,
+			)
+		}
+
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error voting for media %d: %v",
+			mediaID,
+			err,
+		)
+	}
+
+	if !counted {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"votes: user %d has no votes left in hunt %d",
+			userID,
+			huntID,
+		)
+	}
+
+	return nil
+}
+
+var mediaVoteDeleteScript = `
+	DELETE FROM media_votes mv
+	USING voting_rounds v
+	WHERE mv.media_id = $1 AND mv.user_id = $2 AND v.hunt_id = mv.hunt_id
+		AND v.finalized_at IS NULL;
+	`
+
+// DeleteMediaVote takes back the user's vote for the media, as long as the
+// voting round has not been finalized
+func DeleteMediaVote(mediaID, userID int) *response.Error {
+	res, err := stmtMap["mediaVoteDelete"].Exec(mediaID, userID)
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error deleting vote for media %d: %v",
+			mediaID,
+			err,
+		)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error deleting vote for media %d: %v",
+			mediaID,
+			err,
+		)
+	}
+
+	if n < 1 {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"media_id: user %d has no vote for media %d that can be taken back",
+			userID,
+			mediaID,
+		)
+	}
+
+	return nil
+}
+
+var mediaVotesByUserScript = `
+	SELECT media_id
+	FROM media_votes
+	WHERE hunt_id = $1 AND user_id = $2
+	ORDER BY created_at ASC;
+	`
+
+// GetMediaVotesByUser returns the ids of the media the user voted for in the
+// hunt's voting round
+func GetMediaVotesByUser(huntID, userID int) ([]int, *response.Error) {
+	rows, err := stmtMap["mediaVotesByUser"].Query(huntID, userID)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting votes for user %d: %v",
+			userID,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		err = rows.Scan(&id)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error getting votes for user %d: %v",
+				userID,
+				err,
+			)
+			break
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error getting votes for user %d: %v",
+			userID,
+			err,
+		)
+	}
+
+	return ids, e.GetError()
+}
+
+// VoteTallyDB is the number of votes a media file got in a voting round
+type VoteTallyDB struct {
+	// the id of the media
+	MediaID int `json:"mediaID"`
+
+	// the id of the team that uploaded the media
+	TeamID int `json:"teamID"`
+
+	// the number of votes the media got
+	Votes int `json:"votes"`
+}
+
+var mediaVoteTalliesScript = `
+	SELECT mv.media_id, m.team_id, COUNT(*)
+	FROM media_votes mv
+	INNER JOIN media m ON m.id = mv.media_id
+	WHERE mv.hunt_id = $1
+	GROUP BY mv.media_id, m.team_id
+	ORDER BY COUNT(*) DESC, mv.media_id ASC;
+	`
+
+// GetVoteTallies returns the votes each media got in the hunt's voting
+// round, most votes first. Media without votes are left out.
+func GetVoteTallies(huntID int) ([]*VoteTallyDB, *response.Error) {
+	rows, err := stmtMap["mediaVoteTallies"].Query(huntID)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error counting votes for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	tallies := make([]*VoteTallyDB, 0)
+	for rows.Next() {
+		t := VoteTallyDB{}
+		err = rows.Scan(&t.MediaID, &t.TeamID, &t.Votes)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error counting votes for hunt %d: %v",
+				huntID,
+				err,
+			)
+			break
+		}
+
+		tallies = append(tallies, &t)
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error counting votes for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+
+	return tallies, e.GetError()
+}
+
+var votingRoundFinalizeScript = `
+	UPDATE voting_rounds
+	SET finalized_at = NOW()
+	WHERE hunt_id = $1 AND finalized_at IS NULL
+	RETURNING finalized_at;
+	`
+
+// FinalizeVotingRound marks the hunt's voting round as finalized and makes
+// the given adjustments to the teams' points, all in one transaction
+func FinalizeVotingRound(huntID int, adjustments []*ScoreAdjustmentDB) (time.Time, *response.Error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return time.Time{}, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error beginning a transaction: %v",
+			err,
+		)
+	}
+
+	rollback := func(e *response.Error) (time.Time, *response.Error) {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return time.Time{}, response.NewErrorf(
+				http.StatusInternalServerError,
+				"error rolling back tx: %v",
+				rollbackErr,
+			)
+		}
+
+		return time.Time{}, e
+	}
+
+	var finalizedAt time.Time
+	err = tx.Stmt(stmtMap["votingRoundFinalize"]).QueryRow(huntID).Scan(&finalizedAt)
+	if err == sql.ErrNoRows {
+		return rollback(response.NewErrorf(
+			http.StatusBadRequest,
+			"voting: the voting round for hunt %d has been finalized",
+			huntID,
+		))
+	}
+	if err != nil {
+		return rollback(response.NewErrorf(
+			http.StatusInternalServerError,
+			"error finalizing the voting round for hunt %d: %v",
+			huntID,
+			err,
+		))
+	}
+
+	insStmt := tx.Stmt(stmtMap["scoreAdjustmentInsert"])
+	for _, a := range adjustments {
+		err = insStmt.QueryRow(a.TeamID, a.Points, a.Reason, a.MediaID).Scan(&a.ID, &a.CreatedAt)
+		if err != nil {
+			return rollback(response.NewErrorf(
+				http.StatusInternalServerError,
+				"error adjusting the points of team %d: %v",
+				a.TeamID,
+				err,
+			))
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return time.Time{}, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error committing transaction for voting round: %v",
+			err,
+		)
+	}
+
+	return finalizedAt, nil
+}
@@ -0,0 +1,92 @@
+// +build unit
+
+package db_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+)
+
+func TestIsEmoji(t *testing.T) {
+	cases := []struct {
+		emoji    string
+		expected bool
+	}{
+		{"👍", true},
+		{"❤️", true},
+		{"👍🏽", true},
+		{"👨‍👩‍👧", true},
+		{"🇺🇸", true},
+		{"", false},
+		{"a", false},
+		{"lol", false},
+		{"👍 ", false},
+		{"👍a", false},
+		{strings.Repeat("👍", 9), false},
+	}
+
+	for _, c := range cases {
+		if got := db.IsEmoji(c.emoji); got != c.expected {
+			t.Errorf("expected IsEmoji(%q) to be %v got %v", c.emoji, c.expected, got)
+		}
+	}
+}
+
+func TestMediaCommentValidate(t *testing.T) {
+	c := db.MediaCommentDB{Body: "  nice shot  "}
+	if e := c.Validate(r); e != nil {
+		t.Fatalf("expected no error got %s", e.JSON())
+	}
+	if c.Body != "nice shot" {
+		t.Errorf("expected the body to be trimmed got %q", c.Body)
+	}
+
+	for _, body := range []string{"", "   ", strings.Repeat("a", 1001)} {
+		c = db.MediaCommentDB{Body: body}
+		if e := c.Validate(r); e == nil {
+			t.Errorf("expected a body of %d characters to be invalid", len(body))
+		}
+	}
+}
+
+func TestVotingRoundValidate(t *testing.T) {
+	endsAt := time.Date(2019, 6, 1, 20, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name  string
+		round db.VotingRoundDB
+		key   string
+	}{
+		{name: "valid", round: db.VotingRoundDB{EndsAt: endsAt, Winners: 1, BonusPoints: 10, VotesPerPlayer: 2}},
+		{name: "defaults", round: db.VotingRoundDB{EndsAt: endsAt, BonusPoints: 10}},
+		{name: "no end", round: db.VotingRoundDB{BonusPoints: 10}, key: "endsAt"},
+		{name: "no bonus", round: db.VotingRoundDB{EndsAt: endsAt}, key: "bonusPoints"},
+		{name: "negative winners", round: db.VotingRoundDB{EndsAt: endsAt, BonusPoints: 10, Winners: -1}, key: "winners"},
+		{name: "too many votes", round: db.VotingRoundDB{EndsAt: endsAt, BonusPoints: 10, VotesPerPlayer: 101}, key: "votesPerPlayer"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e := c.round.Validate(r)
+			if c.key == "" {
+				if e != nil {
+					t.Fatalf("expected no error got %s", e.JSON())
+				}
+				if c.round.Winners < 1 || c.round.VotesPerPlayer < 1 {
+					t.Errorf("expected defaults to be set got %+v", c.round)
+				}
+				return
+			}
+
+			if e == nil {
+				t.Fatalf("expected a %s error got nil", c.key)
+			}
+			if _, ok := e.ErrorsByKey()[c.key]; !ok {
+				t.Errorf("expected a %s error got %s", c.key, e.JSON())
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package db
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/lib/pq"
+)
+
+// WaitlistEntryDB is a representation of a row in the hunt_waitlist table.
+// An entry either waits for a spot to create a team, TeamName, or for a
+// spot on an existing team, TeamID.
+//
+// swagger:model WaitlistEntry
+type WaitlistEntryDB struct {
+
+	// The id of the waitlist entry
+	//
+	// required: false
+	ID int `json:"waitlistID" valid:"int,optional"`
+
+	// The id of the hunt
+	//
+	// required: false
+	HuntID int `json:"huntID" valid:"int,optional"`
+
+	// The id of the waiting user
+	//
+	// required: false
+	UserID int `json:"userID" valid:"int,optional"`
+
+	// The id of the full team the user is waiting to join
+	//
+	// required: false
+	TeamID int `json:"teamID,omitempty" valid:"int,optional"`
+
+	// The name of the team the user is waiting to create
+	//
+	// maximum length: 255
+	// required: false
+	TeamName string `json:"teamName,omitempty" valid:"stringlength(1|255),optional"`
+
+	// A join code for the hunt. Users that are not playing in or invited to
+	// the hunt need one to wait for a spot to create a team. The code is not
+	// stored with the entry.
+	//
+	// required: false
+	Code string `json:"code,omitempty" valid:"stringlength(1|255),optional"`
+
+	// The time the user joined the waitlist
+	//
+	// required: false
+	// swagger:strfmt date
+	CreatedAt time.Time `json:"createdAt" valid:"-"`
+}
+
+// Validate validates the struct
+func (w *WaitlistEntryDB) Validate(r *http.Request) *response.Error {
+	_, err := govalidator.ValidateStruct(w)
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"error validating waitlist entry: %v",
+			err,
+		)
+	}
+
+	if (w.TeamID == 0) == (w.TeamName == "") {
+		return response.NewError(
+			http.StatusBadRequest,
+			"teamID: exactly one of teamID or teamName is required",
+		)
+	}
+
+	return nil
+}
+
+// scan scans a hunt_waitlist row into the struct. The columns are expected
+// in the order used by waitlistEntrySelectScript
+func (w *WaitlistEntryDB) scan(row interface{ Scan(...interface{}) error }) error {
+	var teamID sql.NullInt64
+	var teamName sql.NullString
+
+	err := row.Scan(
+		&w.ID,
+		&w.HuntID,
+		&w.UserID,
+		&teamID,
+		&teamName,
+		&w.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	w.TeamID = int(teamID.Int64)
+	w.TeamName = teamName.String
+
+	return nil
+}
+
+var waitlistEntryInsertScript = `
+	INSERT INTO hunt_waitlist(hunt_id, user_id, team_id, team_name)
+	VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, ''))
+	RETURNING id, created_at;
+	`
+
+// Insert adds the entry to the end of the hunt's waitlist
+func (w *WaitlistEntryDB) Insert() *response.Error {
+	err := stmtMap["waitlistEntryInsert"].QueryRow(
+		w.HuntID,
+		w.UserID,
+		w.TeamID,
+		w.TeamName,
+	).Scan(&w.ID, &w.CreatedAt)
+	if err != nil {
+		return w.ParseError(err, "insert")
+	}
+
+	return nil
+}
+
+var waitlistEntrySelectScript = `
+	SELECT id, hunt_id, user_id, team_id, team_name, created_at
+	FROM hunt_waitlist
+	WHERE id = $1;
+	`
+
+// GetWaitlistEntry returns the waitlist entry with the given id
+func GetWaitlistEntry(id int) (*WaitlistEntryDB, *response.Error) {
+	w := WaitlistEntryDB{}
+	err := w.scan(stmtMap["waitlistEntrySelect"].QueryRow(id))
+	if err == sql.ErrNoRows {
+		return nil, response.NewErrorf(
+			http.StatusBadRequest,
+			"waitlistID: no waitlist entry with id %d",
+			id,
+		)
+	}
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting waitlist entry %d: %v",
+			id,
+			err,
+		)
+	}
+
+	return &w, nil
+}
+
+var waitlistForHuntScript = `
+	SELECT id, hunt_id, user_id, team_id, team_name, created_at
+	FROM hunt_waitlist
+	WHERE hunt_id = $1
+	ORDER BY created_at, id;
+	`
+
+// GetWaitlistForHunt returns the waitlist for the given hunt, oldest entry
+// first. It is possible to return both results and an error
+func GetWaitlistForHunt(huntID int) ([]*WaitlistEntryDB, *response.Error) {
+	rows, err := stmtMap["waitlistForHunt"].Query(huntID)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting waitlist for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	entries := make([]*WaitlistEntryDB, 0)
+	for rows.Next() {
+		w := WaitlistEntryDB{}
+		err = w.scan(rows)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error getting waitlist entry for hunt %d: %v",
+				huntID,
+				err,
+			)
+			break
+		}
+		entries = append(entries, &w)
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error getting waitlist entry for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+
+	return entries, e.GetError()
+}
+
+var waitlistEntryDeleteScript = `
+	DELETE FROM hunt_waitlist
+	WHERE id = $1 AND hunt_id = $2;
+	`
+
+// DeleteWaitlistEntry deletes the waitlist entry with the given id AND huntID
+func DeleteWaitlistEntry(id, huntID int) *response.Error {
+	res, err := stmtMap["waitlistEntryDelete"].Exec(id, huntID)
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error deleting waitlist entry %d: %v",
+			id,
+			err,
+		)
+	}
+
+	numRows, err := res.RowsAffected()
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error deleting waitlist entry %d: %v",
+			id,
+			err,
+		)
+	}
+
+	if numRows < 1 {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"there is no waitlist entry with id %d and hunt id %d",
+			id,
+			huntID,
+		)
+	}
+
+	return nil
+}
+
+// A WaitlistPromotionDB is a waitlist entry that was given a spot. TeamID is
+// the team that was created for, or joined by, the user. A TeamID of 0 with a
+// TeamName means the name was taken while the user waited.
+type WaitlistPromotionDB struct {
+	EntryID  int
+	UserID   int
+	TeamID   int
+	TeamName string
+}
+
+var waitlistPromoteScript = `
+	SELECT _entry_id, _user_id, _team_id, COALESCE(_team_name, '')
+	FROM promote_waitlist($1);
+	`
+
+// PromoteWaitlist fills any open team or player spots in the given hunt
+// from its waitlist. The promotion happens in a single statement so the
+// spots and entries are updated together.
+func PromoteWaitlist(huntID int) ([]*WaitlistPromotionDB, *response.Error) {
+	rows, err := stmtMap["waitlistPromote"].Query(huntID)
+	if err != nil {
+		team := TeamDB{HuntID: huntID}
+		return nil, team.ParseError(err, "promote waitlist")
+	}
+	defer rows.Close()
+
+	e := response.NewNilError()
+	promotions := make([]*WaitlistPromotionDB, 0)
+	for rows.Next() {
+		p := WaitlistPromotionDB{}
+		err = rows.Scan(&p.EntryID, &p.UserID, &p.TeamID, &p.TeamName)
+		if err != nil {
+			e.Addf(
+				http.StatusInternalServerError,
+				"error promoting waitlist for hunt %d: %v",
+				huntID,
+				err,
+			)
+			break
+		}
+		promotions = append(promotions, &p)
+	}
+
+	if err = rows.Err(); err != nil {
+		e.Addf(
+			http.StatusInternalServerError,
+			"error promoting waitlist for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+
+	return promotions, e.GetError()
+}
+
+// ParseError maps a pq driver error to a response.Error
+func (w *WaitlistEntryDB) ParseError(err error, op string) *response.Error {
+	pqErr, ok := err.(*pq.Error)
+	if ok {
+		if pqErr.Constraint != "" {
+			switch pqErr.Constraint {
+			case "user_waitlisted_once":
+				return response.NewErrorf(
+					http.StatusBadRequest,
+					"userID: user %d is already on the waitlist for hunt %d",
+					w.UserID,
+					w.HuntID,
+				)
+			case "waitlist_team_or_name":
+				return response.NewError(
+					http.StatusBadRequest,
+					"teamID: exactly one of teamID or teamName is required",
+				)
+			case "hunt_waitlist_hunt_id_fkey":
+				return response.NewErrorf(
+					http.StatusBadRequest,
+					"huntID: hunt %d does not exist",
+					w.HuntID,
+				)
+			case "hunt_waitlist_team_id_fkey":
+				return response.NewErrorf(
+					http.StatusBadRequest,
+					"teamID: team %d does not exist",
+					w.TeamID,
+				)
+			}
+		}
+	}
+
+	return response.NewErrorf(
+		http.StatusInternalServerError,
+		"WaitlistEntry: db error on hunt_waitlist operation %s: %v",
+		op,
+		err,
+	)
+}
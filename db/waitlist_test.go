@@ -0,0 +1,53 @@
+// +build unit
+
+package db_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cljohnson4343/scavenge/db"
+)
+
+func TestValidateWaitlistEntryDB(t *testing.T) {
+	cases := []struct {
+		name  string
+		entry db.WaitlistEntryDB
+		valid bool
+	}{
+		{
+			name:  "team name",
+			entry: db.WaitlistEntryDB{TeamName: "Chris Johnson"},
+			valid: true,
+		},
+		{
+			name:  "team id",
+			entry: db.WaitlistEntryDB{TeamID: 43},
+			valid: true,
+		},
+		{
+			name:  "neither",
+			entry: db.WaitlistEntryDB{},
+		},
+		{
+			name:  "both",
+			entry: db.WaitlistEntryDB{TeamID: 43, TeamName: "Chris Johnson"},
+		},
+		{
+			name:  "team name too long",
+			entry: db.WaitlistEntryDB{TeamName: strings.Repeat("a", 256)},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.entry.Validate(r)
+			if c.valid && err != nil {
+				t.Errorf("expected nil but got %s", err.JSON())
+			}
+			if !c.valid && err == nil {
+				t.Errorf("expected an error but got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package events
+
+import (
+	"sync"
+
+	"github.com/cljohnson4343/scavenge/db"
+)
+
+// Subscription receives the events published in a hunt
+type Subscription struct {
+	// C receives the events. It is closed if the subscriber falls too far
+	// behind, after which it should catch up from the stored events.
+	C <-chan *db.HuntEventDB
+
+	broker *Broker
+	huntID int
+	ch     chan *db.HuntEventDB
+}
+
+// Close stops the subscription
+func (s *Subscription) Close() {
+	s.broker.remove(s.huntID, s.ch)
+}
+
+// Broker fans out events to the subscribers of their hunt
+type Broker struct {
+	mu     sync.Mutex
+	subs   map[int]map[chan *db.HuntEventDB]bool
+	buffer int
+}
+
+// NewBroker returns a Broker whose subscribers can fall up to buffer events
+// behind before they are dropped
+func NewBroker(buffer int) *Broker {
+	return &Broker{
+		subs:   make(map[int]map[chan *db.HuntEventDB]bool),
+		buffer: buffer,
+	}
+}
+
+// Subscribe returns a subscription to the events published in the hunt
+func (b *Broker) Subscribe(huntID int) *Subscription {
+	ch := make(chan *db.HuntEventDB, b.buffer)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subs[huntID] == nil {
+		b.subs[huntID] = make(map[chan *db.HuntEventDB]bool)
+	}
+	b.subs[huntID][ch] = true
+
+	return &Subscription{C: ch, broker: b, huntID: huntID, ch: ch}
+}
+
+// Broadcast sends the event to the subscribers of its hunt without waiting
+// on any of them. Subscribers whose buffer is full are dropped.
+func (b *Broker) Broadcast(event *db.HuntEventDB) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs[event.HuntID] {
+		select {
+		case ch <- event:
+		default:
+			b.removeLocked(event.HuntID, ch)
+		}
+	}
+}
+
+// Subscribers returns the number of subscribers to the hunt's events
+func (b *Broker) Subscribers(huntID int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.subs[huntID])
+}
+
+// remove drops the subscriber, if it has not been dropped already
+func (b *Broker) remove(huntID int, ch chan *db.HuntEventDB) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.removeLocked(huntID, ch)
+}
+
+// removeLocked drops the subscriber. The lock must be held.
+func (b *Broker) removeLocked(huntID int, ch chan *db.HuntEventDB) {
+	if !b.subs[huntID][ch] {
+		return
+	}
+
+	delete(b.subs[huntID], ch)
+	if len(b.subs[huntID]) == 0 {
+		delete(b.subs, huntID)
+	}
+	close(ch)
+}
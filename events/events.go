@@ -0,0 +1,199 @@
+// Package events publishes what happens in a hunt, like submissions, score
+// changes, and announcements, to the hunt's members as it happens. Events
+// are stored so clients that reconnect can be sent the events they missed,
+// and are fanned out to the streams open on this server by a Broker.
+package events
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// the types of hunt events
+const (
+	// a team uploaded media or claimed an item
+	Submission = "submission"
+
+	// a flagged submission was reviewed and approved by the hunt's owner
+	Approval = "approval"
+
+	// a team's points changed
+	Score = "score"
+
+	// a team reported its location
+	Location = "location"
+
+	// a team was created, changed, or deleted, or a player joined or left it
+	Team = "team"
+
+	// the hunt's owner sent a message to everyone in the hunt
+	Announcement = "announcement"
+
+	// a team left or returned to the hunt's boundary
+	Boundary = "boundary"
+)
+
+// teamOnly are the types of events that only the team they are about and
+// the hunt's owners receive, so teams can not follow each other around
+var teamOnly = map[string]bool{
+	Submission: true,
+	Approval:   true,
+	Location:   true,
+	Boundary:   true,
+}
+
+// Visible returns whether or not the event is sent to a member of the hunt
+// on the given team, 0 if the member is not on one
+func Visible(event *db.HuntEventDB, teamID int, isOwner bool) bool {
+	if isOwner || event.TeamID == 0 || !teamOnly[event.Type] {
+		return true
+	}
+
+	return event.TeamID == teamID
+}
+
+// broker fans out the events published on this server
+var broker = NewBroker(64)
+
+// Subscribe returns a subscription to the events published in the hunt on
+// this server from now on
+func Subscribe(huntID int) *Subscription {
+	return broker.Subscribe(huntID)
+}
+
+// Send stores an event of the given type about the team, 0 for events about
+// the whole hunt, and sends it to the hunt's open streams
+func Send(huntID, teamID int, eventType string, data interface{}) (*db.HuntEventDB, *response.Error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error encoding %s event for hunt %d: %v",
+			eventType,
+			huntID,
+			err,
+		)
+	}
+
+	event := db.HuntEventDB{
+		HuntID: huntID,
+		TeamID: teamID,
+		Type:   eventType,
+		Data:   raw,
+	}
+	e := event.Insert()
+	if e != nil {
+		return nil, e
+	}
+
+	broker.Broadcast(&event)
+	return &event, nil
+}
+
+// Publish sends an event like Send does. Events are published after the
+// change they describe has been made, so errors are logged rather than
+// returned.
+func Publish(huntID, teamID int, eventType string, data interface{}) {
+	_, e := Send(huntID, teamID, eventType, data)
+	if e != nil {
+		log.Printf("error publishing event: %s\n", e.JSON())
+	}
+}
+
+// PublishForTeam publishes an event about the team in the team's hunt
+func PublishForTeam(teamID int, eventType string, data interface{}) {
+	team, e := db.GetTeam(teamID)
+	if e != nil {
+		log.Printf("error publishing %s event for team %d: %s\n", eventType, teamID, e.JSON())
+		return
+	}
+
+	Publish(team.HuntID, teamID, eventType, data)
+}
+
+// ScoreData is the data of a score event
+type ScoreData struct {
+	// the id of the team whose points changed
+	TeamID int `json:"teamID"`
+
+	// the team's points
+	Points int `json:"points"`
+}
+
+// PublishScore publishes the team's points after they changed
+func PublishScore(teamID int) {
+	points, e := db.GetTeamPoints(teamID)
+	if e != nil {
+		log.Printf("error publishing score for team %d: %s\n", teamID, e.JSON())
+		return
+	}
+
+	PublishForTeam(teamID, Score, &ScoreData{TeamID: teamID, Points: points})
+}
+
+// SubmissionData is the data of a submission event
+type SubmissionData struct {
+	// the id of the team that made the submission
+	TeamID int `json:"teamID"`
+
+	// the id of the item the submission was for, if any
+	ItemID int `json:"itemID,omitempty"`
+
+	// the id of the uploaded media, if the submission was an upload
+	MediaID int `json:"mediaID,omitempty"`
+
+	// where the uploaded media can be retrieved, if the submission was an
+	// upload
+	URL string `json:"url,omitempty"`
+
+	// the id of the item claim, if the submission was a claim
+	ClaimID int `json:"claimID,omitempty"`
+}
+
+// PublishSubmission publishes the team's submission followed by the team's
+// points
+func PublishSubmission(data *SubmissionData) {
+	PublishForTeam(data.TeamID, Submission, data)
+	PublishScore(data.TeamID)
+}
+
+// the actions of team events
+const (
+	TeamCreated   = "created"
+	TeamUpdated   = "updated"
+	TeamDeleted   = "deleted"
+	TeamBalanced  = "balanced"
+	PlayerJoined  = "player_joined"
+	PlayerRemoved = "player_removed"
+)
+
+// TeamData is the data of a team event
+type TeamData struct {
+	// the id of the team, 0 for changes to every team
+	TeamID int `json:"teamID,omitempty"`
+
+	// what happened to the team
+	Action string `json:"action"`
+
+	// the id of the player that joined or was removed from the team
+	UserID int `json:"userID,omitempty"`
+}
+
+// the actions of boundary events
+const (
+	BoundaryLeft     = "left"
+	BoundaryReturned = "returned"
+)
+
+// BoundaryData is the data of a boundary event
+type BoundaryData struct {
+	// whether the team left or returned
+	Action string `json:"action"`
+
+	// the alert raised when the team left
+	Alert *db.BoundaryAlertDB `json:"alert"`
+}
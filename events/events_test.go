@@ -0,0 +1,216 @@
+// +build unit
+
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+func newEvent(id int64, teamID int, eventType string) *db.HuntEventDB {
+	return &db.HuntEventDB{
+		ID:     id,
+		HuntID: 1,
+		TeamID: teamID,
+		Type:   eventType,
+		Data:   json.RawMessage(`{}`),
+	}
+}
+
+func TestVisible(t *testing.T) {
+	cases := []struct {
+		name     string
+		event    *db.HuntEventDB
+		teamID   int
+		isOwner  bool
+		expected bool
+	}{
+		{"announcement", newEvent(1, 0, Announcement), 2, false, true},
+		{"other team's score", newEvent(1, 3, Score), 2, false, true},
+		{"own location", newEvent(1, 2, Location), 2, false, true},
+		{"other team's location", newEvent(1, 3, Location), 2, false, false},
+		{"other team's submission", newEvent(1, 3, Submission), 2, false, false},
+		{"location without a team", newEvent(1, 3, Location), 0, false, false},
+		{"owner", newEvent(1, 3, Location), 0, true, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Visible(c.event, c.teamID, c.isOwner); got != c.expected {
+				t.Errorf("expected %v got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestBroker(t *testing.T) {
+	b := NewBroker(1)
+	sub := b.Subscribe(1)
+	other := b.Subscribe(2)
+
+	b.Broadcast(newEvent(1, 0, Announcement))
+	select {
+	case event := <-sub.C:
+		if event.ID != 1 {
+			t.Errorf("expected event 1 got %d", event.ID)
+		}
+	default:
+		t.Fatalf("expected the subscriber to receive the event")
+	}
+
+	select {
+	case <-other.C:
+		t.Errorf("expected subscribers to other hunts to not receive the event")
+	default:
+	}
+
+	// the subscriber falls behind and is dropped
+	b.Broadcast(newEvent(2, 0, Announcement))
+	b.Broadcast(newEvent(3, 0, Announcement))
+	<-sub.C
+	if _, ok := <-sub.C; ok {
+		t.Errorf("expected a subscriber that fell behind to be closed")
+	}
+	if n := b.Subscribers(1); n != 0 {
+		t.Errorf("expected no subscribers got %d", n)
+	}
+
+	// closing twice is safe
+	sub.Close()
+	other.Close()
+	other.Close()
+	if n := b.Subscribers(2); n != 0 {
+		t.Errorf("expected no subscribers got %d", n)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	buf := bytes.Buffer{}
+	event := newEvent(42, 0, Announcement)
+	event.Data = json.RawMessage(`{"message":"pizza at 6"}`)
+
+	if err := Write(&buf, event); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	got := buf.String()
+	if !strings.HasPrefix(got, "id: 42\nevent: announcement\ndata: {") {
+		t.Errorf("expected an id, event, and data line got %q", got)
+	}
+	if !strings.HasSuffix(got, "}\n\n") {
+		t.Errorf("expected the event to end with a blank line got %q", got)
+	}
+	if !strings.Contains(got, `"data":{"message":"pizza at 6"}`) {
+		t.Errorf("expected the event's data got %q", got)
+	}
+}
+
+func TestLastEventID(t *testing.T) {
+	cases := []struct {
+		name     string
+		header   string
+		query    string
+		expected int64
+		err      bool
+	}{
+		{name: "none"},
+		{name: "header", header: "17", expected: 17},
+		{name: "query", query: "?lastEventID=9", expected: 9},
+		{name: "header before query", header: "17", query: "?lastEventID=9", expected: 17},
+		{name: "invalid", header: "abc", err: true},
+		{name: "negative", query: "?lastEventID=-1", err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/hunts/1/events"+c.query, nil)
+			if c.header != "" {
+				r.Header.Set("Last-Event-ID", c.header)
+			}
+
+			id, e := LastEventID(r)
+			if c.err {
+				if e == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if e != nil {
+				t.Fatalf("expected no error got %s", e.JSON())
+			}
+			if id != c.expected {
+				t.Errorf("expected %d got %d", c.expected, id)
+			}
+		})
+	}
+}
+
+func TestStream(t *testing.T) {
+	broker = NewBroker(8)
+	history = func(huntID int, afterID int64, limit int) ([]*db.HuntEventDB, *response.Error) {
+		if afterID != 1 {
+			t.Errorf("expected to replay after event 1 got %d", afterID)
+		}
+		return []*db.HuntEventDB{
+			newEvent(2, 3, Location),
+			newEvent(3, 2, Score),
+		}, nil
+	}
+	defer func() { history = db.GetHuntEventsAfter }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := httptest.NewRequest("GET", "/hunts/1/events", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	visible := func(event *db.HuntEventDB) bool {
+		return Visible(event, 2, false)
+	}
+
+	done := make(chan *response.Error)
+	go func() {
+		done <- Stream(w, r, 1, 1, visible)
+	}()
+
+	for broker.Subscribers(1) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// event 3 was replayed so it is not sent again
+	broker.Broadcast(newEvent(3, 2, Score))
+	broker.Broadcast(newEvent(4, 0, Announcement))
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	if e := <-done; e != nil {
+		t.Fatalf("expected no error got %s", e.JSON())
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected an event stream got %s", ct)
+	}
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200 got %d", w.Code)
+	}
+
+	body := w.Body.String()
+	if strings.Contains(body, "id: 2\n") {
+		t.Errorf("expected another team's location to not be sent got %q", body)
+	}
+	if strings.Count(body, "id: 3\n") != 1 {
+		t.Errorf("expected event 3 to be sent once got %q", body)
+	}
+	if !strings.Contains(body, "id: 4\n") {
+		t.Errorf("expected the live event to be sent got %q", body)
+	}
+	if n := broker.Subscribers(1); n != 0 {
+		t.Errorf("expected the stream to unsubscribe got %d subscribers", n)
+	}
+}
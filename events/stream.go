@@ -0,0 +1,154 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// replayBatch is how many stored events are read at a time when a client
+// catches up
+const replayBatch = 500
+
+// retryMillis is how long clients wait before reconnecting a dropped stream
+const retryMillis = 3000
+
+// keepAliveInterval is how often an idle stream is sent a comment so that
+// proxies do not close it
+var keepAliveInterval = 15 * time.Second
+
+// history returns the hunt's stored events after the event with the given id
+var history = db.GetHuntEventsAfter
+
+// LastEventID returns the id of the last event the client received. Browsers
+// send it in the Last-Event-ID header when they reconnect. Clients starting
+// a new stream can send it in the lastEventID query parameter instead. 0 is
+// returned if neither is given, in which case the client is only sent new
+// events.
+func LastEventID(r *http.Request) (int64, *response.Error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("lastEventID")
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || id < 0 {
+		return 0, response.NewErrorf(
+			http.StatusBadRequest,
+			"lastEventID: %s is not a valid event id",
+			value,
+		)
+	}
+
+	return id, nil
+}
+
+// Write writes the event to w as a server-sent event
+func Write(w io.Writer, event *db.HuntEventDB) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+// Stream sends the hunt's events that pass visible to the client as
+// server-sent events until the client disconnects. The stored events after
+// lastID are sent first, unless lastID is 0. An error is only returned if
+// the stream could not be started.
+func Stream(w http.ResponseWriter, r *http.Request, huntID int, lastID int64, visible func(*db.HuntEventDB) bool) *response.Error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return response.NewError(
+			http.StatusInternalServerError,
+			"events: the connection does not support streaming",
+		)
+	}
+
+	// subscribe before catching up so no event is missed in between
+	sub := broker.Subscribe(huntID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", retryMillis)
+
+	// the events sent while catching up, which may also be received from
+	// the subscription
+	sent := make(map[int64]bool)
+	for lastID > 0 {
+		events, e := history(huntID, lastID, replayBatch)
+		if e != nil {
+			log.Printf("error replaying events for hunt %d: %s\n", huntID, e.JSON())
+			return nil
+		}
+
+		for _, event := range events {
+			lastID = event.ID
+			sent[event.ID] = true
+			if !visible(event) {
+				continue
+			}
+
+			if err := Write(w, event); err != nil {
+				return nil
+			}
+		}
+
+		if len(events) < replayBatch {
+			break
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case event, ok := <-sub.C:
+			// a closed subscription fell behind, so the client reconnects
+			// and catches up from the stored events
+			if !ok {
+				return nil
+			}
+
+			if sent[event.ID] {
+				delete(sent, event.ID)
+				continue
+			}
+
+			if !visible(event) {
+				continue
+			}
+
+			if err := Write(w, event); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,188 @@
+// Package gc deletes the blobs in storage that no longer belong to a row.
+// Deleted rows queue their blobs in the blob outbox, which Drain works
+// through. Reconcile finds the blobs that were left behind anyway, for
+// example by a crash between storing a file and inserting its row.
+package gc
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/cljohnson4343/scavenge/storage"
+)
+
+// drainBatch is how many queued blobs are read from the outbox at a time
+const drainBatch = 100
+
+// uploadGrace is how long after an unconfirmed upload expires its blob is
+// still in use, so an upload that started just before its url expired can
+// finish
+const uploadGrace = time.Hour
+
+// uploadCutoff returns the time unconfirmed uploads that expired before are
+// abandoned. Upload expirations are stored in UTC.
+func uploadCutoff() time.Time {
+	return time.Now().Add(-uploadGrace).UTC()
+}
+
+// ExpireUploads removes the abandoned unconfirmed uploads, which queues
+// their blobs in the blob outbox, and returns how many were removed
+func ExpireUploads() (int64, *response.Error) {
+	return db.DeleteExpiredUploads(uploadCutoff())
+}
+
+// Drain deletes at most limit of the blobs queued in the blob outbox and
+// returns how many were deleted. Blobs that are already gone count as
+// deleted. Blobs that fail to delete stay queued for the next drain.
+func Drain(store storage.Store, limit int) (int, *response.Error) {
+	queued, e := db.GetBlobOutbox(limit)
+	if e != nil {
+		return 0, e
+	}
+
+	deleted := 0
+	errs := response.NewNilError()
+	for _, b := range queued {
+		deleteErr := store.Delete(b.Key)
+		if deleteErr != nil && deleteErr.Code() != http.StatusNotFound {
+			errs.AddError(deleteErr)
+
+			failErr := b.Fail()
+			if failErr != nil {
+				errs.AddError(failErr)
+			}
+			continue
+		}
+
+		rowErr := b.Delete()
+		if rowErr != nil {
+			errs.AddError(rowErr)
+			continue
+		}
+
+		deleted++
+	}
+
+	return deleted, errs.GetError()
+}
+
+// DrainAll drains the blob outbox until it is empty or a batch has
+// failures, and returns how many blobs were deleted
+func DrainAll(store storage.Store) (int, *response.Error) {
+	total := 0
+	for {
+		deleted, e := Drain(store, drainBatch)
+		total += deleted
+		if e != nil || deleted < drainBatch {
+			return total, e
+		}
+	}
+}
+
+// DrainEvery removes the abandoned uploads and drains the blob outbox every
+// interval. It never returns so it should be run in its own goroutine.
+func DrainEvery(store storage.Store, interval time.Duration) {
+	for range time.Tick(interval) {
+		_, e := ExpireUploads()
+		if e != nil {
+			log.Printf("error removing expired uploads: %s\n", e.JSON())
+		}
+
+		_, e = DrainAll(store)
+		if e != nil {
+			log.Printf("error draining the blob outbox: %s\n", e.JSON())
+		}
+	}
+}
+
+// Report is what Reconcile found in storage
+type Report struct {
+	// Blobs is how many blobs are in storage
+	Blobs int
+
+	// Recent is how many blobs were skipped for being too new
+	Recent int
+
+	// Orphans are the blobs no row refers to, sorted by key
+	Orphans []storage.BlobInfo
+
+	// Deleted is how many orphans were deleted
+	Deleted int
+}
+
+// findOrphans returns the blobs that are not in use and were written before
+// the cutoff, sorted by key, along with how many blobs were skipped for
+// being written after it
+func findOrphans(blobs []storage.BlobInfo, inUse map[string]bool, cutoff time.Time) ([]storage.BlobInfo, int) {
+	orphans := make([]storage.BlobInfo, 0)
+	recent := 0
+	for _, b := range blobs {
+		if inUse[b.Key] {
+			continue
+		}
+
+		// the row of a blob that was just stored may not be inserted yet
+		if b.ModTime.After(cutoff) {
+			recent++
+			continue
+		}
+
+		orphans = append(orphans, b)
+	}
+
+	sort.Slice(orphans, func(i, j int) bool {
+		return orphans[i].Key < orphans[j].Key
+	})
+
+	return orphans, recent
+}
+
+// Reconcile compares the blobs in storage against the rows that refer to
+// them and reports the orphans, the blobs no row refers to. Blobs younger
+// than minAge are never orphans. The orphans are deleted when del is true.
+func Reconcile(store storage.Store, minAge time.Duration, del bool) (*Report, *response.Error) {
+	lister, ok := store.(storage.Lister)
+	if !ok {
+		return nil, response.NewError(
+			http.StatusBadRequest,
+			"storage: the storage driver can not list its blobs",
+		)
+	}
+
+	// the cutoff is taken before the keys in use are read so a blob that is
+	// stored and has its row inserted in between is either recent or in use
+	cutoff := time.Now().Add(-minAge)
+
+	inUse, e := db.GetBlobKeysInUse(uploadCutoff())
+	if e != nil {
+		return nil, e
+	}
+
+	blobs, e := lister.List()
+	if e != nil {
+		return nil, e
+	}
+
+	report := Report{Blobs: len(blobs)}
+	report.Orphans, report.Recent = findOrphans(blobs, inUse, cutoff)
+	if !del {
+		return &report, nil
+	}
+
+	errs := response.NewNilError()
+	for _, b := range report.Orphans {
+		deleteErr := store.Delete(b.Key)
+		if deleteErr != nil && deleteErr.Code() != http.StatusNotFound {
+			errs.AddError(deleteErr)
+			continue
+		}
+
+		report.Deleted++
+	}
+
+	return &report, errs.GetError()
+}
@@ -0,0 +1,39 @@
+// +build unit
+
+package gc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/storage"
+)
+
+func TestFindOrphans(t *testing.T) {
+	now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	cutoff := now.Add(-time.Hour)
+
+	blobs := []storage.BlobInfo{
+		{Key: "c.jpg", ModTime: now.Add(-2 * time.Hour)},
+		{Key: "media.jpg", ModTime: now.Add(-2 * time.Hour)},
+		{Key: "media_160.jpg", ModTime: now.Add(-2 * time.Hour)},
+		{Key: "new.jpg", ModTime: now.Add(-time.Minute)},
+		{Key: "a.png", ModTime: now.Add(-24 * time.Hour)},
+	}
+	inUse := map[string]bool{"media.jpg": true, "media_160.jpg": true}
+
+	orphans, recent := findOrphans(blobs, inUse, cutoff)
+	if recent != 1 {
+		t.Errorf("expected 1 recent blob got %d", recent)
+	}
+
+	expected := []string{"a.png", "c.jpg"}
+	if len(orphans) != len(expected) {
+		t.Fatalf("expected %d orphans got %d: %+v", len(expected), len(orphans), orphans)
+	}
+	for i, key := range expected {
+		if orphans[i].Key != key {
+			t.Errorf("expected orphan %d to be %s got %s", i, key, orphans[i].Key)
+		}
+	}
+}
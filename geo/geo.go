@@ -0,0 +1,91 @@
+// Package geo provides calculations on latitude and longitude points
+package geo
+
+import "math"
+
+// EarthRadius is the mean radius of the earth in meters
+const EarthRadius float64 = 6371008.8
+
+// toRadians converts degrees to radians
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// Distance returns the great circle distance, in meters, between the two
+// points using the haversine formula
+func Distance(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*
+			math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Within returns whether or not the two points are no more than radius
+// meters apart
+func Within(lat1, lng1, lat2, lng2, radius float64) bool {
+	return Distance(lat1, lng1, lat2, lng2) <= radius
+}
+
+// Point is a position on the earth
+type Point struct {
+	// the latitude in degrees
+	Latitude float64 `json:"latitude"`
+
+	// the longitude in degrees
+	Longitude float64 `json:"longitude"`
+}
+
+// Contains returns whether or not the point is inside the polygon, whose
+// vertices are in order and do not repeat the first at the end. Latitude and
+// longitude are treated as a plane, which is accurate for the areas a hunt
+// covers as long as the polygon does not cross the antimeridian.
+func Contains(polygon []Point, lat, lng float64) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+
+		// count the edges a ray going east from the point crosses
+		if (a.Latitude > lat) != (b.Latitude > lat) {
+			crossLng := a.Longitude + (lat-a.Latitude)/(b.Latitude-a.Latitude)*(b.Longitude-a.Longitude)
+			if lng < crossLng {
+				inside = !inside
+			}
+		}
+	}
+
+	return inside
+}
+
+// DistanceToEdge returns the distance, in meters, from the point to the
+// closest edge of the polygon. The polygon is projected onto a plane around
+// the point, which is accurate for the distances a hunt covers.
+func DistanceToEdge(polygon []Point, lat, lng float64) float64 {
+	// meters per degree of latitude and of longitude at the point
+	latScale := toRadians(1) * EarthRadius
+	lngScale := latScale * math.Cos(toRadians(lat))
+
+	project := func(p Point) (float64, float64) {
+		return (p.Longitude - lng) * lngScale, (p.Latitude - lat) * latScale
+	}
+
+	closest := math.Inf(1)
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		ax, ay := project(polygon[j])
+		bx, by := project(polygon[i])
+
+		// the closest point on the edge to the origin, which is the point
+		dx, dy := bx-ax, by-ay
+		t := 0.0
+		if lenSq := dx*dx + dy*dy; lenSq > 0 {
+			t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/lenSq))
+		}
+
+		closest = math.Min(closest, math.Hypot(ax+t*dx, ay+t*dy))
+	}
+
+	return closest
+}
@@ -0,0 +1,117 @@
+// +build unit
+
+package geo_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cljohnson4343/scavenge/geo"
+)
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		name       string
+		lat1, lng1 float64
+		lat2, lng2 float64
+		want       float64
+		tolerance  float64
+	}{
+		{name: "same point", lat1: 40.7128, lng1: -74.006, lat2: 40.7128, lng2: -74.006, want: 0, tolerance: 0.001},
+		{name: "one degree of latitude", lat1: 0, lng1: 0, lat2: 1, lng2: 0, want: 111195, tolerance: 1},
+		{name: "new york to london", lat1: 40.7128, lng1: -74.006, lat2: 51.5074, lng2: -0.1278, want: 5570000, tolerance: 5000},
+		{name: "across the antimeridian", lat1: 0, lng1: 179.5, lat2: 0, lng2: -179.5, want: 111195, tolerance: 1},
+		{name: "antipodes", lat1: 0, lng1: 0, lat2: 0, lng2: 180, want: math.Pi * geo.EarthRadius, tolerance: 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := geo.Distance(c.lat1, c.lng1, c.lat2, c.lng2)
+			if math.Abs(got-c.want) > c.tolerance {
+				t.Errorf("expected %f meters got %f", c.want, got)
+			}
+
+			back := geo.Distance(c.lat2, c.lng2, c.lat1, c.lng1)
+			if math.Abs(got-back) > 0.001 {
+				t.Errorf("expected distance to be symmetric got %f and %f", got, back)
+			}
+		})
+	}
+}
+
+func TestWithin(t *testing.T) {
+	// roughly 11 meters north of the first point
+	if !geo.Within(40.7128, -74.006, 40.7129, -74.006, 25) {
+		t.Errorf("expected points to be within 25 meters")
+	}
+
+	if geo.Within(40.7128, -74.006, 40.7129, -74.006, 5) {
+		t.Errorf("expected points to not be within 5 meters")
+	}
+}
+
+// square is roughly a kilometer on each side around central park
+var square = []geo.Point{
+	{Latitude: 40.775, Longitude: -73.975},
+	{Latitude: 40.775, Longitude: -73.963},
+	{Latitude: 40.784, Longitude: -73.963},
+	{Latitude: 40.784, Longitude: -73.975},
+}
+
+func TestContains(t *testing.T) {
+	// an L shape whose notch is outside
+	ell := []geo.Point{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 0, Longitude: 2},
+		{Latitude: 1, Longitude: 2},
+		{Latitude: 1, Longitude: 1},
+		{Latitude: 2, Longitude: 1},
+		{Latitude: 2, Longitude: 0},
+	}
+
+	cases := []struct {
+		name     string
+		polygon  []geo.Point
+		lat, lng float64
+		want     bool
+	}{
+		{"center of square", square, 40.7795, -73.969, true},
+		{"north of square", square, 40.79, -73.969, false},
+		{"east of square", square, 40.7795, -73.96, false},
+		{"west of square", square, 40.7795, -73.98, false},
+		{"inside the ell", ell, 0.5, 1.5, true},
+		{"the ell's notch", ell, 1.5, 1.5, false},
+		{"too few points", square[:2], 40.7795, -73.969, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := geo.Contains(c.polygon, c.lat, c.lng); got != c.want {
+				t.Errorf("expected %v got %v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestDistanceToEdge(t *testing.T) {
+	cases := []struct {
+		name      string
+		lat, lng  float64
+		want      float64
+		tolerance float64
+	}{
+		{"north of the north edge", 40.785, -73.969, 111.2, 1},
+		{"on the south edge", 40.775, -73.969, 0, 0.01},
+		{"past a corner", 40.785, -73.962, geo.Distance(40.784, -73.963, 40.785, -73.962), 1},
+		{"inside near the west edge", 40.7795, -73.974, geo.Distance(40.7795, -73.975, 40.7795, -73.974), 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := geo.DistanceToEdge(square, c.lat, c.lng)
+			if math.Abs(got-c.want) > c.tolerance {
+				t.Errorf("expected %f meters got %f", c.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package hunts
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/events"
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// Announcement is a message the hunt's owner sends to everyone in the hunt
+//
+// swagger:model Announcement
+type Announcement struct {
+	// the message
+	//
+	// maximum length: 1000
+	// required: true
+	Message string `json:"message"`
+
+	// the id of the user that sent the message
+	//
+	// required: false
+	UserID int `json:"userID"`
+}
+
+// Validate validates the announcement
+func (a *Announcement) Validate(r *http.Request) *response.Error {
+	a.Message = strings.TrimSpace(a.Message)
+	if a.Message == "" || len(a.Message) > 1000 {
+		return response.NewError(
+			http.StatusBadRequest,
+			"message: an announcement must be between 1 and 1000 characters",
+		)
+	}
+
+	return nil
+}
+
+// Announce sends the user's announcement to the hunt's event stream
+func Announce(huntID, userID int, a *Announcement) (*db.HuntEventDB, *response.Error) {
+	a.UserID = userID
+	return events.Send(huntID, 0, events.Announcement, a)
+}
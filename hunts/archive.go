@@ -0,0 +1,257 @@
+package hunts
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/cljohnson4343/scavenge/storage"
+)
+
+// archiveManifestName is the name of the manifest in a media archive
+const archiveManifestName = "manifest.json"
+
+// ArchiveEntry describes a media file in a media archive
+type ArchiveEntry struct {
+	// the path of the file in the archive
+	File string `json:"file"`
+
+	// the id of the media
+	MediaID int `json:"mediaID"`
+
+	// the id of the team that uploaded the media
+	TeamID int `json:"teamID"`
+
+	// the name of the team that uploaded the media
+	TeamName string `json:"teamName"`
+
+	// the id of the media's item, if any
+	ItemID int `json:"itemID,omitempty"`
+
+	// the name of the media's item, if any
+	ItemName string `json:"itemName,omitempty"`
+
+	// the points the media's item is worth
+	Points int `json:"points"`
+
+	// where the team was when it uploaded the media
+	Latitude float32 `json:"latitude"`
+
+	// where the team was when it uploaded the media
+	Longitude float32 `json:"longitude"`
+
+	// when the team uploaded the media
+	SubmittedAt time.Time `json:"submittedAt"`
+
+	// when the photo was taken, read from its EXIF data
+	TakenAt *time.Time `json:"takenAt,omitempty"`
+
+	// where the photo was taken, read from its EXIF data
+	TakenLatitude float32 `json:"takenLatitude,omitempty"`
+
+	// where the photo was taken, read from its EXIF data
+	TakenLongitude float32 `json:"takenLongitude,omitempty"`
+
+	// whether or not the file could not be read from storage, in which case
+	// it is not in the archive
+	Missing bool `json:"missing,omitempty"`
+}
+
+// ArchiveTeam is a team's score in a media archive
+type ArchiveTeam struct {
+	// the id of the team
+	TeamID int `json:"teamID"`
+
+	// the name of the team
+	TeamName string `json:"teamName"`
+
+	// the team's points
+	Points int `json:"points"`
+}
+
+// ArchiveManifest is the manifest.json of a media archive
+type ArchiveManifest struct {
+	// the id of the hunt
+	HuntID int `json:"huntID"`
+
+	// the name of the hunt
+	HuntName string `json:"huntName"`
+
+	// when the archive was made
+	GeneratedAt time.Time `json:"generatedAt"`
+
+	// the hunt's teams and their points
+	Teams []*ArchiveTeam `json:"teams"`
+
+	// the media files in the archive, ordered by team and then item
+	Media []*ArchiveEntry `json:"media"`
+}
+
+// archiveName returns a name, safe to use in a path, made from the given
+// name and id. The id keeps names that only differ in unsafe characters
+// apart.
+func archiveName(name string, id int) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '-' || r == '_':
+			return r
+		}
+		return '_'
+	}, strings.TrimSpace(name))
+
+	safe = strings.Trim(safe, "_")
+	if safe == "" {
+		return fmt.Sprintf("%d", id)
+	}
+
+	return fmt.Sprintf("%s-%d", safe, id)
+}
+
+// archivePath returns the path of the media in an archive, organized by
+// team and then item
+func archivePath(m *db.HuntMediaDB) string {
+	item := "no-item"
+	if m.ItemID != 0 {
+		item = archiveName(m.ItemName, m.ItemID)
+	}
+
+	key := storage.GetKey(m.URL)
+	return fmt.Sprintf(
+		"%s/%s/%d%s",
+		archiveName(m.TeamName, m.TeamID),
+		item,
+		m.ID,
+		strings.ToLower(storage.GetExt(key)),
+	)
+}
+
+// newArchiveEntry returns the manifest entry of the media
+func newArchiveEntry(m *db.HuntMediaDB) *ArchiveEntry {
+	entry := ArchiveEntry{
+		File:           archivePath(m),
+		MediaID:        m.ID,
+		TeamID:         m.TeamID,
+		TeamName:       m.TeamName,
+		ItemID:         m.ItemID,
+		ItemName:       m.ItemName,
+		Points:         m.Points,
+		Latitude:       m.Location.Latitude,
+		Longitude:      m.Location.Longitude,
+		SubmittedAt:    m.Location.TimeStamp,
+		TakenLatitude:  m.TakenLatitude,
+		TakenLongitude: m.TakenLongitude,
+	}
+	if !m.TakenAt.IsZero() {
+		takenAt := m.TakenAt
+		entry.TakenAt = &takenAt
+	}
+
+	return &entry
+}
+
+// NewMediaArchive returns the manifest of the hunt's media archive along
+// with the media to put in it
+func NewMediaArchive(huntID int) (*ArchiveManifest, []*db.HuntMediaDB, *response.Error) {
+	hunt, e := db.GetHunt(huntID)
+	if e != nil {
+		return nil, nil, e
+	}
+
+	teams, e := db.TeamsForHunt(huntID)
+	if e != nil {
+		return nil, nil, e
+	}
+
+	medias, e := db.GetMediaForHunt(huntID)
+	if e != nil {
+		return nil, nil, e
+	}
+
+	manifest := ArchiveManifest{
+		HuntID:      hunt.ID,
+		HuntName:    hunt.Name,
+		GeneratedAt: time.Now().UTC(),
+		Teams:       make([]*ArchiveTeam, 0, len(teams)),
+		Media:       make([]*ArchiveEntry, 0, len(medias)),
+	}
+
+	for _, t := range teams {
+		points, e := db.GetTeamPoints(t.ID)
+		if e != nil {
+			return nil, nil, e
+		}
+
+		manifest.Teams = append(manifest.Teams, &ArchiveTeam{
+			TeamID:   t.ID,
+			TeamName: t.Name,
+			Points:   points,
+		})
+	}
+
+	return &manifest, medias, nil
+}
+
+// WriteMediaArchive streams a zip of the media to w, one file at a time, and
+// ends it with the manifest. Files are stored without compression because
+// photos and videos are already compressed. Files that can not be read from
+// storage are marked missing in the manifest and left out.
+func WriteMediaArchive(w io.Writer, store storage.Store, manifest *ArchiveManifest, medias []*db.HuntMediaDB) *response.Error {
+	zw := zip.NewWriter(w)
+
+	for _, m := range medias {
+		entry := newArchiveEntry(m)
+		manifest.Media = append(manifest.Media, entry)
+
+		file, e := store.Get(storage.GetKey(m.URL))
+		if e != nil {
+			entry.Missing = true
+			continue
+		}
+
+		header := zip.FileHeader{
+			Name:     entry.File,
+			Method:   zip.Store,
+			Modified: m.Location.TimeStamp,
+		}
+		fw, err := zw.CreateHeader(&header)
+		if err == nil {
+			_, err = io.Copy(fw, file)
+		}
+		file.Close()
+		if err != nil {
+			return response.NewErrorf(
+				http.StatusInternalServerError,
+				"error writing media %d to the archive: %v",
+				m.ID,
+				err,
+			)
+		}
+	}
+
+	fw, err := zw.Create(archiveManifestName)
+	if err == nil {
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(manifest)
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error writing the archive manifest: %v",
+			err,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+// +build unit
+
+package hunts
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/storage"
+)
+
+func TestArchiveName(t *testing.T) {
+	cases := []struct {
+		name     string
+		id       int
+		expected string
+	}{
+		{name: "Red Team", id: 4, expected: "Red_Team-4"},
+		{name: "../../etc", id: 5, expected: "etc-5"},
+		{name: "   ", id: 6, expected: "6"},
+		{name: "café-crew_1", id: 7, expected: "caf_-crew_1-7"},
+	}
+
+	for _, c := range cases {
+		if name := archiveName(c.name, c.id); name != c.expected {
+			t.Errorf("expected %q to be %s got %s", c.name, c.expected, name)
+		}
+	}
+}
+
+func TestWriteMediaArchive(t *testing.T) {
+	store := storage.NewMemoryStore("/files/")
+	photo, _ := store.Put("photo.JPG", strings.NewReader("photo data"))
+	video, _ := store.Put("video.mp4", strings.NewReader("video data"))
+
+	submitted := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	taken := submitted.Add(-time.Minute)
+
+	medias := []*db.HuntMediaDB{
+		{
+			MediaMetaDB: db.MediaMetaDB{
+				ID:       1,
+				TeamID:   2,
+				ItemID:   3,
+				URL:      photo,
+				Location: db.LocationDB{Latitude: 40.5, Longitude: -105.1, TimeStamp: submitted},
+				TakenAt:  taken,
+			},
+			TeamName: "Red Team",
+			ItemName: "Statue",
+			Points:   10,
+		},
+		{
+			MediaMetaDB: db.MediaMetaDB{
+				ID:       4,
+				TeamID:   2,
+				URL:      video,
+				Location: db.LocationDB{TimeStamp: submitted},
+			},
+			TeamName: "Red Team",
+		},
+		{
+			MediaMetaDB: db.MediaMetaDB{
+				ID:       5,
+				TeamID:   6,
+				ItemID:   3,
+				URL:      "/files/gone.jpg",
+				Location: db.LocationDB{TimeStamp: submitted},
+			},
+			TeamName: "Blue",
+			ItemName: "Statue",
+			Points:   10,
+		},
+	}
+
+	manifest := ArchiveManifest{HuntID: 43, HuntName: "Hunt"}
+	buf := bytes.Buffer{}
+	e := WriteMediaArchive(&buf, store, &manifest, medias)
+	if e != nil {
+		t.Fatalf("expected no error got %s", e.JSON())
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading archive: %v", err)
+	}
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("error opening %s: %v", f.Name, err)
+		}
+		b, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+
+	expected := map[string]string{
+		"Red_Team-2/Statue-3/1.jpg": "photo data",
+		"Red_Team-2/no-item/4.mp4":  "video data",
+	}
+	for name, data := range expected {
+		if files[name] != data {
+			t.Errorf("expected %s to have %q got %q", name, data, files[name])
+		}
+	}
+	if len(files) != len(expected)+1 {
+		t.Errorf("expected %d files got %d: %v", len(expected)+1, len(files), files)
+	}
+
+	m := ArchiveManifest{}
+	if err = json.Unmarshal([]byte(files[archiveManifestName]), &m); err != nil {
+		t.Fatalf("error reading manifest: %v", err)
+	}
+
+	if len(m.Media) != 3 {
+		t.Fatalf("expected 3 manifest entries got %d", len(m.Media))
+	}
+	first := m.Media[0]
+	if first.Points != 10 || first.Latitude != 40.5 || !first.SubmittedAt.Equal(submitted) {
+		t.Errorf("expected the first entry's points, location, and time got %+v", first)
+	}
+	if first.TakenAt == nil || !first.TakenAt.Equal(taken) {
+		t.Errorf("expected taken at %v got %v", taken, first.TakenAt)
+	}
+	if m.Media[1].TakenAt != nil {
+		t.Errorf("expected no taken at for a video got %v", m.Media[1].TakenAt)
+	}
+	if !m.Media[2].Missing || m.Media[0].Missing {
+		t.Errorf("expected only the last entry to be missing got %+v", m.Media)
+	}
+}
@@ -0,0 +1,333 @@
+package hunts
+
+import (
+	"math/rand"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/cljohnson4343/scavenge/roles"
+	"github.com/cljohnson4343/scavenge/teams"
+)
+
+const (
+	// balanceRandom puts each player on a random team that has room
+	balanceRandom = "random"
+
+	// balanceBalanced fills the smallest teams first so team sizes differ
+	// by as little as possible
+	balanceBalanced = "balanced"
+
+	// balanceGroups is balanceBalanced but keeps each declared group of
+	// friends on the same team
+	balanceGroups = "groups"
+)
+
+// A BalanceRequest asks for the unassigned players of a hunt to be
+// distributed into its teams
+//
+// swagger:model BalanceRequest
+type BalanceRequest struct {
+
+	// the strategy used to pick teams: random, balanced, or groups
+	//
+	// required: true
+	Strategy string `json:"strategy" valid:"-"`
+
+	// the groups of player ids that should be kept on the same team. Only
+	// used by the groups strategy.
+	//
+	// required: false
+	Groups [][]int `json:"groups" valid:"-"`
+}
+
+// Validate validates the balance request
+func (req *BalanceRequest) Validate(r *http.Request) *response.Error {
+	switch req.Strategy {
+	case balanceRandom, balanceBalanced, balanceGroups:
+	default:
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"strategy: must be one of %s, %s, or %s",
+			balanceRandom,
+			balanceBalanced,
+			balanceGroups,
+		)
+	}
+
+	seen := make(map[int]bool)
+	for _, group := range req.Groups {
+		for _, id := range group {
+			if seen[id] {
+				return response.NewErrorf(
+					http.StatusBadRequest,
+					"groups: player %d is in more than one group",
+					id,
+				)
+			}
+			seen[id] = true
+		}
+	}
+
+	return nil
+}
+
+// An Assignment puts a player on a team
+//
+// swagger:model Assignment
+type Assignment struct {
+	PlayerID int `json:"playerID" valid:"-"`
+	TeamID   int `json:"teamID" valid:"-"`
+}
+
+// A BalancePlan is the result of balancing a hunt's teams. Unassigned lists
+// the players that did not fit on any team.
+//
+// swagger:model BalancePlan
+type BalancePlan struct {
+	Assignments []*Assignment `json:"assignments" valid:"-"`
+	Unassigned  []int         `json:"unassigned" valid:"-"`
+}
+
+// Validate validates the plan before it is committed
+func (plan *BalancePlan) Validate(r *http.Request) *response.Error {
+	if len(plan.Assignments) == 0 {
+		return response.NewError(
+			http.StatusBadRequest,
+			"assignments: at least one assignment is required",
+		)
+	}
+
+	seen := make(map[int]bool)
+	for _, a := range plan.Assignments {
+		if seen[a.PlayerID] {
+			return response.NewErrorf(
+				http.StatusBadRequest,
+				"assignments: player %d is assigned more than once",
+				a.PlayerID,
+			)
+		}
+		seen[a.PlayerID] = true
+	}
+
+	return nil
+}
+
+// teamSlot tracks how many players a team has while balancing
+type teamSlot struct {
+	id   int
+	size int
+}
+
+// balanceTeams assigns the players to the given teams. A capacity of 0 means
+// teams have no size limit. Players that do not fit on any team are returned
+// as unassigned. The slots are updated with the new team sizes.
+func balanceTeams(
+	strategy string,
+	slots []*teamSlot,
+	capacity int,
+	players []int,
+	groups [][]int,
+	rng *rand.Rand,
+) *BalancePlan {
+	plan := BalancePlan{
+		Assignments: make([]*Assignment, 0, len(players)),
+		Unassigned:  make([]int, 0),
+	}
+
+	hasRoom := func(s *teamSlot, n int) bool {
+		return capacity == 0 || s.size+n <= capacity
+	}
+
+	// smallest returns the smallest team with room for n players, ties go to
+	// the team that comes first
+	smallest := func(n int) *teamSlot {
+		var best *teamSlot
+		for _, s := range slots {
+			if hasRoom(s, n) && (best == nil || s.size < best.size) {
+				best = s
+			}
+		}
+		return best
+	}
+
+	assign := func(s *teamSlot, ids ...int) {
+		for _, id := range ids {
+			plan.Assignments = append(plan.Assignments, &Assignment{PlayerID: id, TeamID: s.id})
+		}
+		s.size += len(ids)
+	}
+
+	shuffled := make([]int, len(players))
+	copy(shuffled, players)
+	rng.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	switch strategy {
+	case balanceRandom:
+		for _, id := range shuffled {
+			open := make([]*teamSlot, 0, len(slots))
+			for _, s := range slots {
+				if hasRoom(s, 1) {
+					open = append(open, s)
+				}
+			}
+
+			if len(open) == 0 {
+				plan.Unassigned = append(plan.Unassigned, id)
+				continue
+			}
+
+			assign(open[rng.Intn(len(open))], id)
+		}
+
+	case balanceGroups:
+		isPlayer := make(map[int]bool, len(players))
+		for _, id := range players {
+			isPlayer[id] = true
+		}
+
+		// every player is a unit, either on their own or with their group
+		grouped := make(map[int]bool)
+		units := make([][]int, 0, len(players))
+		for _, group := range groups {
+			unit := make([]int, 0, len(group))
+			for _, id := range group {
+				if isPlayer[id] && !grouped[id] {
+					unit = append(unit, id)
+					grouped[id] = true
+				}
+			}
+			if len(unit) > 0 {
+				units = append(units, unit)
+			}
+		}
+		for _, id := range shuffled {
+			if !grouped[id] {
+				units = append(units, []int{id})
+			}
+		}
+
+		// placing the largest units first leaves the singles to even out
+		// the team sizes
+		sort.SliceStable(units, func(i, j int) bool {
+			return len(units[i]) > len(units[j])
+		})
+
+		for _, unit := range units {
+			if s := smallest(len(unit)); s != nil {
+				assign(s, unit...)
+				continue
+			}
+
+			// the group does not fit on any team so it is split up
+			for _, id := range unit {
+				if s := smallest(1); s != nil {
+					assign(s, id)
+				} else {
+					plan.Unassigned = append(plan.Unassigned, id)
+				}
+			}
+		}
+
+	default:
+		for _, id := range shuffled {
+			if s := smallest(1); s != nil {
+				assign(s, id)
+			} else {
+				plan.Unassigned = append(plan.Unassigned, id)
+			}
+		}
+	}
+
+	return &plan
+}
+
+// PreviewBalance returns how the unassigned players of the given hunt would
+// be distributed into its teams. Nothing is changed until the plan is
+// committed with CommitBalance.
+func PreviewBalance(huntID int, req *BalanceRequest) (*BalancePlan, *response.Error) {
+	hunt, e := db.GetHunt(huntID)
+	if e != nil {
+		return nil, e
+	}
+
+	teamDBs, e := db.TeamsForHunt(huntID)
+	if e != nil {
+		return nil, e
+	}
+
+	if len(teamDBs) == 0 {
+		return nil, response.NewErrorf(
+			http.StatusBadRequest,
+			"hunt_id: hunt %d does not have any teams to balance",
+			huntID,
+		)
+	}
+
+	players, e := db.GetPlayersForHunt(huntID)
+	if e != nil {
+		return nil, e
+	}
+
+	sizes := make(map[int]int, len(teamDBs))
+	unassigned := make([]int, 0, len(players))
+	for _, p := range players {
+		if p.TeamID == 0 {
+			unassigned = append(unassigned, p.ID)
+		} else {
+			sizes[p.TeamID]++
+		}
+	}
+
+	slots := make([]*teamSlot, 0, len(teamDBs))
+	for _, t := range teamDBs {
+		slots = append(slots, &teamSlot{id: t.ID, size: sizes[t.ID]})
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return balanceTeams(
+		req.Strategy,
+		slots,
+		hunt.MaxPlayersPerTeam,
+		unassigned,
+		req.Groups,
+		rng,
+	), nil
+}
+
+// CommitBalance applies the plan's assignments. Either every assignment is
+// made or none are. Only players without a team can be assigned.
+func CommitBalance(huntID int, plan *BalancePlan) *response.Error {
+	players := make([]*db.PlayerDB, 0, len(plan.Assignments))
+	for _, a := range plan.Assignments {
+		players = append(players, &db.PlayerDB{
+			UserDB: db.UserDB{ID: a.PlayerID},
+			TeamID: a.TeamID,
+			HuntID: huntID,
+		})
+	}
+
+	e := db.AssignPlayersToTeams(huntID, players)
+	if e != nil {
+		return e
+	}
+
+	e = response.NewNilError()
+	for _, a := range plan.Assignments {
+		teamEditor := roles.New("team_editor", a.TeamID)
+		roleErr := teamEditor.AddTo(a.PlayerID)
+		if roleErr != nil {
+			e.AddError(roleErr)
+		}
+	}
+	if e.GetError() != nil {
+		return e
+	}
+
+	// moving players between teams can open spots for the waitlist
+	return teams.PromoteWaitlist(huntID)
+}
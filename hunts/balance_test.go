@@ -0,0 +1,201 @@
+// +build unit
+
+package hunts
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func newSlots(sizes ...int) []*teamSlot {
+	slots := make([]*teamSlot, 0, len(sizes))
+	for i, size := range sizes {
+		slots = append(slots, &teamSlot{id: i + 1, size: size})
+	}
+
+	return slots
+}
+
+func playerRange(from, to int) []int {
+	players := make([]int, 0, to-from+1)
+	for id := from; id <= to; id++ {
+		players = append(players, id)
+	}
+
+	return players
+}
+
+// teamOf maps each assigned player to their team
+func teamOf(plan *BalancePlan) map[int]int {
+	m := make(map[int]int, len(plan.Assignments))
+	for _, a := range plan.Assignments {
+		m[a.PlayerID] = a.TeamID
+	}
+
+	return m
+}
+
+func TestBalanceTeamsBalanced(t *testing.T) {
+	slots := newSlots(3, 0, 1)
+	rng := rand.New(rand.NewSource(1))
+
+	plan := balanceTeams(balanceBalanced, slots, 0, playerRange(1, 8), nil, rng)
+
+	if len(plan.Assignments) != 8 {
+		t.Fatalf("expected 8 assignments got %d", len(plan.Assignments))
+	}
+	if len(plan.Unassigned) != 0 {
+		t.Errorf("expected no unassigned players got %v", plan.Unassigned)
+	}
+
+	min, max := slots[0].size, slots[0].size
+	for _, s := range slots {
+		if s.size < min {
+			min = s.size
+		}
+		if s.size > max {
+			max = s.size
+		}
+	}
+	if max-min > 1 {
+		t.Errorf("expected team sizes to differ by at most 1 got %d and %d", min, max)
+	}
+}
+
+func TestBalanceTeamsCapacity(t *testing.T) {
+	for _, strategy := range []string{balanceRandom, balanceBalanced, balanceGroups} {
+		t.Run(strategy, func(t *testing.T) {
+			slots := newSlots(2, 0)
+			rng := rand.New(rand.NewSource(1))
+
+			plan := balanceTeams(strategy, slots, 3, playerRange(1, 6), nil, rng)
+
+			if len(plan.Assignments) != 4 {
+				t.Errorf("expected 4 assignments got %d", len(plan.Assignments))
+			}
+			if len(plan.Unassigned) != 2 {
+				t.Errorf("expected 2 unassigned players got %v", plan.Unassigned)
+			}
+			for _, s := range slots {
+				if s.size > 3 {
+					t.Errorf("expected team %d to have at most 3 players got %d", s.id, s.size)
+				}
+			}
+		})
+	}
+}
+
+func TestBalanceTeamsGroups(t *testing.T) {
+	slots := newSlots(0, 0, 0)
+	rng := rand.New(rand.NewSource(1))
+	groups := [][]int{{1, 2, 3}, {4, 5}}
+
+	plan := balanceTeams(balanceGroups, slots, 4, playerRange(1, 9), groups, rng)
+
+	if len(plan.Unassigned) != 0 {
+		t.Errorf("expected no unassigned players got %v", plan.Unassigned)
+	}
+
+	teams := teamOf(plan)
+	for _, group := range groups {
+		for _, id := range group[1:] {
+			if teams[id] != teams[group[0]] {
+				t.Errorf(
+					"expected players %d and %d to be on the same team got %d and %d",
+					group[0],
+					id,
+					teams[group[0]],
+					teams[id],
+				)
+			}
+		}
+	}
+
+	for _, s := range slots {
+		if s.size != 3 {
+			t.Errorf("expected team %d to have 3 players got %d", s.id, s.size)
+		}
+	}
+}
+
+func TestBalanceTeamsSplitsLargeGroups(t *testing.T) {
+	slots := newSlots(0, 0)
+	rng := rand.New(rand.NewSource(1))
+
+	plan := balanceTeams(balanceGroups, slots, 2, playerRange(1, 3), [][]int{{1, 2, 3}}, rng)
+
+	if len(plan.Assignments) != 3 {
+		t.Errorf("expected 3 assignments got %d", len(plan.Assignments))
+	}
+	if len(plan.Unassigned) != 0 {
+		t.Errorf("expected no unassigned players got %v", plan.Unassigned)
+	}
+}
+
+func TestBalanceTeamsIgnoresUnknownGroupMembers(t *testing.T) {
+	slots := newSlots(0)
+	rng := rand.New(rand.NewSource(1))
+
+	plan := balanceTeams(balanceGroups, slots, 0, []int{1}, [][]int{{1, 99}}, rng)
+
+	if len(plan.Assignments) != 1 || plan.Assignments[0].PlayerID != 1 {
+		t.Errorf("expected only player 1 to be assigned got %v", plan.Assignments)
+	}
+}
+
+func TestBalanceRequestValidate(t *testing.T) {
+	cases := []struct {
+		name  string
+		req   BalanceRequest
+		valid bool
+	}{
+		{name: "random", req: BalanceRequest{Strategy: balanceRandom}, valid: true},
+		{name: "balanced", req: BalanceRequest{Strategy: balanceBalanced}, valid: true},
+		{
+			name:  "groups",
+			req:   BalanceRequest{Strategy: balanceGroups, Groups: [][]int{{1, 2}, {3}}},
+			valid: true,
+		},
+		{name: "unknown strategy", req: BalanceRequest{Strategy: "alphabetical"}},
+		{name: "missing strategy", req: BalanceRequest{}},
+		{
+			name: "player in two groups",
+			req:  BalanceRequest{Strategy: balanceGroups, Groups: [][]int{{1, 2}, {2, 3}}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e := c.req.Validate(nil)
+			if c.valid && e != nil {
+				t.Errorf("expected no error got %s", e.JSON())
+			}
+			if !c.valid && e == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestBalancePlanValidate(t *testing.T) {
+	empty := BalancePlan{}
+	if e := empty.Validate(nil); e == nil {
+		t.Errorf("expected an error for a plan with no assignments")
+	}
+
+	dup := BalancePlan{Assignments: []*Assignment{
+		{PlayerID: 1, TeamID: 1},
+		{PlayerID: 1, TeamID: 2},
+	}}
+	if e := dup.Validate(nil); e == nil {
+		t.Errorf("expected an error for a player assigned twice")
+	}
+
+	ok := BalancePlan{Assignments: []*Assignment{
+		{PlayerID: 1, TeamID: 1},
+		{PlayerID: 2, TeamID: 1},
+	}}
+	if e := ok.Validate(nil); e != nil {
+		t.Errorf("expected no error got %s", e.JSON())
+	}
+}
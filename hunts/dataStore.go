@@ -145,6 +145,44 @@ func GetHuntsByUserID(userID int) ([]*Hunt, *response.Error) {
 	return hunts, e.GetError()
 }
 
+// GetHuntsNear returns the Hunts found by the given location search, ordered
+// by distance from the searched point
+func GetHuntsNear(q *nearQuery) ([]*Hunt, *response.Error) {
+	huntDBs, e := db.GetHuntsNear(q.Latitude, q.Longitude, q.Radius, q.Limit)
+	if huntDBs == nil {
+		return nil, e
+	}
+
+	if e == nil {
+		e = response.NewNilError()
+	}
+
+	hunts := make([]*Hunt, 0, len(huntDBs))
+
+	for _, h := range huntDBs {
+		ts, teamErr := teams.GetTeamsForHunt(h.ID)
+		if teamErr != nil {
+			e.AddError(teamErr)
+		}
+
+		items, itemErr := GetItemsForHunt(h.ID)
+		if itemErr != nil {
+			e.AddError(itemErr)
+		}
+
+		players, playersErr := db.GetPlayersForHunt(h.ID)
+		if playersErr != nil {
+			e.AddError(playersErr)
+		}
+
+		hunt := Hunt{HuntDB: *h, Teams: ts, Items: items, Players: players}
+
+		hunts = append(hunts, &hunt)
+	}
+
+	return hunts, e.GetError()
+}
+
 // InsertHunt inserts the given hunt into the database and updates the hunt
 // with the new id and created_at timestamp
 func InsertHunt(userID int, hunt *Hunt) *response.Error {
@@ -3,17 +3,24 @@ package hunts
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/cljohnson4343/scavenge/db"
 	"github.com/cljohnson4343/scavenge/users"
 
 	"github.com/cljohnson4343/scavenge/config"
+	"github.com/cljohnson4343/scavenge/events"
 	"github.com/cljohnson4343/scavenge/hunts/models"
 	"github.com/cljohnson4343/scavenge/request"
 	"github.com/cljohnson4343/scavenge/response"
+	"github.com/cljohnson4343/scavenge/roles"
+	"github.com/cljohnson4343/scavenge/teams"
+	"github.com/cljohnson4343/scavenge/tracks"
 	"github.com/go-chi/render"
 )
 
@@ -21,7 +28,10 @@ import (
 //
 // Lists hunts.
 //
-// This will show all hunts by default.
+// This will show all hunts by default. Providing latitude and
+// longitude query parameters lists the hunts within radius meters
+// (default 10000) of that point, nearest first. The number of hunts
+// returned by a location search can be capped with limit.
 //
 // Consumes:
 // 	- application/json
@@ -52,6 +62,35 @@ func getHuntsHandler() http.HandlerFunc {
 				return
 			}
 
+			e = showVisibleItems(r, hunts...)
+			if e != nil {
+				e.Handle(w)
+				return
+			}
+
+			render.JSON(w, r, hunts)
+			return
+		}
+
+		if isNearQuery(values) {
+			q, e := parseNearQuery(values)
+			if e != nil {
+				e.Handle(w)
+				return
+			}
+
+			hunts, e := GetHuntsNear(q)
+			if e != nil {
+				e.Handle(w)
+				return
+			}
+
+			e = showVisibleItems(r, hunts...)
+			if e != nil {
+				e.Handle(w)
+				return
+			}
+
 			render.JSON(w, r, hunts)
 			return
 		}
@@ -74,6 +113,12 @@ func getHuntsHandler() http.HandlerFunc {
 				return
 			}
 
+			e = showVisibleItems(r, hunt)
+			if e != nil {
+				e.Handle(w)
+				return
+			}
+
 			render.JSON(w, r, hunt)
 			return
 		}
@@ -83,6 +128,12 @@ func getHuntsHandler() http.HandlerFunc {
 			e.Handle(w)
 		}
 
+		e = showVisibleItems(r, hunts...)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
 		render.JSON(w, r, hunts)
 		return
 	})
@@ -117,6 +168,12 @@ func getHuntHandler(env *config.Env) http.HandlerFunc {
 			e.Handle(w)
 		}
 
+		e = showVisibleItems(r, hunt)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
 		render.JSON(w, r, &hunt)
 		return
 	})
@@ -165,7 +222,8 @@ func createHuntHandler() http.HandlerFunc {
 
 // swagger:route DELETE /hunts/{huntID} hunt delete deleteHuntHandler
 //
-// Deletes the given hunt.
+// Deletes the given hunt. The files its teams uploaded are deleted from
+// storage shortly after.
 //
 // Consumes:
 // 	- application/json
@@ -245,13 +303,25 @@ func patchHuntHandler(env *config.Env) http.HandlerFunc {
 			return
 		}
 
+		// raising the hunt's capacity can open spots for the waitlist
+		if hunt.MaxTeams != 0 || hunt.MaxPlayersPerTeam != 0 {
+			e = teams.PromoteWaitlist(huntID)
+			if e != nil {
+				e.Handle(w)
+				return
+			}
+		}
+
 		return
 	})
 }
 
 // swagger:route GET /hunts/{huntID}/items items getItemsHandler
 //
-// Lists the items for hunt with {huntID}.
+// Lists the items for hunt with {huntID}. The items can be filtered with the
+// category, tag, type, q, difficulty, minDifficulty, and maxDifficulty query
+// parameters and sorted with sort=name|points|difficulty|category. Prefix the
+// sort field with - to sort in descending order.
 //
 // Consumes:
 // 	- application/json
@@ -273,12 +343,30 @@ func getItemsHandler(env *config.Env) http.HandlerFunc {
 			return
 		}
 
+		query, e := parseItemQuery(r.URL.Query())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
 		items, e := GetItems(huntID)
 		if e != nil {
 			e.Handle(w)
 		}
 
-		render.JSON(w, r, items)
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		items, e = VisibleItems(huntID, userID, items)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, query.apply(items))
 		return
 	})
 }
@@ -324,6 +412,8 @@ func deleteItemHandler(env *config.Env) http.HandlerFunc {
 // swagger:route POST /hunts/{huntID}/items item create createItemHandler
 //
 // Creates the item described in the request body for the given hunt.
+// Only the hunt's owners can set the item's answers, checkInCode,
+// latitude, longitude, radius, or enforceGeofence.
 //
 // Consumes:
 // 	- application/json
@@ -336,6 +426,7 @@ func deleteItemHandler(env *config.Env) http.HandlerFunc {
 // Responses:
 // 	200:
 //  400:
+//  403:
 //  500:
 func createItemHandler(env *config.Env) http.HandlerFunc {
 	return (func(w http.ResponseWriter, r *http.Request) {
@@ -352,6 +443,18 @@ func createItemHandler(env *config.Env) http.HandlerFunc {
 			return
 		}
 
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		e = RequireSecretAccess(huntID, userID, &item)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
 		item.HuntID = huntID
 		e = InsertItem(&item)
 		if e != nil {
@@ -372,7 +475,9 @@ func createItemHandler(env *config.Env) http.HandlerFunc {
 // will update the corresponding item's value with that
 // key's value. To update the name of the item send
 // body: {"name": "New Item Name"}. NOTE that the id and hunt_id
-// are not eligible to be changed
+// are not eligible to be changed, and only the hunt's owners can
+// change the answers, checkInCode, latitude, longitude, radius, or
+// enforceGeofence
 //
 // Consumes:
 // 	- application/json
@@ -385,6 +490,7 @@ func createItemHandler(env *config.Env) http.HandlerFunc {
 // Responses:
 // 	200:
 // 	400:
+// 	403:
 func patchItemHandler(env *config.Env) http.HandlerFunc {
 	return (func(w http.ResponseWriter, r *http.Request) {
 		huntID, e := request.GetIntURLParam(r, "huntID")
@@ -415,6 +521,18 @@ func patchItemHandler(env *config.Env) http.HandlerFunc {
 			return
 		}
 
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		e = RequireSecretAccess(huntID, userID, &item)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
 		e = UpdateItem(env, &item)
 		if e != nil {
 			e.Handle(w)
@@ -711,6 +829,12 @@ func removeHuntPlayerHandler() http.HandlerFunc {
 			return
 		}
 
+		e = teams.PromoteWaitlist(huntID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
 		return
 	}
 }
@@ -861,3 +985,1762 @@ func declineHuntInvitationHandler() http.HandlerFunc {
 		return
 	}
 }
+
+// swagger:route POST /hunts/{huntID}/codes/ hunt codes create
+//
+// Creates a join code and invite link for the given hunt. The code
+// can optionally be limited to a number of uses and an expiration time.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func createJoinCodeHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		joinCode := db.HuntJoinCodeDB{}
+		e = request.DecodeAndValidate(r, &joinCode)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		creatorID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		joinCode.HuntID = huntID
+		joinCode.CreatorID = creatorID
+		code, e := CreateJoinCode(&joinCode)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, code)
+	}
+}
+
+// swagger:route GET /hunts/{huntID}/codes/ hunt codes
+//
+// Gets the join codes for the given hunt.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func getJoinCodesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		codes, e := GetJoinCodes(huntID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, codes)
+	}
+}
+
+// swagger:route DELETE /hunts/{huntID}/codes/{codeID} hunt codes delete
+//
+// Deletes the join code. Invite links for the code stop working.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func deleteJoinCodeHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		codeID, e := request.GetIntURLParam(r, "codeID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		e = db.DeleteHuntJoinCode(codeID, huntID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		return
+	}
+}
+
+// swagger:route POST /hunts/join/ hunt join
+//
+// Joins the hunt for the given join code or invite token. The
+// joined hunt is returned.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func redeemJoinCodeHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := RedeemRequest{}
+		e := request.DecodeAndValidate(r, &req)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		hunt, e := RedeemJoinCode(&req, userID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		hunt.Items, e = VisibleItems(hunt.ID, userID, hunt.Items)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, hunt)
+	}
+}
+
+// swagger:route POST /hunts/{huntID}/waitlist/ hunt waitlist join
+//
+// Adds the user to the waitlist of a full hunt or team. Provide a
+// teamName to wait for a spot to create a team or a teamID to wait
+// for a spot on a full team. Waiting users are promoted, oldest first,
+// when a spot opens up and are sent a message.
+//
+// Waiting for a spot on a team requires already playing in the hunt.
+// Waiting to create a team requires playing in, or an invitation to,
+// the hunt or else a join code for it, given as code. The code is
+// redeemed when the entry is added.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  403:
+//  500:
+func joinWaitlistHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		entry := db.WaitlistEntryDB{}
+		e = request.DecodeAndValidate(r, &entry)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		entry.HuntID = huntID
+		entry.UserID = userID
+		e = teams.JoinWaitlist(&entry)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, &entry)
+	}
+}
+
+// swagger:route GET /hunts/{huntID}/waitlist/ hunt waitlist
+//
+// Gets the waitlist for the given hunt, oldest entry first.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func getWaitlistHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		entries, e := db.GetWaitlistForHunt(huntID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, entries)
+	}
+}
+
+// swagger:route DELETE /hunts/{huntID}/waitlist/{waitlistID} hunt waitlist leave
+//
+// Removes the entry from the hunt's waitlist. Users can remove their
+// own entries and the hunt's owners can remove any entry.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  403:
+//  500:
+func leaveWaitlistHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		waitlistID, e := request.GetIntURLParam(r, "waitlistID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		entry, e := db.GetWaitlistEntry(waitlistID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		if entry.UserID != userID {
+			isOwner, e := roles.UserHasRole("hunt_owner", huntID, userID)
+			if e != nil {
+				e.Handle(w)
+				return
+			}
+
+			if !isOwner {
+				e = response.NewError(
+					http.StatusForbidden,
+					"You are not authorized to remove this waitlist entry",
+				)
+				e.Handle(w)
+				return
+			}
+		}
+
+		e = db.DeleteWaitlistEntry(waitlistID, huntID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		return
+	}
+}
+
+// swagger:route POST /hunts/{huntID}/teams/new hunt teams create
+//
+// Creates a team in a hunt that lets players manage their own teams.
+// The current user is added to the team and becomes its owner.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func createOwnTeamHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		team := teams.Team{}
+		e = request.DecodeAndValidate(r, &team)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		e = teams.CreateOwnTeam(huntID, userID, &team)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		events.Publish(huntID, team.ID, events.Team, &events.TeamData{
+			TeamID: team.ID,
+			Action: events.TeamCreated,
+		})
+
+		render.JSON(w, r, &team)
+	}
+}
+
+// swagger:route POST /hunts/{huntID}/balance/preview hunt balance preview
+//
+// Previews distributing the hunt's unassigned players into its teams.
+// The strategy is one of random, balanced, or groups; the groups
+// strategy keeps each group of player ids on the same team. Nothing
+// is changed until the returned assignments are committed.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func previewBalanceHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		req := BalanceRequest{}
+		e = request.DecodeAndValidate(r, &req)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		plan, e := PreviewBalance(huntID, &req)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, plan)
+	}
+}
+
+// swagger:route POST /hunts/{huntID}/balance/ hunt balance commit
+//
+// Commits team assignments, usually ones returned by a preview. All
+// of the assignments are made or, if any fail, none are. Players that are
+// already on another team can not be assigned.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func commitBalanceHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		plan := BalancePlan{}
+		e = request.DecodeAndValidate(r, &plan)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		e = CommitBalance(huntID, &plan)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		events.Publish(huntID, 0, events.Team, &events.TeamData{
+			Action: events.TeamBalanced,
+		})
+
+		players, e := db.GetPlayersForHunt(huntID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, players)
+	}
+}
+
+// swagger:route GET /hunts/{huntID}/flags/ hunt flags
+//
+// Gets the submissions in the hunt that were flagged for being made from
+// outside their item's radius or the hunt's boundary, after impossibly fast
+// travel, or with a photo that looks like another photo uploaded in the hunt,
+// newest first.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func getFlagsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		flags, e := db.GetSubmissionFlagsForHunt(huntID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, flags)
+	}
+}
+
+// swagger:route DELETE /hunts/{huntID}/flags/{flagID} hunt flags dismiss
+//
+// Dismisses the flag once the submission has been reviewed.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func deleteFlagHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		flagID, e := request.GetIntURLParam(r, "flagID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		teamID, e := db.DeleteSubmissionFlag(flagID, huntID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		events.Publish(huntID, teamID, events.Approval, map[string]int{
+			"flagID": flagID,
+			"teamID": teamID,
+		})
+	}
+}
+
+// swagger:route POST /hunts/{huntID}/items/{itemID}/hints/ hint create
+//
+// Adds a hint to the item. Hints are revealed in order, either for free
+// once revealAfter minutes have passed since the hunt started or when a
+// team unlocks them for cost points.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func createHintHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		itemID, e := request.GetIntURLParam(r, "itemID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		hint := db.HintDB{}
+		e = request.DecodeAndValidate(r, &hint)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		e = CreateHint(huntID, itemID, &hint)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, &hint)
+	}
+}
+
+// swagger:route GET /hunts/{huntID}/items/{itemID}/hints/ hint list
+//
+// Gets every hint for the item in the order they are revealed.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func getHintsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		itemID, e := request.GetIntURLParam(r, "itemID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		hints, e := GetHints(huntID, itemID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, hints)
+	}
+}
+
+// swagger:route DELETE /hunts/{huntID}/items/{itemID}/hints/{hintID} hint delete
+//
+// Deletes the hint.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func deleteHintHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		itemID, e := request.GetIntURLParam(r, "itemID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		hintID, e := request.GetIntURLParam(r, "hintID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		e = DeleteHint(huntID, itemID, hintID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+	}
+}
+
+// swagger:route PUT /hunts/{huntID}/items/{itemID}/dependencies item dependencies
+//
+// Sets the items that a team has to complete before the item is visible to
+// it. Dependencies that would make items depend on each other are
+// rejected.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func setDependenciesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		itemID, e := request.GetIntURLParam(r, "itemID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		req := DependenciesRequest{}
+		e = request.DecodeAndValidate(r, &req)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		item, e := SetDependencies(huntID, itemID, req.DependsOn)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, item)
+	}
+}
+
+// maxImportSize is the largest csv file, in bytes, that can be imported
+const maxImportSize = 2 << 20
+
+// swagger:route POST /hunts/{huntID}/items/import items importItemsHandler
+//
+// Creates or updates the hunt's items from the csv file in the request body.
+// Rows are matched to existing items by name. Every row is validated and
+// nothing is saved unless all of them are valid. The errors of each invalid
+// row are prefixed with the row's number.
+//
+// Consumes:
+// 	- text/csv
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func importItemsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		body := http.MaxBytesReader(w, r.Body, maxImportSize)
+		defer body.Close()
+
+		result, e := ImportItemsCSV(huntID, body)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, result)
+	}
+}
+
+// swagger:route GET /hunts/{huntID}/items/export items exportItemsHandler
+//
+// Gets the hunt's items as a csv file that can be edited and imported.
+//
+// Produces:
+//	- text/csv
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func exportItemsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		buf := bytes.Buffer{}
+		e = ExportItemsCSV(huntID, &buf)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set(
+			"Content-Disposition",
+			fmt.Sprintf("attachment; filename=\"hunt-%d-items.csv\"", huntID),
+		)
+		w.Write(buf.Bytes())
+	}
+}
+
+// swagger:route GET /hunts/{huntID}/media/archive media getMediaArchiveHandler
+//
+// Gets every media file submitted in the hunt as a zip archive. The files are
+// organized in a folder for each team with a folder for each item. The
+// archive's manifest.json has when and where each file was submitted and
+// taken, the points of each file's item, and each team's points.
+//
+// Produces:
+//	- application/zip
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func getMediaArchiveHandler(env *config.Env) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		manifest, medias, e := NewMediaArchive(huntID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set(
+			"Content-Disposition",
+			fmt.Sprintf("attachment; filename=\"hunt-%d-media.zip\"", huntID),
+		)
+
+		// the archive is streamed as it is made so once it has started an
+		// error can only be logged
+		e = WriteMediaArchive(w, env.Storage, manifest, medias)
+		if e != nil {
+			log.Printf("error streaming media archive for hunt %d: %s\n", huntID, e.JSON())
+		}
+	}
+}
+
+// swagger:route GET /hunts/{huntID}/media/ media getHuntMediaHandler
+//
+// Gets the media of the hunt with their reactions and how many comments they
+// have. While the hunt is running players only get their own team's media.
+// Once it has ended they get every team's media.
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func getHuntMediaHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		medias, e := GetSharedMedia(huntID, userID, time.Now())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, medias)
+	}
+}
+
+// swagger:route GET /hunts/{huntID}/media/{mediaID}/comments/ media comments getMediaCommentsHandler
+//
+// Gets the comments on the media, oldest first.
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  403:
+//  500:
+func getMediaCommentsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		mediaID, e := request.GetIntURLParam(r, "mediaID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		comments, e := GetMediaComments(huntID, mediaID, userID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, comments)
+	}
+}
+
+// swagger:route POST /hunts/{huntID}/media/{mediaID}/comments/ media comments createMediaCommentHandler
+//
+// Leaves a comment on the media.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  403:
+//  500:
+func createMediaCommentHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		mediaID, e := request.GetIntURLParam(r, "mediaID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		comment := db.MediaCommentDB{}
+		e = request.DecodeAndValidate(r, &comment)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		e = CreateMediaComment(huntID, mediaID, userID, &comment)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, &comment)
+	}
+}
+
+// swagger:route DELETE /hunts/{huntID}/media/{mediaID}/comments/{commentID} media comments deleteMediaCommentHandler
+//
+// Deletes a comment on the media. Users can delete their own comments and
+// hunt owners can delete any comment.
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  403:
+//  500:
+func deleteMediaCommentHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		mediaID, e := request.GetIntURLParam(r, "mediaID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		commentID, e := request.GetIntURLParam(r, "commentID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		e = DeleteMediaComment(huntID, mediaID, commentID, userID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+	}
+}
+
+// swagger:route POST /hunts/{huntID}/media/{mediaID}/reactions/ media reactions createMediaReactionHandler
+//
+// Reacts to the media with an emoji. Reacting with an emoji again does
+// nothing.
+//
+// Consumes:
+// 	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  403:
+//  500:
+func createMediaReactionHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		mediaID, e := request.GetIntURLParam(r, "mediaID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		reaction := db.MediaReactionDB{}
+		e = request.DecodeAndValidate(r, &reaction)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		e = ReactToMedia(huntID, mediaID, userID, &reaction)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+	}
+}
+
+// swagger:route DELETE /hunts/{huntID}/media/{mediaID}/reactions/ media reactions deleteMediaReactionHandler
+//
+// Removes the user's reaction to the media with the emoji given by the
+// emoji query parameter.
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  403:
+//  500:
+func deleteMediaReactionHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		mediaID, e := request.GetIntURLParam(r, "mediaID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		emoji := r.URL.Query().Get("emoji")
+		if emoji == "" {
+			e = response.NewError(http.StatusBadRequest, "emoji: the emoji query parameter is required")
+			e.Handle(w)
+			return
+		}
+
+		e = DeleteMediaReaction(huntID, mediaID, userID, emoji)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+	}
+}
+
+// swagger:route GET /hunts/{huntID}/voting voting getVotingHandler
+//
+// Gets the hunt's best photo voting round, whether it is open, and the
+// media the user voted for. Once the round is finalized the votes each
+// media got and the winning media are included.
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func getVotingHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		status, e := GetVotingStatus(huntID, userID, time.Now())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, status)
+	}
+}
+
+// swagger:route PUT /hunts/{huntID}/voting voting setVotingHandler
+//
+// Sets up the hunt's best photo voting round or changes it until it is
+// finalized. Voting opens when the hunt ends and closes at endsAt. Each
+// player has votesPerPlayer votes and can not vote for their own team's
+// media.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func setVotingHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		round := db.VotingRoundDB{}
+		e = request.DecodeAndValidate(r, &round)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		e = SetVotingRound(huntID, &round)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, &round)
+	}
+}
+
+// swagger:route POST /hunts/{huntID}/voting/finalize voting finalizeVotingHandler
+//
+// Counts the votes once voting has closed and awards the bonus points to
+// the team of each winning media as a score adjustment. Media tied with the
+// last winner win too. A round can only be finalized once.
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func finalizeVotingHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		status, e := FinalizeVoting(huntID, time.Now())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		published := make(map[int]bool)
+		for _, m := range status.WinningMedia {
+			if !published[m.TeamID] {
+				published[m.TeamID] = true
+				events.PublishScore(m.TeamID)
+			}
+		}
+
+		render.JSON(w, r, status)
+	}
+}
+
+// swagger:route POST /hunts/{huntID}/media/{mediaID}/vote voting castVoteHandler
+//
+// Votes for the media as one of the best photos of the hunt.
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  403:
+//  500:
+func castVoteHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		mediaID, e := request.GetIntURLParam(r, "mediaID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		e = CastVote(huntID, mediaID, userID, time.Now())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+	}
+}
+
+// swagger:route DELETE /hunts/{huntID}/media/{mediaID}/vote voting retractVoteHandler
+//
+// Takes back the user's vote for the media while voting is open.
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func retractVoteHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		mediaID, e := request.GetIntURLParam(r, "mediaID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		e = RetractVote(huntID, mediaID, userID, time.Now())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+	}
+}
+
+// swagger:route GET /hunts/{huntID}/events events getEventsHandler
+//
+// Streams the hunt's events as server-sent events: submissions, approvals,
+// score changes, location updates, team changes, and announcements. Players
+// only receive the submissions, approvals, and locations of their own team.
+// Clients that reconnect with the Last-Event-ID header, or that send the
+// lastEventID query parameter, are first sent the events they missed.
+//
+// Produces:
+//	- text/event-stream
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func getEventsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		lastID, e := events.LastEventID(r)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		viewer, e := newMediaViewer(huntID, userID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		visible := func(event *db.HuntEventDB) bool {
+			return events.Visible(event, viewer.teamID, viewer.isOwner)
+		}
+
+		e = events.Stream(w, r, huntID, lastID, visible)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+	}
+}
+
+// swagger:route POST /hunts/{huntID}/announcements/ events createAnnouncementHandler
+//
+// Sends a message to everyone in the hunt through the hunt's event stream.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func createAnnouncementHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		announcement := Announcement{}
+		e = request.DecodeAndValidate(r, &announcement)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		event, e := Announce(huntID, userID, &announcement)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, event)
+	}
+}
+
+// swagger:route GET /hunts/{huntID}/locations/live locations getLiveLocationsHandler
+//
+// Opens a WebSocket connection that receives the location of every team in
+// the hunt as LiveMessages when they are stored, starting with each team's
+// last known location. It is meant for the organizers' live map, so fixes
+// can not be sent over it.
+//
+// Schemes: ws, wss
+//
+// Responses:
+//  101:
+//  400:
+//  403:
+//  500:
+func getLiveLocationsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		conn, e := teams.UpgradeLive(w, r)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		teams.ServeLiveLocations(conn, huntID, 0)
+	}
+}
+
+// swagger:route GET /hunts/{huntID}/locations/export locations exportTracksHandler
+//
+// Exports the track of every team in the hunt, and where their media was
+// submitted, as GeoJSON, GPX, or KML. The format query parameter picks the
+// format, GeoJSON by default. The from and to query parameters, RFC 3339
+// times, limit the export to that range of time.
+//
+// Produces:
+//	- application/geo+json
+//	- application/gpx+xml
+//	- application/vnd.google-earth.kml+xml
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func exportTracksHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		format, e := tracks.Format(r)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		from, to, e := tracks.TimeRange(r)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		hunt, e := db.GetHunt(huntID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		teamDBs, e := db.TeamsForHunt(huntID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		teamNames := make(map[int]string, len(teamDBs))
+		for _, t := range teamDBs {
+			teamNames[t.ID] = t.Name
+		}
+
+		locations, e := db.GetLocationsForHuntBetween(huntID, from, to)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		media, e := db.GetMediaForHunt(huntID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		export := teams.TrackExport(hunt.Name, locations, media, teamNames, from, to)
+		e = tracks.Write(w, format, fmt.Sprintf("hunt-%d-tracks", huntID), export)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+	}
+}
+
+// swagger:route GET /hunts/{huntID}/stats stats getHuntStatsHandler
+//
+// Compares how every team in the hunt moved: the distance each covered, its
+// moving time, its average and fastest speeds, and how long it took to
+// complete each item. The teams that moved the farthest come first. While
+// the hunt is running players only get their own team's stats.
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func getHuntStatsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		stats, e := GetVisibleHuntStats(huntID, userID, time.Now())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, stats)
+	}
+}
+
+// swagger:route GET /hunts/{huntID}/boundary boundary getBoundaryHandler
+//
+// Gets the polygon the hunt is played in and whether submissions made
+// outside it are rejected.
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func getBoundaryHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		boundary, e := db.GetHuntBoundary(huntID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		if boundary == nil {
+			e = response.NewErrorf(
+				http.StatusBadRequest,
+				"hunt_id: hunt %d does not have a boundary",
+				huntID,
+			)
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, boundary)
+	}
+}
+
+// swagger:route PUT /hunts/{huntID}/boundary boundary setBoundaryHandler
+//
+// Sets the polygon the hunt is played in, replacing the one it had. Teams
+// seen outside it raise an alert, and submissions made outside it are
+// flagged, or rejected if rejectSubmissions is set.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func setBoundaryHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		boundary := db.HuntBoundaryDB{}
+		e = request.DecodeAndValidate(r, &boundary)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		boundary.HuntID = huntID
+		e = boundary.Upsert()
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, &boundary)
+	}
+}
+
+// swagger:route DELETE /hunts/{huntID}/boundary boundary deleteBoundaryHandler
+//
+// Removes the hunt's boundary. The alerts already raised are kept.
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func deleteBoundaryHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		e = db.DeleteHuntBoundary(huntID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+	}
+}
+
+// swagger:route GET /hunts/{huntID}/alerts boundary getHuntAlertsHandler
+//
+// Gets the alerts raised when teams in the hunt left its boundary, newest
+// first. Only the alerts of teams that have not returned yet are included
+// if open is true.
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func getHuntAlertsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		huntID, e := request.GetIntURLParam(r, "huntID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		openOnly := false
+		if open := r.URL.Query().Get("open"); open != "" {
+			var err error
+			openOnly, err = strconv.ParseBool(open)
+			if err != nil {
+				e := response.NewError(http.StatusBadRequest, "open parameter must be true or false")
+				e.Handle(w)
+				return
+			}
+		}
+
+		alerts, e := db.GetBoundaryAlertsForHunt(huntID, openOnly)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, alerts)
+	}
+}
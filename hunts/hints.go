@@ -0,0 +1,60 @@
+package hunts
+
+import (
+	"net/http"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// itemInHunt returns an error if the item with the given id is not part of
+// the given hunt
+func itemInHunt(huntID, itemID int) *response.Error {
+	item, e := db.GetItem(itemID)
+	if e != nil {
+		return e
+	}
+
+	if item.HuntID != huntID {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"item_id: item %d is not part of hunt %d",
+			itemID,
+			huntID,
+		)
+	}
+
+	return nil
+}
+
+// CreateHint adds the hint to the given item of the given hunt
+func CreateHint(huntID, itemID int, hint *db.HintDB) *response.Error {
+	e := itemInHunt(huntID, itemID)
+	if e != nil {
+		return e
+	}
+
+	hint.ID = 0
+	hint.ItemID = itemID
+	return hint.Insert()
+}
+
+// GetHints returns every hint for the given item of the given hunt
+func GetHints(huntID, itemID int) ([]*db.HintDB, *response.Error) {
+	e := itemInHunt(huntID, itemID)
+	if e != nil {
+		return nil, e
+	}
+
+	return db.GetHintsForItem(itemID)
+}
+
+// DeleteHint deletes the hint from the given item of the given hunt
+func DeleteHint(huntID, itemID, hintID int) *response.Error {
+	e := itemInHunt(huntID, itemID)
+	if e != nil {
+		return e
+	}
+
+	return db.DeleteHint(hintID, itemID)
+}
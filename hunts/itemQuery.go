@@ -0,0 +1,159 @@
+package hunts
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/hunts/models"
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// itemSorts are the fields the items of a hunt can be sorted by
+var itemSorts = map[string]func(a, b *models.Item) bool{
+	"name": func(a, b *models.Item) bool {
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	},
+	"points": func(a, b *models.Item) bool {
+		return a.Points < b.Points
+	},
+	"difficulty": func(a, b *models.Item) bool {
+		return a.Difficulty < b.Difficulty
+	},
+	"category": func(a, b *models.Item) bool {
+		return strings.ToLower(a.Category) < strings.ToLower(b.Category)
+	},
+}
+
+// itemQuery filters and sorts the items of a hunt
+type itemQuery struct {
+	Category      string
+	Tags          []string
+	Type          string
+	Search        string
+	MinDifficulty int
+	MaxDifficulty int
+	Sort          string
+	Descending    bool
+}
+
+// parseItemQuery builds an itemQuery from the category, tag, type, q,
+// difficulty, minDifficulty, maxDifficulty, and sort query parameters. tag
+// can be repeated and an item must have all of the given tags. sort can be
+// prefixed with - to sort in descending order.
+func parseItemQuery(values url.Values) (*itemQuery, *response.Error) {
+	q := itemQuery{
+		Category: strings.TrimSpace(values.Get("category")),
+		Tags:     db.NormalizeTags(values["tag"]),
+		Type:     values.Get("type"),
+		Search:   strings.ToLower(strings.TrimSpace(values.Get("q"))),
+	}
+	e := response.NewNilError()
+
+	parseDifficulty := func(key string) int {
+		str := values.Get(key)
+		if str == "" {
+			return 0
+		}
+
+		d, err := strconv.Atoi(str)
+		if err != nil || d < 1 || d > 5 {
+			e.Addf(http.StatusBadRequest, "%s: must be a number between 1 and 5", key)
+			return 0
+		}
+
+		return d
+	}
+
+	q.MinDifficulty = parseDifficulty("minDifficulty")
+	q.MaxDifficulty = parseDifficulty("maxDifficulty")
+	if d := parseDifficulty("difficulty"); d != 0 {
+		q.MinDifficulty, q.MaxDifficulty = d, d
+	}
+
+	if q.MinDifficulty != 0 && q.MaxDifficulty != 0 && q.MinDifficulty > q.MaxDifficulty {
+		e.Add(http.StatusBadRequest, "minDifficulty: must not be greater than maxDifficulty")
+	}
+
+	switch q.Type {
+	case "", db.ItemTypePhoto, db.ItemTypeVideo, db.ItemTypeText, db.ItemTypeCheckIn, db.ItemTypeGPS:
+	default:
+		e.Addf(http.StatusBadRequest, "type: %s is not an item type", q.Type)
+	}
+
+	if str := values.Get("sort"); str != "" {
+		q.Descending = strings.HasPrefix(str, "-")
+		q.Sort = strings.TrimPrefix(str, "-")
+		if _, ok := itemSorts[q.Sort]; !ok {
+			e.Add(http.StatusBadRequest, "sort: must be one of name, points, difficulty, or category")
+		}
+	}
+
+	return &q, e.GetError()
+}
+
+// matches returns whether or not the given item passes the query's filters
+func (q *itemQuery) matches(item *models.Item) bool {
+	if q.Category != "" && !strings.EqualFold(q.Category, item.Category) {
+		return false
+	}
+
+	if q.Type != "" && q.Type != item.Type {
+		return false
+	}
+
+	if q.MinDifficulty != 0 && item.Difficulty < q.MinDifficulty {
+		return false
+	}
+
+	if q.MaxDifficulty != 0 && (item.Difficulty == 0 || item.Difficulty > q.MaxDifficulty) {
+		return false
+	}
+
+	if q.Search != "" && !strings.Contains(strings.ToLower(item.Name), q.Search) {
+		return false
+	}
+
+	for _, tag := range q.Tags {
+		found := false
+		for _, t := range item.Tags {
+			if t == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// apply returns the items that match the query in the query's order. Items
+// that compare equal keep their original order.
+func (q *itemQuery) apply(items []*models.Item) []*models.Item {
+	filtered := make([]*models.Item, 0, len(items))
+	for _, item := range items {
+		if q.matches(item) {
+			filtered = append(filtered, item)
+		}
+	}
+
+	less, ok := itemSorts[q.Sort]
+	if !ok {
+		return filtered
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		if q.Descending {
+			return less(filtered[j], filtered[i])
+		}
+		return less(filtered[i], filtered[j])
+	})
+
+	return filtered
+}
@@ -0,0 +1,117 @@
+// +build unit
+
+package hunts
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/hunts/models"
+)
+
+func newItem(id int, name, category string, points, difficulty int, tags ...string) *models.Item {
+	return &models.Item{ItemDB: db.ItemDB{
+		ID:         id,
+		Name:       name,
+		Category:   category,
+		Points:     points,
+		Difficulty: difficulty,
+		Tags:       tags,
+		Type:       db.ItemTypePhoto,
+	}}
+}
+
+func itemIDs(items []*models.Item) []int {
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+
+	return ids
+}
+
+func TestItemQueryApply(t *testing.T) {
+	items := []*models.Item{
+		newItem(1, "Fountain", "landmarks", 10, 2, "park"),
+		newItem(2, "Statue", "landmarks", 30, 4, "park", "night"),
+		newItem(3, "Red Door", "colors", 20, 1),
+		newItem(4, "Bench", "", 5, 0, "park"),
+	}
+
+	cases := []struct {
+		name     string
+		query    string
+		expected []int
+	}{
+		{name: "no query", query: "", expected: []int{1, 2, 3, 4}},
+		{name: "category", query: "category=Landmarks", expected: []int{1, 2}},
+		{name: "one tag", query: "tag=park", expected: []int{1, 2, 4}},
+		{name: "all tags", query: "tag=park&tag=NIGHT", expected: []int{2}},
+		{name: "min difficulty", query: "minDifficulty=2", expected: []int{1, 2}},
+		{name: "max difficulty", query: "maxDifficulty=2", expected: []int{1, 3}},
+		{name: "difficulty", query: "difficulty=4", expected: []int{2}},
+		{name: "search", query: "q=door", expected: []int{3}},
+		{name: "type", query: "type=photo", expected: []int{1, 2, 3, 4}},
+		{name: "no items of type", query: "type=video", expected: []int{}},
+		{name: "sort by points", query: "sort=points", expected: []int{4, 1, 3, 2}},
+		{name: "sort by points descending", query: "sort=-points", expected: []int{2, 3, 1, 4}},
+		{name: "sort by name", query: "sort=name", expected: []int{4, 1, 3, 2}},
+		{name: "sort by category", query: "sort=category", expected: []int{4, 3, 1, 2}},
+		{name: "filter and sort", query: "tag=park&sort=-difficulty", expected: []int{2, 1, 4}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			values, err := url.ParseQuery(c.query)
+			if err != nil {
+				t.Fatalf("error parsing query: %v", err)
+			}
+
+			q, e := parseItemQuery(values)
+			if e != nil {
+				t.Fatalf("expected no error got %s", e.JSON())
+			}
+
+			got := itemIDs(q.apply(items))
+			if len(got) != len(c.expected) {
+				t.Fatalf("expected %v got %v", c.expected, got)
+			}
+			for i := range got {
+				if got[i] != c.expected[i] {
+					t.Fatalf("expected %v got %v", c.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseItemQueryErrors(t *testing.T) {
+	cases := []struct {
+		query string
+		key   string
+	}{
+		{query: "difficulty=6", key: "difficulty"},
+		{query: "minDifficulty=abc", key: "minDifficulty"},
+		{query: "minDifficulty=4&maxDifficulty=2", key: "minDifficulty"},
+		{query: "type=audio", key: "type"},
+		{query: "sort=-created", key: "sort"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			values, err := url.ParseQuery(c.query)
+			if err != nil {
+				t.Fatalf("error parsing query: %v", err)
+			}
+
+			_, e := parseItemQuery(values)
+			if e == nil {
+				t.Fatalf("expected a %s error got nil", c.key)
+			}
+			if _, ok := e.ErrorsByKey()[c.key]; !ok {
+				t.Errorf("expected a %s error got %s", c.key, e.JSON())
+			}
+		})
+	}
+}
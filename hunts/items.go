@@ -1,10 +1,15 @@
 package hunts
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/cljohnson4343/scavenge/config"
 	"github.com/cljohnson4343/scavenge/db"
 	"github.com/cljohnson4343/scavenge/hunts/models"
 	"github.com/cljohnson4343/scavenge/response"
+	"github.com/cljohnson4343/scavenge/roles"
+	"github.com/cljohnson4343/scavenge/users"
 )
 
 // GetItems returns the items for the given hunt
@@ -40,6 +45,42 @@ func GetItemsForHunt(huntID int) ([]*models.Item, *response.Error) {
 	return items, e
 }
 
+// checkSecretFields returns an error if a user that is not one of the hunt's
+// owners sets any of the fields that decide how the item is claimed. Players
+// can edit items, and an answer or code of their choosing would let them
+// claim the item without finding it.
+func checkSecretFields(item *models.Item, isOwner bool) *response.Error {
+	if isOwner {
+		return nil
+	}
+
+	keys := item.SecretKeys()
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return response.NewErrorf(
+		http.StatusForbidden,
+		"%s: only the hunt's owners can set an item's answers, check in code, or target point",
+		keys[0],
+	)
+}
+
+// RequireSecretAccess returns an error if the user is not allowed to set the
+// item's secret fields, see checkSecretFields
+func RequireSecretAccess(huntID, userID int, item *models.Item) *response.Error {
+	if len(item.SecretKeys()) == 0 {
+		return nil
+	}
+
+	isOwner, e := roles.UserHasRole("hunt_owner", huntID, userID)
+	if e != nil {
+		return e
+	}
+
+	return checkSecretFields(item, isOwner)
+}
+
 // InsertItem inserts an Item into the db
 func InsertItem(item *models.Item) *response.Error {
 	return item.Insert()
@@ -55,3 +96,59 @@ func DeleteItem(env *config.Env, huntID, itemID int) *response.Error {
 func UpdateItem(env *config.Env, item *models.Item) *response.Error {
 	return item.Update(env)
 }
+
+// VisibleItems returns the items the user can see. Hunt owners see every
+// item. Everyone else only sees the items that are visible to their team, see
+// db.ItemDB.VisibleTo, and never sees the answers, codes, or target points.
+func VisibleItems(huntID, userID int, items []*models.Item) ([]*models.Item, *response.Error) {
+	isOwner, e := roles.UserHasRole("hunt_owner", huntID, userID)
+	if e != nil {
+		return nil, e
+	}
+
+	if isOwner {
+		return items, nil
+	}
+
+	teamID, e := db.TeamIDForPlayer(huntID, userID)
+	if e != nil {
+		return nil, e
+	}
+
+	completed := make(map[int]bool)
+	if teamID != 0 {
+		completed, e = db.GetCompletedItemIDs(teamID)
+		if e != nil {
+			return nil, e
+		}
+	}
+
+	visible := visibleItems(items, completed, time.Now())
+	for _, item := range visible {
+		item.HideSecrets()
+	}
+
+	return visible, nil
+}
+
+// showVisibleItems replaces the items of each hunt with the ones the user
+// making the request can see
+func showVisibleItems(r *http.Request, hunts ...*Hunt) *response.Error {
+	userID, e := users.GetUserID(r.Context())
+	if e != nil {
+		return e
+	}
+
+	for _, h := range hunts {
+		if h == nil {
+			continue
+		}
+
+		h.Items, e = VisibleItems(h.ID, userID, h.Items)
+		if e != nil {
+			return e
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,342 @@
+package hunts
+
+import (
+	"encoding/csv"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// itemCSVColumns are the columns of an item csv file in the order they are
+// exported. Only name is required when importing.
+var itemCSVColumns = []string{
+	"name",
+	"points",
+	"type",
+	"category",
+	"tags",
+	"difficulty",
+	"answers",
+	"checkInCode",
+	"latitude",
+	"longitude",
+	"radius",
+	"enforceGeofence",
+	"visibleFrom",
+	"visibleUntil",
+}
+
+const (
+	// csvListSeparator separates the values of the tags and answers columns
+	csvListSeparator = "|"
+
+	// maxImportRows is the most items a single csv file can import
+	maxImportRows = 1000
+)
+
+// ImportResult is the outcome of an item import
+type ImportResult struct {
+	// the number of items that were created
+	Created int `json:"created"`
+
+	// the number of existing items that were replaced
+	Updated int `json:"updated"`
+}
+
+// parseCSVHeader maps each column of the header to its index. Column names
+// are not case sensitive.
+func parseCSVHeader(header []string) (map[string]int, *response.Error) {
+	known := make(map[string]string, len(itemCSVColumns))
+	for _, c := range itemCSVColumns {
+		known[strings.ToLower(c)] = c
+	}
+
+	e := response.NewNilError()
+	columns := make(map[string]int, len(header))
+	for idx, h := range header {
+		c, ok := known[strings.ToLower(strings.TrimSpace(h))]
+		if !ok {
+			e.Addf(http.StatusBadRequest, "header: %s is not an item column", h)
+			continue
+		}
+
+		if _, ok = columns[c]; ok {
+			e.Addf(http.StatusBadRequest, "header: the %s column is repeated", c)
+			continue
+		}
+
+		columns[c] = idx
+	}
+
+	if _, ok := columns["name"]; !ok {
+		e.Add(http.StatusBadRequest, "header: the name column is required")
+	}
+
+	return columns, e.GetError()
+}
+
+// splitCSVList splits a tags or answers cell into its values
+func splitCSVList(cell string) []string {
+	if strings.TrimSpace(cell) == "" {
+		return nil
+	}
+
+	values := strings.Split(cell, csvListSeparator)
+	for i, v := range values {
+		values[i] = strings.TrimSpace(v)
+	}
+
+	return values
+}
+
+// parseCSVItem builds an item for the given hunt from a csv record
+func parseCSVItem(huntID int, columns map[string]int, record []string) (*db.ItemDB, *response.Error) {
+	item := db.ItemDB{HuntID: huntID}
+	e := response.NewNilError()
+
+	for c, idx := range columns {
+		if idx >= len(record) {
+			continue
+		}
+
+		cell := strings.TrimSpace(record[idx])
+		if cell == "" {
+			continue
+		}
+
+		var err error
+		switch c {
+		case "name":
+			item.Name = cell
+		case "points":
+			item.Points, err = strconv.Atoi(cell)
+		case "type":
+			item.Type = strings.ToLower(cell)
+		case "category":
+			item.Category = cell
+		case "tags":
+			item.Tags = db.NormalizeTags(splitCSVList(cell))
+		case "difficulty":
+			item.Difficulty, err = strconv.Atoi(cell)
+		case "answers":
+			item.Answers = splitCSVList(cell)
+		case "checkInCode":
+			item.CheckInCode = cell
+		case "latitude":
+			var lat float64
+			lat, err = strconv.ParseFloat(cell, 32)
+			item.Latitude = float32(lat)
+		case "longitude":
+			var lng float64
+			lng, err = strconv.ParseFloat(cell, 32)
+			item.Longitude = float32(lng)
+		case "radius":
+			item.Radius, err = strconv.Atoi(cell)
+		case "enforceGeofence":
+			var enforce bool
+			enforce, err = strconv.ParseBool(cell)
+			item.EnforceGeofence = &enforce
+		case "visibleFrom":
+			item.VisibleFrom, err = time.Parse(time.RFC3339, cell)
+		case "visibleUntil":
+			item.VisibleUntil, err = time.Parse(time.RFC3339, cell)
+		}
+
+		if err != nil {
+			e.Addf(http.StatusBadRequest, "%s: %s is not a valid value", c, cell)
+		}
+	}
+
+	if e.GetError() != nil {
+		return nil, e
+	}
+
+	if strings.TrimSpace(item.Name) == "" {
+		return nil, response.NewError(http.StatusBadRequest, "name: every item needs a name")
+	}
+
+	if vErr := item.Validate(nil); vErr != nil {
+		return nil, vErr
+	}
+
+	return &item, nil
+}
+
+// ParseItemsCSV reads the items for the given hunt from a csv file. The first
+// record is the header. Every row is validated and the returned error holds
+// the errors of every invalid row, each prefixed with the row's number.
+func ParseItemsCSV(huntID int, r io.Reader) ([]*db.ItemDB, *response.Error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, response.NewError(http.StatusBadRequest, "header: the file is empty")
+	}
+	if err != nil {
+		return nil, response.NewErrorf(http.StatusBadRequest, "header: %v", err)
+	}
+
+	columns, e := parseCSVHeader(header)
+	if e != nil {
+		return nil, e
+	}
+
+	e = response.NewNilError()
+	items := make([]*db.ItemDB, 0)
+	rowForName := make(map[string]int)
+
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			e.Addf(http.StatusBadRequest, "row %d: %v", row, err)
+			break
+		}
+
+		if len(items) >= maxImportRows {
+			e.Addf(http.StatusBadRequest, "row %d: a file can import at most %d items", row, maxImportRows)
+			break
+		}
+
+		item, rowErr := parseCSVItem(huntID, columns, record)
+		if rowErr != nil {
+			rowErr.Prefix("row " + strconv.Itoa(row) + ": ")
+			e.AddError(rowErr)
+			continue
+		}
+
+		key := strings.ToLower(item.Name)
+		if prev, ok := rowForName[key]; ok {
+			e.Addf(http.StatusBadRequest, "row %d: name: %s is already used on row %d", row, item.Name, prev)
+			continue
+		}
+		rowForName[key] = row
+
+		items = append(items, item)
+	}
+
+	if e.GetError() != nil {
+		return nil, e
+	}
+
+	if len(items) == 0 {
+		return nil, response.NewError(http.StatusBadRequest, "rows: the file does not have any items")
+	}
+
+	return items, nil
+}
+
+// formatCSVCoordinate formats a coordinate cell, leaving 0 blank
+func formatCSVCoordinate(f float32) string {
+	if f == 0 {
+		return ""
+	}
+
+	return strconv.FormatFloat(float64(f), 'f', -1, 32)
+}
+
+// formatCSVTime formats a time cell, leaving the zero time blank
+func formatCSVTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+// itemCSVRecord returns the csv record for the given item
+func itemCSVRecord(item *db.ItemDB) []string {
+	var difficulty, radius, enforceGeofence string
+	if item.Difficulty != 0 {
+		difficulty = strconv.Itoa(item.Difficulty)
+	}
+	if item.Radius != 0 {
+		radius = strconv.Itoa(item.Radius)
+	}
+	if item.EnforceGeofence != nil {
+		enforceGeofence = strconv.FormatBool(*item.EnforceGeofence)
+	}
+
+	return []string{
+		item.Name,
+		strconv.Itoa(item.Points),
+		item.Type,
+		item.Category,
+		strings.Join(item.Tags, csvListSeparator),
+		difficulty,
+		strings.Join(item.Answers, csvListSeparator),
+		item.CheckInCode,
+		formatCSVCoordinate(item.Latitude),
+		formatCSVCoordinate(item.Longitude),
+		radius,
+		enforceGeofence,
+		formatCSVTime(item.VisibleFrom),
+		formatCSVTime(item.VisibleUntil),
+	}
+}
+
+// WriteItemsCSV writes the given items as a csv file that can be imported
+func WriteItemsCSV(w io.Writer, items []*db.ItemDB) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write(itemCSVColumns)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		err = writer.Write(itemCSVRecord(item))
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ImportItemsCSV creates or updates the items of the given hunt from a csv
+// file. Rows are matched to existing items by name. Nothing is saved unless
+// every row is valid.
+func ImportItemsCSV(huntID int, r io.Reader) (*ImportResult, *response.Error) {
+	items, e := ParseItemsCSV(huntID, r)
+	if e != nil {
+		return nil, e
+	}
+
+	created, updated, e := db.ImportItems(items)
+	if e != nil {
+		return nil, e
+	}
+
+	return &ImportResult{Created: created, Updated: updated}, nil
+}
+
+// ExportItemsCSV writes the items of the given hunt as a csv file
+func ExportItemsCSV(huntID int, w io.Writer) *response.Error {
+	items, e := db.GetItemsWithHuntID(huntID)
+	if items == nil {
+		return e
+	}
+
+	err := WriteItemsCSV(w, items)
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error writing items for hunt %d: %v",
+			huntID,
+			err,
+		)
+	}
+
+	return e
+}
@@ -0,0 +1,156 @@
+// +build unit
+
+package hunts
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+)
+
+func TestParseItemsCSV(t *testing.T) {
+	file := `Name,Points,Type,Category,Tags,Difficulty,Answers,Latitude,Longitude,Radius
+Fountain,10,,landmarks,Park|night,2,,,,
+Riddle,20,text,puzzles,,3,a map| the map,,,
+Checkpoint,30,gps,,,,,40.5,-73.25,25
+`
+	items, e := ParseItemsCSV(7, strings.NewReader(file))
+	if e != nil {
+		t.Fatalf("expected no error got %s", e.JSON())
+	}
+
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items got %d", len(items))
+	}
+
+	fountain := items[0]
+	if fountain.HuntID != 7 || fountain.Name != "Fountain" || fountain.Points != 10 {
+		t.Errorf("unexpected item %+v", fountain)
+	}
+	if fountain.Category != "landmarks" || fountain.Difficulty != 2 {
+		t.Errorf("unexpected category or difficulty %+v", fountain)
+	}
+	if len(fountain.Tags) != 2 || fountain.Tags[0] != "park" || fountain.Tags[1] != "night" {
+		t.Errorf("expected tags [park night] got %v", fountain.Tags)
+	}
+
+	riddle := items[1]
+	if riddle.Type != db.ItemTypeText || len(riddle.Answers) != 2 || riddle.Answers[1] != "the map" {
+		t.Errorf("unexpected text item %+v", riddle)
+	}
+
+	checkpoint := items[2]
+	if checkpoint.Latitude != 40.5 || checkpoint.Longitude != -73.25 || checkpoint.Radius != 25 {
+		t.Errorf("unexpected gps item %+v", checkpoint)
+	}
+}
+
+func TestParseItemsCSVRowErrors(t *testing.T) {
+	file := `name,points,type,difficulty
+Fountain,ten,,
+,5,,
+Riddle,20,text,
+Statue,5,,9
+Bench,5,,
+bench,5,,
+`
+	_, e := ParseItemsCSV(7, strings.NewReader(file))
+	if e == nil {
+		t.Fatalf("expected an error got nil")
+	}
+
+	errs := e.ErrorsByKey()
+	for _, row := range []string{"row 2", "row 3", "row 4", "row 5", "row 7"} {
+		if _, ok := errs[row]; !ok {
+			t.Errorf("expected an error for %s got %s", row, e.JSON())
+		}
+	}
+	if _, ok := errs["row 6"]; ok {
+		t.Errorf("expected no error for row 6 got %s", e.JSON())
+	}
+}
+
+func TestParseItemsCSVHeaderErrors(t *testing.T) {
+	cases := map[string]string{
+		"empty":          "",
+		"missing name":   "points\n5\n",
+		"unknown column": "name,colour\nFountain,red\n",
+		"repeated":       "name,Name\nFountain,Fountain\n",
+	}
+
+	for name, file := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, e := ParseItemsCSV(7, strings.NewReader(file))
+			if e == nil {
+				t.Fatalf("expected an error got nil")
+			}
+			if _, ok := e.ErrorsByKey()["header"]; !ok {
+				t.Errorf("expected a header error got %s", e.JSON())
+			}
+		})
+	}
+
+	_, e := ParseItemsCSV(7, strings.NewReader("name\n"))
+	if e == nil {
+		t.Errorf("expected an error for a file without items")
+	}
+}
+
+func TestItemsCSVRoundTrip(t *testing.T) {
+	enforce := true
+	from := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	items := []*db.ItemDB{
+		{
+			Name:            "Fountain, North",
+			Points:          10,
+			Type:            db.ItemTypePhoto,
+			Category:        "landmarks",
+			Tags:            []string{"park", "night"},
+			Difficulty:      2,
+			Latitude:        40.5,
+			Longitude:       -73.25,
+			Radius:          50,
+			EnforceGeofence: &enforce,
+			VisibleFrom:     from,
+		},
+		{
+			Name:        "Secret",
+			Points:      5,
+			Type:        db.ItemTypeCheckIn,
+			CheckInCode: "owl",
+		},
+	}
+
+	buf := bytes.Buffer{}
+	if err := WriteItemsCSV(&buf, items); err != nil {
+		t.Fatalf("error writing csv: %v", err)
+	}
+
+	parsed, e := ParseItemsCSV(3, &buf)
+	if e != nil {
+		t.Fatalf("expected no error got %s", e.JSON())
+	}
+	if len(parsed) != len(items) {
+		t.Fatalf("expected %d items got %d", len(items), len(parsed))
+	}
+
+	for i, item := range items {
+		item.HuntID = 3
+		got := itemCSVRecord(parsed[i])
+		expected := itemCSVRecord(item)
+		for c := range expected {
+			if got[c] != expected[c] {
+				t.Errorf(
+					"expected %s of item %d to be %q got %q",
+					itemCSVColumns[c],
+					i,
+					expected[c],
+					got[c],
+				)
+			}
+		}
+	}
+}
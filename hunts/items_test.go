@@ -0,0 +1,73 @@
+// +build unit
+
+package hunts
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/hunts/models"
+)
+
+func TestCheckSecretFields(t *testing.T) {
+	enforce := true
+
+	cases := []struct {
+		name    string
+		item    db.ItemDB
+		isOwner bool
+		allowed bool
+	}{
+		{
+			name:    "editor sets name and points",
+			item:    db.ItemDB{Name: "item", Points: 10},
+			allowed: true,
+		},
+		{
+			name: "editor sets answers",
+			item: db.ItemDB{Type: "text", Answers: []string{"answer"}},
+		},
+		{
+			name: "editor sets check in code",
+			item: db.ItemDB{Type: "checkin", CheckInCode: "QR43"},
+		},
+		{
+			name: "editor sets target point",
+			item: db.ItemDB{Latitude: 40.7, Longitude: -74},
+		},
+		{
+			name: "editor sets radius",
+			item: db.ItemDB{Radius: 25},
+		},
+		{
+			name: "editor sets geofence",
+			item: db.ItemDB{EnforceGeofence: &enforce},
+		},
+		{
+			name:    "owner sets answers and check in code",
+			item:    db.ItemDB{Answers: []string{"answer"}, CheckInCode: "QR43"},
+			isOwner: true,
+			allowed: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e := checkSecretFields(&models.Item{ItemDB: c.item}, c.isOwner)
+			if c.allowed {
+				if e != nil {
+					t.Errorf("expected no error but got %s", e.JSON())
+				}
+				return
+			}
+
+			if e == nil {
+				t.Fatalf("expected an error")
+			}
+			if e.Code() != http.StatusForbidden {
+				t.Errorf("expected status %d got %d", http.StatusForbidden, e.Code())
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package hunts
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/joincode"
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/spf13/viper"
+)
+
+const (
+	// defaultInviteLinkBase is prepended to invite tokens to build invite links
+	// when the invite_link_base config value is not set
+	defaultInviteLinkBase = "/join/"
+)
+
+// A JoinCode is a code that players can redeem to join a hunt. The token
+// and link can be shared in place of the code.
+//
+// swagger:model JoinCode
+type JoinCode struct {
+	db.HuntJoinCodeDB `valid:"-"`
+
+	// the signed token that can be redeemed in place of the code
+	Token string `json:"token" valid:"-"`
+
+	// the shareable invite link for the code
+	Link string `json:"link" valid:"-"`
+}
+
+// RedeemRequest is the body of a join request. Either a code or a token
+// from an invite link must be given.
+type RedeemRequest struct {
+	Code  string `json:"code" valid:"-"`
+	Token string `json:"token" valid:"-"`
+}
+
+// Validate validates the redeem request
+func (req *RedeemRequest) Validate(r *http.Request) *response.Error {
+	if req.Code == "" && req.Token == "" {
+		return response.NewError(
+			http.StatusBadRequest,
+			"code: either a code or a token is required",
+		)
+	}
+
+	return nil
+}
+
+// getInviteSecret returns the key used to sign invite tokens, set by the
+// invite_secret config value. There is no fallback since links signed with
+// a generated key would stop working when the process restarts.
+func getInviteSecret() ([]byte, *response.Error) {
+	secret := viper.GetString("invite_secret")
+	if secret == "" {
+		return nil, response.NewError(
+			http.StatusInternalServerError,
+			"invite_secret: the invite_secret config value must be set to use invite links",
+		)
+	}
+
+	return []byte(secret), nil
+}
+
+// signInviteToken returns a token for the join code with the given id and
+// code. The token is the code's id and a signature of the id and code.
+func signInviteToken(secret []byte, id int, code string) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%d:%s", id, code)
+
+	return fmt.Sprintf(
+		"%d.%s",
+		id,
+		base64.RawURLEncoding.EncodeToString(mac.Sum(nil)),
+	)
+}
+
+// parseInviteToken returns the join code id the token was signed for
+func parseInviteToken(token string) (int, *response.Error) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return 0, response.NewError(http.StatusBadRequest, "token: invalid token")
+	}
+
+	id, err := strconv.Atoi(parts[0])
+	if err != nil || id < 1 {
+		return 0, response.NewError(http.StatusBadRequest, "token: invalid token")
+	}
+
+	return id, nil
+}
+
+// verifyInviteToken returns whether or not the token was signed for the
+// join code with the given id and code
+func verifyInviteToken(secret []byte, token string, id int, code string) bool {
+	expected := signInviteToken(secret, id, code)
+
+	return hmac.Equal([]byte(token), []byte(expected))
+}
+
+// newJoinCode wraps the db join code with its token and invite link
+func newJoinCode(c *db.HuntJoinCodeDB) (*JoinCode, *response.Error) {
+	secret, e := getInviteSecret()
+	if e != nil {
+		return nil, e
+	}
+
+	base := viper.GetString("invite_link_base")
+	if base == "" {
+		base = defaultInviteLinkBase
+	}
+
+	token := signInviteToken(secret, c.ID, c.Code)
+	return &JoinCode{
+		HuntJoinCodeDB: *c,
+		Token:          token,
+		Link:           base + token,
+	}, nil
+}
+
+// CreateJoinCode generates a code for the given join code and stores it
+func CreateJoinCode(c *db.HuntJoinCodeDB) (*JoinCode, *response.Error) {
+	var e *response.Error
+	for i := 0; i < joincode.MaxAttempts; i++ {
+		code, err := joincode.Generate()
+		if err != nil {
+			return nil, response.NewErrorf(
+				http.StatusInternalServerError,
+				"error generating join code: %v",
+				err,
+			)
+		}
+
+		c.Code = code
+		e = c.Insert()
+		if e == nil {
+			return newJoinCode(c)
+		}
+
+		// only retry if the generated code collided with an existing one
+		if _, ok := e.ErrorsByKey()["code"]; !ok {
+			return nil, e
+		}
+	}
+
+	return nil, e
+}
+
+// GetJoinCodes returns the join codes for the given hunt
+func GetJoinCodes(huntID int) ([]*JoinCode, *response.Error) {
+	dbCodes, e := db.GetJoinCodesForHunt(huntID)
+
+	codes := make([]*JoinCode, 0, len(dbCodes))
+	for _, c := range dbCodes {
+		code, codeErr := newJoinCode(c)
+		if codeErr != nil {
+			return nil, codeErr
+		}
+
+		codes = append(codes, code)
+	}
+
+	return codes, e
+}
+
+// resolveJoinCode returns the join code described by the redeem request
+func resolveJoinCode(req *RedeemRequest) (*db.HuntJoinCodeDB, *response.Error) {
+	if req.Token == "" {
+		return db.GetHuntJoinCodeByCode(joincode.Normalize(req.Code))
+	}
+
+	id, e := parseInviteToken(req.Token)
+	if e != nil {
+		return nil, e
+	}
+
+	c, e := db.GetHuntJoinCode(id)
+	if e != nil {
+		return nil, response.NewError(http.StatusBadRequest, "token: invalid token")
+	}
+
+	secret, e := getInviteSecret()
+	if e != nil {
+		return nil, e
+	}
+
+	if !verifyInviteToken(secret, req.Token, c.ID, c.Code) {
+		return nil, response.NewError(http.StatusBadRequest, "token: invalid token")
+	}
+
+	return c, nil
+}
+
+// RedeemJoinCode adds the user to the hunt the code or token in the redeem
+// request is for. The hunt is returned on success.
+func RedeemJoinCode(req *RedeemRequest, userID int) (*Hunt, *response.Error) {
+	c, e := resolveJoinCode(req)
+	if e != nil {
+		return nil, e
+	}
+
+	isPlayer, e := db.IsHuntPlayer(c.HuntID, userID)
+	if e != nil {
+		return nil, e
+	}
+	if isPlayer {
+		return nil, response.NewErrorf(
+			http.StatusBadRequest,
+			"code: you have already joined hunt %d",
+			c.HuntID,
+		)
+	}
+
+	redeemed, e := db.RedeemHuntJoinCode(c.Code)
+	if e != nil {
+		return nil, e
+	}
+
+	player := db.PlayerDB{
+		UserDB: db.UserDB{
+			ID: userID,
+		},
+	}
+	e = AddPlayer(redeemed.HuntID, &player)
+	if e != nil {
+		// give the use back since the user never joined
+		releaseErr := db.ReleaseHuntJoinCode(redeemed.ID)
+		if releaseErr != nil {
+			e.AddError(releaseErr)
+		}
+		return nil, e
+	}
+
+	return GetHunt(redeemed.HuntID)
+}
@@ -0,0 +1,79 @@
+// +build unit
+
+package hunts
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestInviteToken(t *testing.T) {
+	secret := []byte("secret")
+	token := signInviteToken(secret, 43, "ABCDEFGH")
+
+	id, e := parseInviteToken(token)
+	if e != nil {
+		t.Fatalf("expected no error but got %s", e.JSON())
+	}
+	if id != 43 {
+		t.Errorf("expected id 43 got %d", id)
+	}
+
+	if !verifyInviteToken(secret, token, 43, "ABCDEFGH") {
+		t.Errorf("expected token %s to be valid", token)
+	}
+
+	cases := []struct {
+		name   string
+		secret []byte
+		id     int
+		code   string
+	}{
+		{name: "wrong secret", secret: []byte("other"), id: 43, code: "ABCDEFGH"},
+		{name: "wrong id", secret: secret, id: 44, code: "ABCDEFGH"},
+		{name: "wrong code", secret: secret, id: 43, code: "ABCDEFGJ"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if verifyInviteToken(c.secret, token, c.id, c.code) {
+				t.Errorf("expected token %s to be invalid", token)
+			}
+		})
+	}
+}
+
+func TestParseInviteTokenInvalid(t *testing.T) {
+	for _, token := range []string{"", "abc", "abc.def", "0.def", "-1.def"} {
+		t.Run(token, func(t *testing.T) {
+			_, e := parseInviteToken(token)
+			if e == nil {
+				t.Errorf("expected an error for token %q", token)
+			}
+		})
+	}
+}
+
+func TestInviteSecretRequired(t *testing.T) {
+	defer viper.Set("invite_secret", viper.GetString("invite_secret"))
+
+	viper.Set("invite_secret", "")
+	_, e := getInviteSecret()
+	if e == nil {
+		t.Fatalf("expected an error when invite_secret is not set")
+	}
+	if e.Code() != http.StatusInternalServerError {
+		t.Errorf("expected status %d got %d", http.StatusInternalServerError, e.Code())
+	}
+
+	viper.Set("invite_secret", "secret")
+	secret, e := getInviteSecret()
+	if e != nil {
+		t.Fatalf("expected no error but got %s", e.JSON())
+	}
+	if string(secret) != "secret" {
+		t.Errorf("expected secret %q got %q", "secret", secret)
+	}
+}
@@ -0,0 +1,208 @@
+package hunts
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/cljohnson4343/scavenge/roles"
+)
+
+// SharedMedia is a media file as the players of its hunt see it, along
+// with what they have said about it
+//
+// swagger:model SharedMedia
+type SharedMedia struct {
+	*db.HuntMediaDB
+
+	// the reactions to the media, most used first
+	Reactions []*db.ReactionCountDB `json:"reactions"`
+
+	// the number of comments on the media
+	Comments int `json:"comments"`
+}
+
+// mediaViewer is a user looking at the media of a hunt
+type mediaViewer struct {
+	// the id of the user
+	userID int
+
+	// the id of the user's team in the hunt, 0 if the user is not on one
+	teamID int
+
+	// whether or not the user owns the hunt
+	isOwner bool
+}
+
+// newMediaViewer returns the user as a viewer of the hunt's media
+func newMediaViewer(huntID, userID int) (*mediaViewer, *response.Error) {
+	isOwner, e := roles.UserHasRole("hunt_owner", huntID, userID)
+	if e != nil {
+		return nil, e
+	}
+
+	teamID, e := db.TeamIDForPlayer(huntID, userID)
+	if e != nil {
+		return nil, e
+	}
+
+	return &mediaViewer{userID: userID, teamID: teamID, isOwner: isOwner}, nil
+}
+
+// canSee returns whether or not the viewer can see media uploaded by the
+// given team. While the hunt is running players only see their own team's
+// media so photos do not give away where items are. Everyone sees every
+// team's media once the hunt has ended.
+func (v *mediaViewer) canSee(hunt *db.HuntDB, teamID int, now time.Time) bool {
+	return v.isOwner || (v.teamID != 0 && v.teamID == teamID) || !now.Before(hunt.EndTime)
+}
+
+// viewableMedia returns an error if the media is not part of the hunt or
+// the user can not see it yet
+func viewableMedia(huntID, mediaID, userID int, now time.Time) (*mediaViewer, *response.Error) {
+	mediaHuntID, teamID, e := db.GetMediaHunt(mediaID)
+	if e != nil {
+		return nil, e
+	}
+
+	if mediaHuntID != huntID {
+		return nil, response.NewErrorf(
+			http.StatusBadRequest,
+			"media_id: media %d is not part of hunt %d",
+			mediaID,
+			huntID,
+		)
+	}
+
+	hunt, e := db.GetHunt(huntID)
+	if e != nil {
+		return nil, e
+	}
+
+	viewer, e := newMediaViewer(huntID, userID)
+	if e != nil {
+		return nil, e
+	}
+
+	if !viewer.canSee(hunt, teamID, now) {
+		return nil, response.NewErrorf(
+			http.StatusForbidden,
+			"media_id: media %d can not be seen until hunt %d ends",
+			mediaID,
+			huntID,
+		)
+	}
+
+	return viewer, nil
+}
+
+// GetSharedMedia returns the media of the hunt the user can see, with their
+// reactions and comment counts. The EXIF capture details are only shown to
+// the hunt's owners.
+func GetSharedMedia(huntID, userID int, now time.Time) ([]*SharedMedia, *response.Error) {
+	hunt, e := db.GetHunt(huntID)
+	if e != nil {
+		return nil, e
+	}
+
+	viewer, e := newMediaViewer(huntID, userID)
+	if e != nil {
+		return nil, e
+	}
+
+	medias, e := db.GetMediaForHunt(huntID)
+	if e != nil {
+		return nil, e
+	}
+
+	reactions, e := db.GetReactionCountsForHunt(huntID, userID)
+	if e != nil {
+		return nil, e
+	}
+
+	comments, e := db.GetCommentCountsForHunt(huntID)
+	if e != nil {
+		return nil, e
+	}
+
+	shared := make([]*SharedMedia, 0, len(medias))
+	for _, m := range medias {
+		if !viewer.canSee(hunt, m.TeamID, now) {
+			continue
+		}
+
+		if !viewer.isOwner {
+			m.HideCaptureDetails()
+		}
+
+		s := SharedMedia{
+			HuntMediaDB: m,
+			Reactions:   reactions[m.ID],
+			Comments:    comments[m.ID],
+		}
+		if s.Reactions == nil {
+			s.Reactions = make([]*db.ReactionCountDB, 0)
+		}
+
+		shared = append(shared, &s)
+	}
+
+	return shared, nil
+}
+
+// GetMediaComments returns the comments on the media of the hunt
+func GetMediaComments(huntID, mediaID, userID int) ([]*db.MediaCommentDB, *response.Error) {
+	_, e := viewableMedia(huntID, mediaID, userID, time.Now())
+	if e != nil {
+		return nil, e
+	}
+
+	return db.GetCommentsForMedia(mediaID)
+}
+
+// CreateMediaComment leaves the user's comment on the media of the hunt
+func CreateMediaComment(huntID, mediaID, userID int, comment *db.MediaCommentDB) *response.Error {
+	_, e := viewableMedia(huntID, mediaID, userID, time.Now())
+	if e != nil {
+		return e
+	}
+
+	comment.ID = 0
+	comment.MediaID = mediaID
+	comment.UserID = userID
+	return comment.Insert()
+}
+
+// DeleteMediaComment deletes the comment from the media of the hunt. Users
+// can delete their own comments and the hunt's owners can delete any.
+func DeleteMediaComment(huntID, mediaID, commentID, userID int) *response.Error {
+	viewer, e := viewableMedia(huntID, mediaID, userID, time.Now())
+	if e != nil {
+		return e
+	}
+
+	return db.DeleteMediaComment(commentID, mediaID, userID, viewer.isOwner)
+}
+
+// ReactToMedia adds the user's reaction to the media of the hunt
+func ReactToMedia(huntID, mediaID, userID int, reaction *db.MediaReactionDB) *response.Error {
+	_, e := viewableMedia(huntID, mediaID, userID, time.Now())
+	if e != nil {
+		return e
+	}
+
+	reaction.MediaID = mediaID
+	reaction.UserID = userID
+	return reaction.Insert()
+}
+
+// DeleteMediaReaction removes the user's reaction from the media of the hunt
+func DeleteMediaReaction(huntID, mediaID, userID int, emoji string) *response.Error {
+	_, e := viewableMedia(huntID, mediaID, userID, time.Now())
+	if e != nil {
+		return e
+	}
+
+	return db.DeleteMediaReaction(mediaID, userID, emoji)
+}
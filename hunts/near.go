@@ -0,0 +1,99 @@
+package hunts
+
+import (
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+const (
+	// defaultNearRadius is the search radius, in meters, used when a location
+	// search does not provide one
+	defaultNearRadius float64 = 10000
+
+	// maxNearRadius is the largest search radius, in meters, that is supported
+	maxNearRadius float64 = 500000
+
+	// defaultNearLimit is the number of hunts returned when a location search
+	// does not provide a limit
+	defaultNearLimit int = 50
+
+	// maxNearLimit is the largest number of hunts a location search can return
+	maxNearLimit int = 200
+)
+
+// nearQuery is a search for the hunts around a point
+type nearQuery struct {
+	Latitude  float64
+	Longitude float64
+	Radius    float64
+	Limit     int
+}
+
+// isNearQuery returns whether or not the given query values describe a
+// location search
+func isNearQuery(values url.Values) bool {
+	return values.Get("latitude") != "" || values.Get("longitude") != ""
+}
+
+// parseFinite parses a number that is not NaN or infinite, which ParseFloat
+// accepts and which pass every range check
+func parseFinite(str string) (float64, bool) {
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+
+	return f, true
+}
+
+// parseNearQuery builds a nearQuery from the latitude, longitude, radius, and
+// limit query parameters. latitude and longitude are required, radius is in
+// meters.
+func parseNearQuery(values url.Values) (*nearQuery, *response.Error) {
+	q := nearQuery{Radius: defaultNearRadius, Limit: defaultNearLimit}
+	e := response.NewNilError()
+
+	var ok bool
+	q.Latitude, ok = parseFinite(values.Get("latitude"))
+	if !ok || q.Latitude < -90 || q.Latitude > 90 {
+		e.Add(http.StatusBadRequest, "latitude: must be a number between -90 and 90")
+	}
+
+	q.Longitude, ok = parseFinite(values.Get("longitude"))
+	if !ok || q.Longitude < -180 || q.Longitude > 180 {
+		e.Add(http.StatusBadRequest, "longitude: must be a number between -180 and 180")
+	}
+
+	if str := values.Get("radius"); str != "" {
+		q.Radius, ok = parseFinite(str)
+		if !ok || q.Radius <= 0 || q.Radius > maxNearRadius {
+			e.Addf(
+				http.StatusBadRequest,
+				"radius: must be a number of meters greater than 0 and at most %.0f",
+				maxNearRadius,
+			)
+		}
+	}
+
+	if str := values.Get("limit"); str != "" {
+		var err error
+		q.Limit, err = strconv.Atoi(str)
+		if err != nil || q.Limit < 1 || q.Limit > maxNearLimit {
+			e.Addf(
+				http.StatusBadRequest,
+				"limit: must be a number between 1 and %d",
+				maxNearLimit,
+			)
+		}
+	}
+
+	if e.GetError() != nil {
+		return nil, e
+	}
+
+	return &q, nil
+}
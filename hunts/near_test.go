@@ -0,0 +1,113 @@
+// +build unit
+
+package hunts
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParseNearQuery(t *testing.T) {
+	cases := []struct {
+		name     string
+		values   url.Values
+		valid    bool
+		expected nearQuery
+	}{
+		{
+			name:   "defaults",
+			values: url.Values{"latitude": {"34.730705"}, "longitude": {"-86.59481"}},
+			valid:  true,
+			expected: nearQuery{
+				Latitude:  34.730705,
+				Longitude: -86.59481,
+				Radius:    defaultNearRadius,
+				Limit:     defaultNearLimit,
+			},
+		},
+		{
+			name: "radius and limit",
+			values: url.Values{
+				"latitude":  {"34.730705"},
+				"longitude": {"-86.59481"},
+				"radius":    {"2500"},
+				"limit":     {"10"},
+			},
+			valid: true,
+			expected: nearQuery{
+				Latitude:  34.730705,
+				Longitude: -86.59481,
+				Radius:    2500,
+				Limit:     10,
+			},
+		},
+		{
+			name:   "missing longitude",
+			values: url.Values{"latitude": {"34.730705"}},
+		},
+		{
+			name:   "latitude out of range",
+			values: url.Values{"latitude": {"91"}, "longitude": {"-86.59481"}},
+		},
+		{
+			name:   "latitude not a number",
+			values: url.Values{"latitude": {"NaN"}, "longitude": {"-86.59481"}},
+		},
+		{
+			name:   "infinite longitude",
+			values: url.Values{"latitude": {"34.730705"}, "longitude": {"Inf"}},
+		},
+		{
+			name: "radius not a number",
+			values: url.Values{
+				"latitude":  {"34.730705"},
+				"longitude": {"-86.59481"},
+				"radius":    {"NaN"},
+			},
+		},
+		{
+			name: "negative radius",
+			values: url.Values{
+				"latitude":  {"34.730705"},
+				"longitude": {"-86.59481"},
+				"radius":    {"-1"},
+			},
+		},
+		{
+			name: "radius too large",
+			values: url.Values{
+				"latitude":  {"34.730705"},
+				"longitude": {"-86.59481"},
+				"radius":    {"500001"},
+			},
+		},
+		{
+			name: "limit too large",
+			values: url.Values{
+				"latitude":  {"34.730705"},
+				"longitude": {"-86.59481"},
+				"limit":     {"201"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			q, e := parseNearQuery(c.values)
+			if !c.valid {
+				if e == nil {
+					t.Fatalf("expected an error but got %+v", q)
+				}
+				return
+			}
+
+			if e != nil {
+				t.Fatalf("expected no error but got %s", e.JSON())
+			}
+
+			if *q != c.expected {
+				t.Errorf("expected %+v got %+v", c.expected, *q)
+			}
+		})
+	}
+}
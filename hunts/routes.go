@@ -44,6 +44,7 @@ func Routes(env *config.Env) *chi.Mux {
 	router.Delete("/{huntID}", deleteHuntHandler(env)) // tested
 	router.Patch("/{huntID}", patchHuntHandler(env))
 	router.Post("/populate/", populateDBHandler(env))
+	router.Post("/join/", redeemJoinCodeHandler())
 
 	// /hunts/{huntID}/items routes
 	router.Get("/{huntID}/items/", getItemsHandler(env))
@@ -51,6 +52,13 @@ func Routes(env *config.Env) *chi.Mux {
 	router.Post("/{huntID}/items/", createItemHandler(env))
 	router.Patch("/{huntID}/items/{itemID}", patchItemHandler(env))
 
+	router.Post("/{huntID}/items/{itemID}/hints/", createHintHandler())
+	router.Get("/{huntID}/items/{itemID}/hints/", getHintsHandler())
+	router.Delete("/{huntID}/items/{itemID}/hints/{hintID}", deleteHintHandler())
+	router.Put("/{huntID}/items/{itemID}/dependencies", setDependenciesHandler())
+	router.Post("/{huntID}/items/import", importItemsHandler())
+	router.Get("/{huntID}/items/export", exportItemsHandler())
+
 	router.Get("/{huntID}/players/", getHuntPlayersHandler())
 	router.Post("/{huntID}/players/", addHuntPlayerHandler())
 	router.Delete("/{huntID}/players/{playerID}", removeHuntPlayerHandler())
@@ -67,5 +75,47 @@ func Routes(env *config.Env) *chi.Mux {
 		declineHuntInvitationHandler(),
 	)
 
+	router.Post("/{huntID}/codes/", createJoinCodeHandler())
+	router.Get("/{huntID}/codes/", getJoinCodesHandler())
+	router.Delete("/{huntID}/codes/{codeID}", deleteJoinCodeHandler())
+
+	router.Post("/{huntID}/waitlist/", joinWaitlistHandler())
+	router.Get("/{huntID}/waitlist/", getWaitlistHandler())
+	router.Delete("/{huntID}/waitlist/{waitlistID}", leaveWaitlistHandler())
+
+	router.Post("/{huntID}/teams/new", createOwnTeamHandler())
+
+	router.Post("/{huntID}/balance/preview", previewBalanceHandler())
+	router.Post("/{huntID}/balance/", commitBalanceHandler())
+
+	router.Get("/{huntID}/flags/", getFlagsHandler())
+	router.Delete("/{huntID}/flags/{flagID}", deleteFlagHandler())
+
+	router.Get("/{huntID}/media/archive", getMediaArchiveHandler(env))
+	router.Get("/{huntID}/media/", getHuntMediaHandler())
+	router.Get("/{huntID}/media/{mediaID}/comments/", getMediaCommentsHandler())
+	router.Post("/{huntID}/media/{mediaID}/comments/", createMediaCommentHandler())
+	router.Delete("/{huntID}/media/{mediaID}/comments/{commentID}", deleteMediaCommentHandler())
+	router.Post("/{huntID}/media/{mediaID}/reactions/", createMediaReactionHandler())
+	router.Delete("/{huntID}/media/{mediaID}/reactions/", deleteMediaReactionHandler())
+	router.Post("/{huntID}/media/{mediaID}/vote", castVoteHandler())
+	router.Delete("/{huntID}/media/{mediaID}/vote", retractVoteHandler())
+
+	router.Get("/{huntID}/voting", getVotingHandler())
+	router.Put("/{huntID}/voting", setVotingHandler())
+	router.Post("/{huntID}/voting/finalize", finalizeVotingHandler())
+
+	router.Get("/{huntID}/events", getEventsHandler())
+	router.Post("/{huntID}/announcements/", createAnnouncementHandler())
+
+	router.Get("/{huntID}/locations/live", getLiveLocationsHandler())
+	router.Get("/{huntID}/locations/export", exportTracksHandler())
+	router.Get("/{huntID}/stats", getHuntStatsHandler())
+
+	router.Get("/{huntID}/boundary", getBoundaryHandler())
+	router.Put("/{huntID}/boundary", setBoundaryHandler())
+	router.Delete("/{huntID}/boundary", deleteBoundaryHandler())
+	router.Get("/{huntID}/alerts", getHuntAlertsHandler())
+
 	return router
 }
@@ -0,0 +1,44 @@
+package hunts
+
+import (
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/cljohnson4343/scavenge/teams"
+)
+
+// visibleStats returns the stats of the teams the viewer can see. Like
+// media, other teams' stats are hidden from players while the hunt is
+// running so their items and times do not give away where items are.
+func visibleStats(viewer *mediaViewer, hunt *db.HuntDB, stats []*teams.TeamStats, now time.Time) []*teams.TeamStats {
+	visible := make([]*teams.TeamStats, 0, len(stats))
+	for _, s := range stats {
+		if viewer.canSee(hunt, s.TeamID, now) {
+			visible = append(visible, s)
+		}
+	}
+
+	return visible
+}
+
+// GetVisibleHuntStats returns the stats of the teams in the hunt with the
+// given id that the user with the given id can see
+func GetVisibleHuntStats(huntID, userID int, now time.Time) ([]*teams.TeamStats, *response.Error) {
+	hunt, e := db.GetHunt(huntID)
+	if e != nil {
+		return nil, e
+	}
+
+	viewer, e := newMediaViewer(huntID, userID)
+	if e != nil {
+		return nil, e
+	}
+
+	stats, e := teams.GetHuntStats(huntID)
+	if e != nil {
+		return nil, e
+	}
+
+	return visibleStats(viewer, hunt, stats, now), nil
+}
@@ -0,0 +1,42 @@
+// +build unit
+
+package hunts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/teams"
+)
+
+func TestVisibleStats(t *testing.T) {
+	now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	running := &db.HuntDB{StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour)}
+	ended := &db.HuntDB{StartTime: now.Add(-2 * time.Hour), EndTime: now.Add(-time.Hour)}
+	stats := []*teams.TeamStats{{TeamID: 1}, {TeamID: 2}}
+
+	cases := []struct {
+		name     string
+		viewer   mediaViewer
+		hunt     *db.HuntDB
+		expected int
+	}{
+		{name: "player while running", viewer: mediaViewer{teamID: 1}, hunt: running, expected: 1},
+		{name: "player without a team while running", viewer: mediaViewer{}, hunt: running, expected: 0},
+		{name: "owner while running", viewer: mediaViewer{isOwner: true}, hunt: running, expected: 2},
+		{name: "player after the hunt", viewer: mediaViewer{teamID: 1}, hunt: ended, expected: 2},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := visibleStats(&c.viewer, c.hunt, stats, now)
+			if len(got) != c.expected {
+				t.Fatalf("expected %d teams got %d", c.expected, len(got))
+			}
+			if c.expected == 1 && got[0].TeamID != c.viewer.teamID {
+				t.Errorf("expected the stats of team %d got team %d", c.viewer.teamID, got[0].TeamID)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package hunts
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/hunts/models"
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// DependenciesRequest is the body of a request to set the items an item
+// depends on
+type DependenciesRequest struct {
+
+	// the ids of the items that have to be completed first. An empty list
+	// removes every dependency.
+	//
+	// required: true
+	DependsOn []int `json:"dependsOn" valid:"-"`
+}
+
+// Validate validates the dependencies request
+func (req *DependenciesRequest) Validate(r *http.Request) *response.Error {
+	seen := make(map[int]bool, len(req.DependsOn))
+	for _, id := range req.DependsOn {
+		if seen[id] {
+			return response.NewErrorf(
+				http.StatusBadRequest,
+				"dependsOn: item %d is listed more than once",
+				id,
+			)
+		}
+		seen[id] = true
+	}
+
+	return nil
+}
+
+// visibleItems returns the items a team that has completed the given items
+// can see at the given time
+func visibleItems(items []*models.Item, completed map[int]bool, now time.Time) []*models.Item {
+	visible := make([]*models.Item, 0, len(items))
+	for _, item := range items {
+		if item.VisibleTo(completed, now) {
+			visible = append(visible, item)
+		}
+	}
+
+	return visible
+}
+
+// findCycle returns the ids of the items in a dependency cycle, starting and
+// ending with the same item, or nil if there is none. deps maps each item to
+// the items it depends on.
+func findCycle(deps map[int][]int) []int {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+
+	state := make(map[int]int, len(deps))
+	path := make([]int, 0, len(deps))
+
+	var visit func(id int) []int
+	visit = func(id int) []int {
+		state[id] = visiting
+		path = append(path, id)
+
+		for _, dep := range deps[id] {
+			switch state[dep] {
+			case visiting:
+				// the cycle is the part of the path from dep back to dep
+				for i, p := range path {
+					if p == dep {
+						cycle := append([]int{}, path[i:]...)
+						return append(cycle, dep)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[id] = done
+		return nil
+	}
+
+	// visit the items in order so the same cycle is always reported
+	ids := make([]int, 0, len(deps))
+	for id := range deps {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		if state[id] == unvisited {
+			if cycle := visit(id); cycle != nil {
+				return cycle
+			}
+		}
+	}
+
+	return nil
+}
+
+// SetDependencies replaces the items the given item depends on. Dependencies
+// that would keep an item hidden forever, i.e. cycles, are rejected.
+func SetDependencies(huntID, itemID int, dependsOn []int) (*db.ItemDB, *response.Error) {
+	e := itemInHunt(huntID, itemID)
+	if e != nil {
+		return nil, e
+	}
+
+	items, e := db.GetItemsWithHuntID(huntID)
+	if e != nil {
+		return nil, e
+	}
+
+	deps := make(map[int][]int, len(items))
+	for _, item := range items {
+		deps[item.ID] = item.DependsOn
+	}
+	deps[itemID] = dependsOn
+
+	if cycle := findCycle(deps); cycle != nil {
+		strs := make([]string, 0, len(cycle))
+		for _, id := range cycle {
+			strs = append(strs, fmt.Sprintf("%d", id))
+		}
+
+		return nil, response.NewErrorf(
+			http.StatusBadRequest,
+			"dependsOn: the items would depend on each other, %s",
+			strings.Join(strs, " -> "),
+		)
+	}
+
+	e = db.SetItemDependencies(itemID, dependsOn)
+	if e != nil {
+		return nil, e
+	}
+
+	return db.GetItem(itemID)
+}
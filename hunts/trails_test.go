@@ -0,0 +1,96 @@
+// +build unit
+
+package hunts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/hunts/models"
+)
+
+func TestFindCycle(t *testing.T) {
+	cases := []struct {
+		name  string
+		deps  map[int][]int
+		cycle []int
+	}{
+		{name: "no items", deps: map[int][]int{}},
+		{name: "no dependencies", deps: map[int][]int{1: nil, 2: nil}},
+		{name: "trail", deps: map[int][]int{1: nil, 2: {1}, 3: {2}, 4: {2, 3}}},
+		{name: "two items", deps: map[int][]int{1: {2}, 2: {1}}, cycle: []int{1, 2, 1}},
+		{name: "self", deps: map[int][]int{1: {1}}, cycle: []int{1, 1}},
+		{
+			name:  "cycle after a trail",
+			deps:  map[int][]int{1: {2}, 2: {3}, 3: {4}, 4: {2}},
+			cycle: []int{2, 3, 4, 2},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := findCycle(c.deps)
+			if len(got) != len(c.cycle) {
+				t.Fatalf("expected cycle %v got %v", c.cycle, got)
+			}
+
+			for i := range got {
+				if got[i] != c.cycle[i] {
+					t.Fatalf("expected cycle %v got %v", c.cycle, got)
+				}
+			}
+		})
+	}
+}
+
+func TestVisibleItems(t *testing.T) {
+	now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	items := []*models.Item{
+		{ItemDB: db.ItemDB{ID: 1}},
+		{ItemDB: db.ItemDB{ID: 2, DependsOn: []int{1}}},
+		{ItemDB: db.ItemDB{ID: 3, VisibleFrom: now.Add(time.Hour)}},
+		{ItemDB: db.ItemDB{ID: 4, VisibleFrom: now.Add(-time.Hour), VisibleUntil: now.Add(time.Hour)}},
+	}
+
+	cases := []struct {
+		name      string
+		completed map[int]bool
+		visible   []int
+	}{
+		{name: "nothing completed", completed: map[int]bool{}, visible: []int{1, 4}},
+		{name: "first item completed", completed: map[int]bool{1: true}, visible: []int{1, 2, 4}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := visibleItems(items, c.completed, now)
+			if len(got) != len(c.visible) {
+				t.Fatalf("expected %d items got %d", len(c.visible), len(got))
+			}
+
+			for i, item := range got {
+				if item.ID != c.visible[i] {
+					t.Errorf("expected item %d got %d", c.visible[i], item.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestDependenciesRequestValidate(t *testing.T) {
+	req := DependenciesRequest{DependsOn: []int{1, 2}}
+	if e := req.Validate(nil); e != nil {
+		t.Errorf("expected no error got %s", e.JSON())
+	}
+
+	req = DependenciesRequest{DependsOn: []int{}}
+	if e := req.Validate(nil); e != nil {
+		t.Errorf("expected no error for clearing dependencies got %s", e.JSON())
+	}
+
+	req = DependenciesRequest{DependsOn: []int{1, 2, 1}}
+	if e := req.Validate(nil); e == nil {
+		t.Errorf("expected an error for a repeated dependency")
+	}
+}
@@ -0,0 +1,274 @@
+package hunts
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// VotingStatus is a hunt's best photo voting round as a player sees it
+//
+// swagger:model VotingStatus
+type VotingStatus struct {
+	*db.VotingRoundDB
+
+	// whether or not votes can be cast right now
+	Open bool `json:"open"`
+
+	// the ids of the media the user voted for
+	Votes []int `json:"votes"`
+
+	// the number of votes the user has left
+	VotesLeft int `json:"votesLeft"`
+
+	// the votes each media got, most first, once the round is finalized
+	Results []*db.VoteTallyDB `json:"results,omitempty"`
+
+	// the media that won the bonus, once the round is finalized
+	WinningMedia []*db.VoteTallyDB `json:"winningMedia,omitempty"`
+}
+
+// votingOpen returns an error if votes can not be cast in the round at the
+// given time. Voting opens when the hunt ends and closes at the round's end
+// or when the round is finalized.
+func votingOpen(hunt *db.HuntDB, round *db.VotingRoundDB, now time.Time) *response.Error {
+	if round.Finalized() {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"voting: the voting round for hunt %d has been finalized",
+			hunt.ID,
+		)
+	}
+
+	if now.Before(hunt.EndTime) {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"voting: voting opens when hunt %d ends at %s",
+			hunt.ID,
+			hunt.EndTime.Format(time.RFC3339),
+		)
+	}
+
+	if !now.Before(round.EndsAt) {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"voting: voting for hunt %d closed at %s",
+			hunt.ID,
+			round.EndsAt.Format(time.RFC3339),
+		)
+	}
+
+	return nil
+}
+
+// pickWinners returns the media that win a voting round with the given
+// number of winners. The tallies must be ordered by votes, most first. Media
+// tied with the last winner win too, so a tie never comes down to the order
+// the media were uploaded in.
+func pickWinners(tallies []*db.VoteTallyDB, winners int) []*db.VoteTallyDB {
+	picked := make([]*db.VoteTallyDB, 0)
+	if winners < 1 {
+		return picked
+	}
+
+	for i, t := range tallies {
+		if t.Votes < 1 {
+			break
+		}
+
+		if i >= winners && t.Votes < tallies[winners-1].Votes {
+			break
+		}
+
+		picked = append(picked, t)
+	}
+
+	return picked
+}
+
+// bonusAdjustments returns the score adjustments that award the bonus to
+// the team of each winning media
+func bonusAdjustments(winners []*db.VoteTallyDB, bonus int) []*db.ScoreAdjustmentDB {
+	adjustments := make([]*db.ScoreAdjustmentDB, 0, len(winners))
+	for _, w := range winners {
+		adjustments = append(adjustments, &db.ScoreAdjustmentDB{
+			TeamID:  w.TeamID,
+			Points:  bonus,
+			Reason:  fmt.Sprintf("best photo: media %d got %d votes", w.MediaID, w.Votes),
+			MediaID: w.MediaID,
+		})
+	}
+
+	return adjustments
+}
+
+// SetVotingRound sets up the hunt's voting round, or changes it if its
+// votes have not been counted
+func SetVotingRound(huntID int, round *db.VotingRoundDB) *response.Error {
+	hunt, e := db.GetHunt(huntID)
+	if e != nil {
+		return e
+	}
+
+	if !round.EndsAt.After(hunt.EndTime) {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"endsAt: voting must close after hunt %d ends at %s",
+			huntID,
+			hunt.EndTime.Format(time.RFC3339),
+		)
+	}
+
+	round.HuntID = huntID
+	return round.Upsert()
+}
+
+// GetVotingStatus returns the hunt's voting round along with the user's
+// votes. The results are included once the round has been finalized.
+func GetVotingStatus(huntID, userID int, now time.Time) (*VotingStatus, *response.Error) {
+	hunt, e := db.GetHunt(huntID)
+	if e != nil {
+		return nil, e
+	}
+
+	round, e := db.GetVotingRound(huntID)
+	if e != nil {
+		return nil, e
+	}
+
+	votes, e := db.GetMediaVotesByUser(huntID, userID)
+	if e != nil {
+		return nil, e
+	}
+
+	status := VotingStatus{
+		VotingRoundDB: round,
+		Open:          votingOpen(hunt, round, now) == nil,
+		Votes:         votes,
+		VotesLeft:     round.VotesPerPlayer - len(votes),
+	}
+	if status.VotesLeft < 0 || round.Finalized() {
+		status.VotesLeft = 0
+	}
+
+	if round.Finalized() {
+		status.Results, e = db.GetVoteTallies(huntID)
+		if e != nil {
+			return nil, e
+		}
+
+		status.WinningMedia = pickWinners(status.Results, round.Winners)
+	}
+
+	return &status, nil
+}
+
+// CastVote records the user's vote for the media in the hunt's voting
+// round. Players can not vote for their own team's media.
+func CastVote(huntID, mediaID, userID int, now time.Time) *response.Error {
+	hunt, e := db.GetHunt(huntID)
+	if e != nil {
+		return e
+	}
+
+	round, e := db.GetVotingRound(huntID)
+	if e != nil {
+		return e
+	}
+
+	e = votingOpen(hunt, round, now)
+	if e != nil {
+		return e
+	}
+
+	viewer, e := viewableMedia(huntID, mediaID, userID, now)
+	if e != nil {
+		return e
+	}
+
+	_, teamID, e := db.GetMediaHunt(mediaID)
+	if e != nil {
+		return e
+	}
+
+	if viewer.teamID != 0 && viewer.teamID == teamID {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"media_id: media %d was uploaded by your own team",
+			mediaID,
+		)
+	}
+
+	return db.InsertMediaVote(huntID, mediaID, userID)
+}
+
+// RetractVote takes back the user's vote for the media in the hunt's
+// voting round
+func RetractVote(huntID, mediaID, userID int, now time.Time) *response.Error {
+	hunt, e := db.GetHunt(huntID)
+	if e != nil {
+		return e
+	}
+
+	round, e := db.GetVotingRound(huntID)
+	if e != nil {
+		return e
+	}
+
+	e = votingOpen(hunt, round, now)
+	if e != nil {
+		return e
+	}
+
+	return db.DeleteMediaVote(mediaID, userID)
+}
+
+// FinalizeVoting counts the votes of the hunt's voting round, once voting
+// has closed, and awards the bonus to the team of each winning media
+func FinalizeVoting(huntID int, now time.Time) (*VotingStatus, *response.Error) {
+	round, e := db.GetVotingRound(huntID)
+	if e != nil {
+		return nil, e
+	}
+
+	if round.Finalized() {
+		return nil, response.NewErrorf(
+			http.StatusBadRequest,
+			"voting: the voting round for hunt %d has been finalized",
+			huntID,
+		)
+	}
+
+	if now.Before(round.EndsAt) {
+		return nil, response.NewErrorf(
+			http.StatusBadRequest,
+			"voting: voting for hunt %d is open until %s",
+			huntID,
+			round.EndsAt.Format(time.RFC3339),
+		)
+	}
+
+	tallies, e := db.GetVoteTallies(huntID)
+	if e != nil {
+		return nil, e
+	}
+
+	winners := pickWinners(tallies, round.Winners)
+	round.FinalizedAt, e = db.FinalizeVotingRound(
+		huntID,
+		bonusAdjustments(winners, round.BonusPoints),
+	)
+	if e != nil {
+		return nil, e
+	}
+
+	return &VotingStatus{
+		VotingRoundDB: round,
+		Votes:         make([]int, 0),
+		Results:       tallies,
+		WinningMedia:  winners,
+	}, nil
+}
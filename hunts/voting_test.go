@@ -0,0 +1,127 @@
+// +build unit
+
+package hunts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+)
+
+func tallies(votes ...int) []*db.VoteTallyDB {
+	t := make([]*db.VoteTallyDB, 0, len(votes))
+	for i, v := range votes {
+		t = append(t, &db.VoteTallyDB{MediaID: i + 1, TeamID: 10 + i, Votes: v})
+	}
+	return t
+}
+
+func TestPickWinners(t *testing.T) {
+	cases := []struct {
+		name    string
+		tallies []*db.VoteTallyDB
+		winners int
+		media   []int
+	}{
+		{"no votes", tallies(), 3, []int{}},
+		{"fewer media than winners", tallies(4, 2), 3, []int{1, 2}},
+		{"top three", tallies(9, 7, 5, 3, 1), 3, []int{1, 2, 3}},
+		{"tie with the last winner", tallies(9, 5, 5, 5, 1), 2, []int{1, 2, 3, 4}},
+		{"tie above the last winner", tallies(5, 5, 3, 1), 2, []int{1, 2}},
+		{"media without votes", tallies(3, 0, 0), 3, []int{1}},
+		{"no winners", tallies(3, 2), 0, []int{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := pickWinners(c.tallies, c.winners)
+			if len(got) != len(c.media) {
+				t.Fatalf("expected %d winners got %d", len(c.media), len(got))
+			}
+
+			for i, w := range got {
+				if w.MediaID != c.media[i] {
+					t.Errorf("expected winner %d to be media %d got %d", i, c.media[i], w.MediaID)
+				}
+			}
+		})
+	}
+}
+
+func TestBonusAdjustments(t *testing.T) {
+	adjustments := bonusAdjustments(tallies(4, 2), 25)
+	if len(adjustments) != 2 {
+		t.Fatalf("expected 2 adjustments got %d", len(adjustments))
+	}
+
+	for i, a := range adjustments {
+		if a.TeamID != 10+i || a.MediaID != i+1 || a.Points != 25 {
+			t.Errorf("expected team %d to get 25 points for media %d got %+v", 10+i, i+1, a)
+		}
+		if a.Reason == "" {
+			t.Errorf("expected adjustment %d to have a reason", i)
+		}
+	}
+}
+
+func TestVotingOpen(t *testing.T) {
+	end := time.Date(2019, 6, 1, 18, 0, 0, 0, time.UTC)
+	hunt := db.HuntDB{ID: 1, EndTime: end}
+	round := db.VotingRoundDB{HuntID: 1, EndsAt: end.Add(2 * time.Hour)}
+	finalized := round
+	finalized.FinalizedAt = end.Add(time.Hour)
+
+	cases := []struct {
+		name  string
+		round *db.VotingRoundDB
+		now   time.Time
+		open  bool
+	}{
+		{"during the hunt", &round, end.Add(-time.Minute), false},
+		{"when the hunt ends", &round, end, true},
+		{"before voting closes", &round, end.Add(time.Hour), true},
+		{"when voting closes", &round, end.Add(2 * time.Hour), false},
+		{"finalized", &finalized, end.Add(time.Hour), false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e := votingOpen(&hunt, c.round, c.now)
+			if c.open && e != nil {
+				t.Errorf("expected voting to be open got %s", e.JSON())
+			}
+			if !c.open && e == nil {
+				t.Errorf("expected voting to be closed")
+			}
+		})
+	}
+}
+
+func TestMediaViewerCanSee(t *testing.T) {
+	end := time.Date(2019, 6, 1, 18, 0, 0, 0, time.UTC)
+	hunt := db.HuntDB{ID: 1, EndTime: end}
+	during, after := end.Add(-time.Hour), end.Add(time.Hour)
+
+	cases := []struct {
+		name   string
+		viewer mediaViewer
+		teamID int
+		now    time.Time
+		canSee bool
+	}{
+		{"own team during the hunt", mediaViewer{userID: 1, teamID: 2}, 2, during, true},
+		{"other team during the hunt", mediaViewer{userID: 1, teamID: 2}, 3, during, false},
+		{"no team during the hunt", mediaViewer{userID: 1}, 3, during, false},
+		{"owner during the hunt", mediaViewer{userID: 1, isOwner: true}, 3, during, true},
+		{"other team after the hunt", mediaViewer{userID: 1, teamID: 2}, 3, after, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.viewer.canSee(&hunt, c.teamID, c.now); got != c.canSee {
+				t.Errorf("expected %v got %v", c.canSee, got)
+			}
+		})
+	}
+}
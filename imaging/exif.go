@@ -0,0 +1,330 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+)
+
+// EXIF is the metadata of a photo that the app cares about
+type EXIF struct {
+	// Orientation is the EXIF orientation, 1 through 8. 1 means the pixels
+	// are stored the way the photo is displayed.
+	Orientation int
+
+	// TakenAt is when the photo was taken. EXIF times do not have a time
+	// zone so the camera's local time is read as UTC.
+	TakenAt time.Time
+
+	// HasLocation is whether or not the photo has GPS coordinates
+	HasLocation bool
+
+	// Latitude is where the photo was taken
+	Latitude float64
+
+	// Longitude is where the photo was taken
+	Longitude float64
+}
+
+// errBadEXIF is returned for EXIF data that can not be read
+var errBadEXIF = errors.New("imaging: malformed exif data")
+
+// the EXIF tags that are read
+const (
+	tagOrientation      = 0x0112
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagDateTimeOriginal = 0x9003
+	tagGPSLatitudeRef   = 0x0001
+	tagGPSLatitude      = 0x0002
+	tagGPSLongitudeRef  = 0x0003
+	tagGPSLongitude     = 0x0004
+)
+
+// exifTimeLayout is the layout of EXIF date times
+const exifTimeLayout = "2006:01:02 15:04:05"
+
+// typeSizes maps the TIFF field types to the size of one of their values
+var typeSizes = map[uint16]uint32{
+	1:  1, // BYTE
+	2:  1, // ASCII
+	3:  2, // SHORT
+	4:  4, // LONG
+	5:  8, // RATIONAL
+	7:  1, // UNDEFINED
+	9:  4, // SLONG
+	10: 8, // SRATIONAL
+}
+
+// exifSegment returns the TIFF data of the EXIF segment of a jpeg, or nil if
+// the jpeg does not have one
+func exifSegment(data []byte) []byte {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return nil
+		}
+
+		marker := data[i+1]
+		// the image data starts at the start of scan marker
+		if marker == 0xDA || marker == 0xD9 {
+			return nil
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return nil
+		}
+
+		payload := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+			return payload[6:]
+		}
+
+		i += 2 + length
+	}
+
+	return nil
+}
+
+// errBadJPEG is returned for jpegs whose segments can not be read
+var errBadJPEG = errors.New("imaging: malformed jpeg")
+
+// keptSegment returns whether or not StripMetadata keeps the application
+// segment with the given marker and payload. Only the segments that are
+// needed to show the photo are kept: JFIF, ICC profiles, and Adobe color
+// transforms.
+func keptSegment(marker byte, payload []byte) bool {
+	switch marker {
+	case 0xE0, 0xEE:
+		return true
+	case 0xE2:
+		return bytes.HasPrefix(payload, []byte("ICC_PROFILE\x00"))
+	}
+
+	return false
+}
+
+// StripMetadata returns a copy of the jpeg without its metadata, like its
+// EXIF, XMP, and comments, or any images appended to it, without decoding
+// it. It is used for photos that can not be processed so they are never
+// stored with their location. ErrNotImage is returned for data that is not
+// a jpeg, and an error for a jpeg whose segments can not be read.
+func StripMetadata(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, ErrNotImage
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return nil, errBadJPEG
+		}
+
+		marker := data[i+1]
+		// markers can be padded with fill bytes
+		if marker == 0xFF {
+			i++
+			continue
+		}
+
+		// the image data runs from the start of scan marker to the end of
+		// image marker, which can not appear inside of it
+		if marker == 0xDA {
+			end := bytes.Index(data[i:], []byte{0xFF, 0xD9})
+			if end < 0 {
+				return nil, errBadJPEG
+			}
+
+			return append(out, data[i:i+end+2]...), nil
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return nil, errBadJPEG
+		}
+
+		payload := data[i+4 : i+2+length]
+		isMetadata := marker == 0xFE || (marker >= 0xE0 && marker <= 0xEF && !keptSegment(marker, payload))
+		if !isMetadata {
+			out = append(out, data[i:i+2+length]...)
+		}
+
+		i += 2 + length
+	}
+
+	return nil, errBadJPEG
+}
+
+// tiff reads the fields of TIFF data
+type tiff struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// ifdEntry is a field of an image file directory
+type ifdEntry struct {
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+// ifd reads the image file directory at the given offset
+func (t *tiff) ifd(offset uint32) (map[uint16]ifdEntry, error) {
+	if uint64(offset)+2 > uint64(len(t.data)) {
+		return nil, errBadEXIF
+	}
+
+	count := uint32(t.order.Uint16(t.data[offset:]))
+	start := offset + 2
+	if uint64(start)+uint64(count)*12 > uint64(len(t.data)) {
+		return nil, errBadEXIF
+	}
+
+	entries := make(map[uint16]ifdEntry, count)
+	for i := uint32(0); i < count; i++ {
+		b := t.data[start+i*12 : start+i*12+12]
+		tag := t.order.Uint16(b)
+		typ := t.order.Uint16(b[2:])
+		n := t.order.Uint32(b[4:])
+
+		size, ok := typeSizes[typ]
+		if !ok || n > uint32(len(t.data)) {
+			continue
+		}
+
+		var value []byte
+		if total := uint64(size) * uint64(n); total <= 4 {
+			value = b[8 : 8+total]
+		} else {
+			valueOffset := uint64(t.order.Uint32(b[8:]))
+			if valueOffset+total > uint64(len(t.data)) {
+				continue
+			}
+			value = t.data[valueOffset : valueOffset+total]
+		}
+
+		entries[tag] = ifdEntry{typ: typ, count: n, value: value}
+	}
+
+	return entries, nil
+}
+
+// unsigned returns the first value of a SHORT or LONG field
+func (t *tiff) unsigned(e ifdEntry) (uint32, bool) {
+	switch {
+	case e.typ == 3 && len(e.value) >= 2:
+		return uint32(t.order.Uint16(e.value)), true
+	case e.typ == 4 && len(e.value) >= 4:
+		return t.order.Uint32(e.value), true
+	}
+
+	return 0, false
+}
+
+// ascii returns the value of an ASCII field
+func (t *tiff) ascii(e ifdEntry) string {
+	return strings.TrimRight(string(e.value), "\x00 ")
+}
+
+// degrees returns the decimal degrees of a GPS coordinate field, which is
+// stored as 3 rationals: degrees, minutes, and seconds
+func (t *tiff) degrees(e ifdEntry) (float64, bool) {
+	if e.typ != 5 || e.count != 3 || len(e.value) < 24 {
+		return 0, false
+	}
+
+	var parts [3]float64
+	for i := range parts {
+		num := t.order.Uint32(e.value[i*8:])
+		den := t.order.Uint32(e.value[i*8+4:])
+		if den == 0 {
+			return 0, false
+		}
+		parts[i] = float64(num) / float64(den)
+	}
+
+	return parts[0] + parts[1]/60 + parts[2]/3600, true
+}
+
+// ReadEXIF returns the EXIF metadata of a jpeg. A nil EXIF is returned if
+// the data does not have any.
+func ReadEXIF(data []byte) (*EXIF, error) {
+	segment := exifSegment(data)
+	if segment == nil {
+		return nil, nil
+	}
+
+	if len(segment) < 8 {
+		return nil, errBadEXIF
+	}
+
+	t := tiff{data: segment}
+	switch string(segment[:2]) {
+	case "II":
+		t.order = binary.LittleEndian
+	case "MM":
+		t.order = binary.BigEndian
+	default:
+		return nil, errBadEXIF
+	}
+
+	if t.order.Uint16(segment[2:]) != 42 {
+		return nil, errBadEXIF
+	}
+
+	ifd0, err := t.ifd(t.order.Uint32(segment[4:]))
+	if err != nil {
+		return nil, err
+	}
+
+	exif := EXIF{Orientation: 1}
+
+	if e, ok := ifd0[tagOrientation]; ok {
+		if o, ok := t.unsigned(e); ok && o >= 1 && o <= 8 {
+			exif.Orientation = int(o)
+		}
+	}
+
+	if e, ok := ifd0[tagExifIFD]; ok {
+		if offset, ok := t.unsigned(e); ok {
+			if sub, err := t.ifd(offset); err == nil {
+				if dt, ok := sub[tagDateTimeOriginal]; ok {
+					taken, err := time.Parse(exifTimeLayout, t.ascii(dt))
+					if err == nil {
+						exif.TakenAt = taken
+					}
+				}
+			}
+		}
+	}
+
+	if e, ok := ifd0[tagGPSIFD]; ok {
+		if offset, ok := t.unsigned(e); ok {
+			if gps, err := t.ifd(offset); err == nil {
+				lat, latOK := t.degrees(gps[tagGPSLatitude])
+				lng, lngOK := t.degrees(gps[tagGPSLongitude])
+				if latOK && lngOK {
+					if t.ascii(gps[tagGPSLatitudeRef]) == "S" {
+						lat = -lat
+					}
+					if t.ascii(gps[tagGPSLongitudeRef]) == "W" {
+						lng = -lng
+					}
+
+					exif.HasLocation = true
+					exif.Latitude = lat
+					exif.Longitude = lng
+				}
+			}
+		}
+	}
+
+	return &exif, nil
+}
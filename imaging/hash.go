@@ -0,0 +1,70 @@
+package imaging
+
+import (
+	"image"
+	"math/bits"
+)
+
+// hashWidth and hashHeight are the size of the grid a photo is shrunk to
+// before it is hashed. Each row of 9 cells gives 8 bits.
+const (
+	hashWidth  = 9
+	hashHeight = 8
+)
+
+// DHash returns the difference hash of the image. The image is shrunk to a
+// 9x8 grid of brightnesses and each bit records whether a cell is brighter
+// than the cell to its right. Copies of a photo that were scaled, cropped a
+// little, or re-encoded have hashes that differ by only a few bits.
+func DHash(img *image.RGBA) uint64 {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if w < 1 || h < 1 {
+		return 0
+	}
+
+	var grid [hashHeight][hashWidth]int
+	for gy := 0; gy < hashHeight; gy++ {
+		y0, y1 := gy*h/hashHeight, (gy+1)*h/hashHeight
+		if y1 == y0 {
+			y1 = y0 + 1
+		}
+
+		for gx := 0; gx < hashWidth; gx++ {
+			x0, x1 := gx*w/hashWidth, (gx+1)*w/hashWidth
+			if x1 == x0 {
+				x1 = x0 + 1
+			}
+
+			sum := 0
+			for y := y0; y < y1 && y < h; y++ {
+				si := img.PixOffset(x0+img.Rect.Min.X, y+img.Rect.Min.Y)
+				for x := x0; x < x1 && x < w; x++ {
+					// the integer form of the rec. 601 luma weights
+					r, g, b := int(img.Pix[si]), int(img.Pix[si+1]), int(img.Pix[si+2])
+					sum += (299*r + 587*g + 114*b) / 1000
+					si += 4
+				}
+			}
+
+			grid[gy][gx] = sum / ((x1 - x0) * (y1 - y0))
+		}
+	}
+
+	var hash uint64
+	for gy := 0; gy < hashHeight; gy++ {
+		for gx := 0; gx < hashWidth-1; gx++ {
+			hash <<= 1
+			if grid[gy][gx] > grid[gy][gx+1] {
+				hash |= 1
+			}
+		}
+	}
+
+	return hash
+}
+
+// HashDistance returns how many bits the two hashes differ by. Hashes of the
+// same photo usually differ by fewer than 10 of their 64 bits.
+func HashDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
@@ -0,0 +1,168 @@
+// Package imaging processes uploaded photos. It reads their EXIF metadata,
+// turns them upright, strips their metadata, and makes thumbnails.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+)
+
+// ThumbnailSizes are the sides, in pixels, of the squares thumbnails are
+// scaled to fit, largest first
+var ThumbnailSizes = []int{1024, 480, 160}
+
+const (
+	// maxPixels is the largest image, in pixels, that is processed
+	maxPixels = 50000000
+
+	// thumbnailQuality is the jpeg quality of thumbnails
+	thumbnailQuality = 80
+
+	// publicQuality is the jpeg quality of the public copy of a photo
+	publicQuality = 90
+)
+
+// ErrNotImage is returned for data that is not an image that can be
+// processed, like videos
+var ErrNotImage = errors.New("imaging: not a supported image")
+
+// Thumbnail is a scaled down jpeg copy of a photo
+type Thumbnail struct {
+	// Size is the side of the square the thumbnail fits in
+	Size int
+
+	// Width is the width of the thumbnail in pixels
+	Width int
+
+	// Height is the height of the thumbnail in pixels
+	Height int
+
+	// Data is the jpeg
+	Data []byte
+}
+
+// Result is a processed photo
+type Result struct {
+	// EXIF is the photo's metadata, nil if it did not have any
+	EXIF *EXIF
+
+	// Public is an upright copy of the photo, in the photo's format, without
+	// any of its metadata
+	Public []byte
+
+	// Thumbnails are the upright scaled down copies of the photo, largest
+	// first
+	Thumbnails []*Thumbnail
+
+	// Hash is the difference hash of the upright photo, used to find
+	// copies of it
+	Hash uint64
+}
+
+// encode returns the image as a jpeg
+func encode(img image.Image, quality int) ([]byte, error) {
+	buf := bytes.Buffer{}
+	err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodePublic returns the upright image in the photo's format. Encoding
+// drops every kind of metadata the original had, i.e. EXIF, XMP, and text
+// chunks, not just the metadata that was read. The frames of a gif are kept.
+func encodePublic(data []byte, format string, img image.Image) ([]byte, error) {
+	buf := bytes.Buffer{}
+	switch format {
+	case "png":
+		err := png.Encode(&buf, img)
+		if err != nil {
+			return nil, err
+		}
+	case "gif":
+		g, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		err = gif.EncodeAll(&buf, g)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return encode(img, publicQuality)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Process reads the photo's metadata, turns it upright, hashes it, strips its
+// metadata, and makes its thumbnails. Thumbnails are only made for the sizes
+// smaller than the photo.
+// ErrNotImage is returned for data that is not a jpeg, png, or gif.
+func Process(data []byte) (*Result, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrNotImage
+	}
+
+	if config.Width*config.Height > maxPixels {
+		return nil, errors.New("imaging: image is too large to process")
+	}
+
+	res := Result{}
+	orientation := 1
+	if format == "jpeg" {
+		res.EXIF, err = ReadEXIF(data)
+		if err != nil {
+			return nil, err
+		}
+		if res.EXIF != nil {
+			orientation = res.EXIF.Orientation
+		}
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	img := Orient(toRGBA(decoded), orientation)
+	res.Hash = DHash(img)
+
+	// the original is always re-encoded since its location can be kept in
+	// metadata that is not read, like XMP
+	res.Public, err = encodePublic(data, format, img)
+	if err != nil {
+		return nil, err
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	for _, size := range ThumbnailSizes {
+		if w <= size && h <= size {
+			continue
+		}
+
+		// each thumbnail is scaled from the last, larger, one
+		img = Resize(img, size)
+		b, err := encode(img, thumbnailQuality)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Thumbnails = append(res.Thumbnails, &Thumbnail{
+			Size:   size,
+			Width:  img.Bounds().Dx(),
+			Height: img.Bounds().Dy(),
+			Data:   b,
+		})
+	}
+
+	return &res, nil
+}
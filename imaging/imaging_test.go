@@ -0,0 +1,458 @@
+// +build unit
+
+package imaging_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/imaging"
+)
+
+// tiffEntry is a field written by buildEXIF
+type tiffEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+// writeIFD writes an image file directory at the offset, with values that do
+// not fit in an entry written right after it. It returns the offset after
+// the directory and its values.
+func writeIFD(buf []byte, offset uint32, entries []tiffEntry) uint32 {
+	le := binary.LittleEndian
+	le.PutUint16(buf[offset:], uint16(len(entries)))
+	extra := offset + 2 + uint32(len(entries))*12 + 4
+	for i, e := range entries {
+		b := buf[offset+2+uint32(i)*12:]
+		le.PutUint16(b, e.tag)
+		le.PutUint16(b[2:], e.typ)
+		le.PutUint32(b[4:], e.count)
+		if len(e.value) <= 4 {
+			copy(b[8:12], e.value)
+			continue
+		}
+		le.PutUint32(b[8:], extra)
+		copy(buf[extra:], e.value)
+		extra += uint32(len(e.value))
+	}
+
+	return extra
+}
+
+// rationals returns degrees, minutes, and seconds as little endian
+// rationals
+func rationals(d, m, s uint32) []byte {
+	b := make([]byte, 24)
+	for i, v := range []uint32{d, m, s} {
+		binary.LittleEndian.PutUint32(b[i*8:], v)
+		binary.LittleEndian.PutUint32(b[i*8+4:], 1)
+	}
+	return b
+}
+
+// buildEXIF returns little endian TIFF data with an orientation, a date
+// time original, and a GPS location
+func buildEXIF(orientation uint16) []byte {
+	buf := make([]byte, 512)
+	copy(buf, "II")
+	binary.LittleEndian.PutUint16(buf[2:], 42)
+	binary.LittleEndian.PutUint32(buf[4:], 8)
+
+	short := make([]byte, 2)
+	binary.LittleEndian.PutUint16(short, orientation)
+
+	// ifd0 has 3 entries, so the exif ifd starts after it at 8+2+36+4
+	exifOffset, gpsOffset := make([]byte, 4), make([]byte, 4)
+	binary.LittleEndian.PutUint32(exifOffset, 50)
+
+	exifEnd := writeIFD(buf, 50, []tiffEntry{
+		{0x9003, 2, 20, []byte("2019:06:01 13:45:30\x00")},
+	})
+	binary.LittleEndian.PutUint32(gpsOffset, exifEnd)
+
+	writeIFD(buf, 8, []tiffEntry{
+		{0x0112, 3, 1, short},
+		{0x8769, 4, 1, exifOffset},
+		{0x8825, 4, 1, gpsOffset},
+	})
+
+	end := writeIFD(buf, exifEnd, []tiffEntry{
+		{0x0001, 2, 2, []byte("N\x00")},
+		{0x0002, 5, 3, rationals(40, 30, 36)},
+		{0x0003, 2, 2, []byte("W\x00")},
+		{0x0004, 5, 3, rationals(105, 15, 0)},
+	})
+
+	return buf[:end]
+}
+
+// withEXIF inserts an EXIF segment after the start of image marker
+func withEXIF(jpg, tiff []byte) []byte {
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	header := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(header[2:], uint16(len(segment)+2))
+
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, header...)
+	out = append(out, segment...)
+	return append(out, jpg[2:]...)
+}
+
+func encodeJPEG(t *testing.T, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	buf := bytes.Buffer{}
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("error encoding jpeg: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadEXIF(t *testing.T) {
+	data := withEXIF(encodeJPEG(t, 8, 8), buildEXIF(6))
+
+	exif, err := imaging.ReadEXIF(data)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if exif == nil {
+		t.Fatalf("expected exif data")
+	}
+
+	if exif.Orientation != 6 {
+		t.Errorf("expected orientation 6, got %d", exif.Orientation)
+	}
+
+	taken := time.Date(2019, 6, 1, 13, 45, 30, 0, time.UTC)
+	if !exif.TakenAt.Equal(taken) {
+		t.Errorf("expected taken at %v, got %v", taken, exif.TakenAt)
+	}
+
+	if !exif.HasLocation {
+		t.Fatalf("expected a location")
+	}
+	if math.Abs(exif.Latitude-40.51) > 0.0001 {
+		t.Errorf("expected latitude 40.51, got %f", exif.Latitude)
+	}
+	if math.Abs(exif.Longitude+105.25) > 0.0001 {
+		t.Errorf("expected longitude -105.25, got %f", exif.Longitude)
+	}
+}
+
+func TestReadEXIFWithoutMetadata(t *testing.T) {
+	exif, err := imaging.ReadEXIF(encodeJPEG(t, 8, 8))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if exif != nil {
+		t.Errorf("expected no exif data, got %v", exif)
+	}
+}
+
+func TestOrient(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+
+	cases := []struct {
+		name        string
+		orientation int
+		width       int
+		height      int
+		red         image.Point
+		blue        image.Point
+	}{
+		{"upright", 1, 2, 1, image.Pt(0, 0), image.Pt(1, 0)},
+		{"rotated 180", 3, 2, 1, image.Pt(1, 0), image.Pt(0, 0)},
+		{"rotated counter clockwise", 6, 1, 2, image.Pt(0, 0), image.Pt(0, 1)},
+		{"rotated clockwise", 8, 1, 2, image.Pt(0, 1), image.Pt(0, 0)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+			img.SetRGBA(0, 0, red)
+			img.SetRGBA(1, 0, blue)
+
+			res := imaging.Orient(img, c.orientation)
+			if res.Bounds().Dx() != c.width || res.Bounds().Dy() != c.height {
+				t.Fatalf(
+					"expected %dx%d, got %dx%d",
+					c.width,
+					c.height,
+					res.Bounds().Dx(),
+					res.Bounds().Dy(),
+				)
+			}
+
+			if got := res.RGBAAt(c.red.X, c.red.Y); got != red {
+				t.Errorf("expected red at %v, got %v", c.red, got)
+			}
+			if got := res.RGBAAt(c.blue.X, c.blue.Y); got != blue {
+				t.Errorf("expected blue at %v, got %v", c.blue, got)
+			}
+		})
+	}
+}
+
+func TestResize(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 100))
+
+	res := imaging.Resize(img, 200)
+	if res.Bounds().Dx() != 200 || res.Bounds().Dy() != 50 {
+		t.Errorf("expected 200x50, got %dx%d", res.Bounds().Dx(), res.Bounds().Dy())
+	}
+
+	if imaging.Resize(img, 500) != img {
+		t.Errorf("expected images that fit to be returned as is")
+	}
+}
+
+func TestProcess(t *testing.T) {
+	data := withEXIF(encodeJPEG(t, 2000, 1000), buildEXIF(6))
+
+	res, err := imaging.Process(data)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if res.EXIF == nil || !res.EXIF.HasLocation {
+		t.Fatalf("expected the exif location to be read")
+	}
+
+	if res.Public == nil {
+		t.Fatalf("expected a public copy")
+	}
+	if bytes.Contains(res.Public, []byte("Exif")) {
+		t.Errorf("expected the public copy to not have exif data")
+	}
+
+	public, _, err := image.DecodeConfig(bytes.NewReader(res.Public))
+	if err != nil {
+		t.Fatalf("error decoding public copy: %v", err)
+	}
+	if public.Width != 1000 || public.Height != 2000 {
+		t.Errorf("expected an upright 1000x2000 copy, got %dx%d", public.Width, public.Height)
+	}
+
+	if len(res.Thumbnails) != len(imaging.ThumbnailSizes) {
+		t.Fatalf("expected %d thumbnails, got %d", len(imaging.ThumbnailSizes), len(res.Thumbnails))
+	}
+
+	for i, thumb := range res.Thumbnails {
+		if thumb.Size != imaging.ThumbnailSizes[i] {
+			t.Errorf("expected thumbnail %d to be size %d, got %d", i, imaging.ThumbnailSizes[i], thumb.Size)
+		}
+
+		config, _, err := image.DecodeConfig(bytes.NewReader(thumb.Data))
+		if err != nil {
+			t.Fatalf("error decoding thumbnail %d: %v", thumb.Size, err)
+		}
+
+		if config.Width != thumb.Width || config.Height != thumb.Height {
+			t.Errorf(
+				"expected thumbnail %d to be %dx%d, got %dx%d",
+				thumb.Size,
+				thumb.Width,
+				thumb.Height,
+				config.Width,
+				config.Height,
+			)
+		}
+
+		if config.Height != thumb.Size || config.Width != thumb.Size/2 {
+			t.Errorf("expected thumbnail %d to be upright, got %dx%d", thumb.Size, config.Width, config.Height)
+		}
+	}
+}
+
+func TestProcessSmallImage(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 100, 100))); err != nil {
+		t.Fatalf("error encoding png: %v", err)
+	}
+
+	res, err := imaging.Process(buf.Bytes())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if res.EXIF != nil || len(res.Thumbnails) != 0 {
+		t.Errorf("expected a small png to not have exif data or thumbnails, got %+v", res)
+	}
+
+	if _, err := png.Decode(bytes.NewReader(res.Public)); err != nil {
+		t.Errorf("expected the public copy to be a png, got %v", err)
+	}
+}
+
+// withSegment inserts an APPn segment with the payload after the start of
+// image marker
+func withSegment(jpg []byte, marker byte, payload []byte) []byte {
+	header := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(header[2:], uint16(len(payload)+2))
+
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, header...)
+	out = append(out, payload...)
+	return append(out, jpg[2:]...)
+}
+
+func TestProcessStripsXMP(t *testing.T) {
+	xmp := []byte("http://ns.adobe.com/xap/1.0/\x00" +
+		`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF><rdf:Description ` +
+		`exif:GPSLatitude="40,42.5N" exif:GPSLongitude="74,0.36W"/></rdf:RDF></x:xmpmeta>`)
+	data := withSegment(encodeJPEG(t, 8, 8), 0xE1, xmp)
+
+	res, err := imaging.Process(data)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if res.EXIF != nil {
+		t.Errorf("expected no exif data, got %+v", res.EXIF)
+	}
+	if bytes.Contains(res.Public, []byte("GPSLatitude")) {
+		t.Errorf("expected the public copy to not have the xmp location")
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(res.Public)); err != nil {
+		t.Errorf("expected the public copy to decode, got %v", err)
+	}
+}
+
+func TestProcessStripsPNGText(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatalf("error encoding png: %v", err)
+	}
+	orig := buf.Bytes()
+
+	// a tEXt chunk right after the IHDR chunk, which ends at byte 33
+	text := []byte("Comment\x00GPSLatitude 40.7")
+	chunk := make([]byte, 8, 12+len(text))
+	binary.BigEndian.PutUint32(chunk, uint32(len(text)))
+	copy(chunk[4:], "tEXt")
+	chunk = append(chunk, text...)
+	crc := make([]byte, 4)
+	binary.BigEndian.PutUint32(crc, crc32.ChecksumIEEE(chunk[4:]))
+	chunk = append(chunk, crc...)
+
+	data := append([]byte{}, orig[:33]...)
+	data = append(data, chunk...)
+	data = append(data, orig[33:]...)
+
+	res, err := imaging.Process(data)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if bytes.Contains(res.Public, []byte("GPSLatitude")) {
+		t.Errorf("expected the public copy to not have the text chunk")
+	}
+}
+
+func TestStripMetadata(t *testing.T) {
+	jpg := encodeJPEG(t, 8, 8)
+	data := withEXIF(jpg, buildEXIF(6))
+	// an image appended after the end of the photo, like the ones multi
+	// picture jpegs have, can carry its own metadata
+	data = append(data, withEXIF(jpg, buildEXIF(1))...)
+
+	stripped, err := imaging.StripMetadata(data)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	exif, err := imaging.ReadEXIF(stripped)
+	if err != nil || exif != nil {
+		t.Errorf("expected the exif data to be stripped, got %v and %v", exif, err)
+	}
+	if bytes.Contains(stripped, []byte("Exif\x00\x00")) {
+		t.Errorf("expected the appended image to be stripped")
+	}
+
+	if _, err := jpeg.Decode(bytes.NewReader(stripped)); err != nil {
+		t.Errorf("expected the stripped photo to decode, got %v", err)
+	}
+
+	if _, err := imaging.StripMetadata(data[:len(jpg)/2]); err == nil {
+		t.Errorf("expected an error for a truncated jpeg")
+	}
+
+	if _, err := imaging.StripMetadata([]byte("not an image")); err != imaging.ErrNotImage {
+		t.Errorf("expected ErrNotImage, got %v", err)
+	}
+}
+
+func TestProcessNotImage(t *testing.T) {
+	_, err := imaging.Process([]byte("not an image"))
+	if err != imaging.ErrNotImage {
+		t.Errorf("expected ErrNotImage, got %v", err)
+	}
+}
+
+// gradient returns an image that gets brighter from left to right, or from
+// right to left if reversed
+func gradient(w, h int, reversed bool) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8(x * 255 / w)
+			if reversed {
+				v = 255 - v
+			}
+			img.SetRGBA(x, y, color.RGBA{v, uint8(y * 255 / h), v, 255})
+		}
+	}
+	return img
+}
+
+func TestDHash(t *testing.T) {
+	img := gradient(640, 480, false)
+
+	// a scaled down, re-encoded copy
+	buf := bytes.Buffer{}
+	if err := jpeg.Encode(&buf, imaging.Resize(img, 200), &jpeg.Options{Quality: 50}); err != nil {
+		t.Fatalf("error encoding jpeg: %v", err)
+	}
+	res, err := imaging.Process(buf.Bytes())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	hash := imaging.DHash(img)
+	if d := imaging.HashDistance(hash, res.Hash); d > 4 {
+		t.Errorf("expected a copy to differ by at most 4 bits, got %d", d)
+	}
+
+	other := imaging.DHash(gradient(640, 480, true))
+	if d := imaging.HashDistance(hash, other); d < 32 {
+		t.Errorf("expected a different image to differ by at least 32 bits, got %d", d)
+	}
+}
+
+func TestHashDistance(t *testing.T) {
+	cases := []struct {
+		a, b     uint64
+		distance int
+	}{
+		{0, 0, 0},
+		{0xFF, 0, 8},
+		{0xF0F0, 0x0F0F, 16},
+		{^uint64(0), 0, 64},
+	}
+
+	for _, c := range cases {
+		if d := imaging.HashDistance(c.a, c.b); d != c.distance {
+			t.Errorf("expected %x and %x to differ by %d bits, got %d", c.a, c.b, c.distance, d)
+		}
+	}
+}
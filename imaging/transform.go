@@ -0,0 +1,118 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// toRGBA draws the image onto a white RGBA canvas. Transparent pixels end up
+// white because jpegs do not have an alpha channel.
+func toRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Over)
+
+	return dst
+}
+
+// Orient returns the image turned so it displays upright for the given EXIF
+// orientation. The cases describe how the stored pixels are turned.
+func Orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // flipped horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotated 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // flipped vertically
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90 counter clockwise
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90 clockwise
+				dx, dy = y, w-1-x
+			}
+
+			si := img.PixOffset(x+img.Rect.Min.X, y+img.Rect.Min.Y)
+			di := dst.PixOffset(dx, dy)
+			copy(dst.Pix[di:di+4], img.Pix[si:si+4])
+		}
+	}
+
+	return dst
+}
+
+// Resize scales the image down to fit in a square with the given side,
+// keeping its aspect ratio. Each new pixel is the average of the pixels it
+// covers. Images that already fit are returned as is.
+func Resize(img *image.RGBA, size int) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if w <= size && h <= size {
+		return img
+	}
+
+	dw, dh := size, size
+	if w > h {
+		dh = h * size / w
+	} else {
+		dw = w * size / h
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for dy := 0; dy < dh; dy++ {
+		y0, y1 := dy*h/dh, (dy+1)*h/dh
+		if y1 == y0 {
+			y1 = y0 + 1
+		}
+
+		for dx := 0; dx < dw; dx++ {
+			x0, x1 := dx*w/dw, (dx+1)*w/dw
+			if x1 == x0 {
+				x1 = x0 + 1
+			}
+
+			var sum [4]int
+			for y := y0; y < y1; y++ {
+				si := img.PixOffset(x0+img.Rect.Min.X, y+img.Rect.Min.Y)
+				for x := x0; x < x1; x++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += int(img.Pix[si+c])
+					}
+					si += 4
+				}
+			}
+
+			n := (x1 - x0) * (y1 - y0)
+			di := dst.PixOffset(dx, dy)
+			for c := 0; c < 4; c++ {
+				dst.Pix[di+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,50 @@
+// Package joincode generates the short codes players use to join hunts and
+// teams
+package joincode
+
+import (
+	"crypto/rand"
+	"strings"
+)
+
+const (
+	// Alphabet is the set of characters codes are made of. Easily confused
+	// characters, i.e. 0/O and 1/I, are left out so codes can be read off
+	// of a flyer.
+	Alphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	// Length is the number of characters in a code
+	Length = 8
+
+	// MaxAttempts is the number of times a new code should be generated
+	// when the previous one is already in use
+	MaxAttempts = 5
+)
+
+// Generate returns a random code
+func Generate() (string, error) {
+	buf := make([]byte, Length)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+
+	// the alphabet's length is a power of 2 so there is no modulo bias
+	for i, b := range buf {
+		buf[i] = Alphabet[int(b)%len(Alphabet)]
+	}
+
+	return string(buf), nil
+}
+
+// Normalize makes user entered codes match generated codes, i.e.
+// "abcd-efgh " becomes "ABCDEFGH"
+func Normalize(code string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '\t':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(code))
+}
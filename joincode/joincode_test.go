@@ -0,0 +1,56 @@
+// +build unit
+
+package joincode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cljohnson4343/scavenge/joincode"
+)
+
+func TestGenerate(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		code, err := joincode.Generate()
+		if err != nil {
+			t.Fatalf("error generating code: %v", err)
+		}
+
+		if len(code) != joincode.Length {
+			t.Errorf("expected code of length %d got %s", joincode.Length, code)
+		}
+
+		for _, r := range code {
+			if !strings.ContainsRune(joincode.Alphabet, r) {
+				t.Errorf("code %s contains %c which is not in the alphabet", code, r)
+			}
+		}
+
+		if seen[code] {
+			t.Errorf("code %s was generated twice", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		code     string
+		expected string
+	}{
+		{code: "ABCDEFGH", expected: "ABCDEFGH"},
+		{code: "abcdefgh", expected: "ABCDEFGH"},
+		{code: "abcd-efgh", expected: "ABCDEFGH"},
+		{code: " ABCD EFGH\t", expected: "ABCDEFGH"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.code, func(t *testing.T) {
+			got := joincode.Normalize(c.code)
+			if got != c.expected {
+				t.Errorf("expected %s got %s", c.expected, got)
+			}
+		})
+	}
+}
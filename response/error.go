@@ -136,6 +136,18 @@ func (err *Error) AddError(e *Error) {
 	err.errors = append(err.errors, e.errors...)
 }
 
+// Prefix prepends the given prefix to the msg of each of the Error's errors.
+// This is useful for attributing errors to where they came from, for example
+// the row of an upload.
+func (err *Error) Prefix(prefix string) {
+	for _, e := range err.errors {
+		msg := e.sb.String()
+		e.sb.Reset()
+		e.sb.WriteString(prefix)
+		e.sb.WriteString(msg)
+	}
+}
+
 // res is an internal struct used to map an error's data to json
 type res struct {
 	Code   int    `json:"code"`
@@ -159,6 +171,13 @@ func (err *Error) Handle(w http.ResponseWriter) {
 		panic("tried to handle a nil error")
 	}
 
+	w.WriteHeader(err.Code())
+	w.Write(err.JSON())
+}
+
+// Code returns the Error's highest priority status code, the lowest valued
+// one. This is the status code Handle responds with.
+func (err *Error) Code() int {
 	highestPriorityCode := 4343
 	for _, e := range err.errors {
 		if e.code < highestPriorityCode {
@@ -166,8 +185,7 @@ func (err *Error) Handle(w http.ResponseWriter) {
 		}
 	}
 
-	w.WriteHeader(highestPriorityCode)
-	w.Write(err.JSON())
+	return highestPriorityCode
 }
 
 // JSON returns a []byte of the errors for Error, for example:
@@ -21,6 +21,7 @@ import (
 	"github.com/cljohnson4343/scavenge/hunts"
 	"github.com/cljohnson4343/scavenge/response"
 	"github.com/cljohnson4343/scavenge/roles"
+	"github.com/cljohnson4343/scavenge/storage"
 	"github.com/cljohnson4343/scavenge/teams"
 	"github.com/cljohnson4343/scavenge/users"
 )
@@ -45,7 +46,7 @@ func TestMain(m *testing.M) {
 	d := db.InitDB("testing")
 	defer db.Shutdown(d)
 
-	env = config.CreateEnv(d)
+	env = config.CreateEnv(d, storage.NewMemoryStore(config.FilesURL))
 	response.SetDevMode(true)
 
 	// Login in user to get a valid user session cookie
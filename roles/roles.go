@@ -47,6 +47,14 @@ func DeleteRolesForTeam(teamID int) *response.Error {
 	return db.DeleteRolesByRegex(regex)
 }
 
+// RemoveTeamRoles removes all of the user's roles for the given team, for
+// when the user is no longer on it
+func RemoveTeamRoles(teamID, userID int) *response.Error {
+	regex := fmt.Sprintf("^team_[a-zA-Z]+_%d$", teamID)
+
+	return db.RemoveRolesByRegex(userID, regex)
+}
+
 // DeleteRolesForHunt deletes all the roles and permissions for the given hunt
 // this includes all roles and permissions for teams in the hunt
 func DeleteRolesForHunt(huntID int, teams []*db.TeamDB) *response.Error {
@@ -204,11 +212,36 @@ var PermToRoleEndpoint = map[string]roleEndPoint{
 		Route:          `/teams/%d/locations/`,
 		Role:           `user`,
 	},
+	"get_live_locations": roleEndPoint{
+		FormattedRegex: `/teams/%d/locations/live$`,
+		Route:          `/teams/%d/locations/live`,
+		Role:           `team_member`,
+	},
 	"post_location": roleEndPoint{
 		FormattedRegex: `/teams/%d/locations/$`,
 		Route:          `/teams/%d/locations/`,
 		Role:           `team_member`,
 	},
+	"get_team_stats": roleEndPoint{
+		FormattedRegex: `/teams/%d/stats$`,
+		Route:          `/teams/%d/stats`,
+		Role:           `team_member`,
+	},
+	"get_team_alerts": roleEndPoint{
+		FormattedRegex: `/teams/%d/alerts$`,
+		Route:          `/teams/%d/alerts`,
+		Role:           `team_member`,
+	},
+	"get_track_export": roleEndPoint{
+		FormattedRegex: `/teams/%d/locations/export$`,
+		Route:          `/teams/%d/locations/export`,
+		Role:           `team_member`,
+	},
+	"post_location_batch": roleEndPoint{
+		FormattedRegex: `/teams/%d/locations/batch$`,
+		Route:          `/teams/%d/locations/batch`,
+		Role:           `team_member`,
+	},
 	"delete_location": roleEndPoint{
 		FormattedRegex: `/teams/\d+/locations/\d+$`,
 		Route:          `/teams/%d/locations/43`,
@@ -229,6 +262,56 @@ var PermToRoleEndpoint = map[string]roleEndPoint{
 		Route:          `/teams/%d/media/43`,
 		Role:           `team_member`,
 	},
+	"post_upload": roleEndPoint{
+		FormattedRegex: `/teams/%d/uploads/$`,
+		Route:          `/teams/%d/uploads/`,
+		Role:           `team_member`,
+	},
+	"post_upload_confirm": roleEndPoint{
+		FormattedRegex: `/teams/%d/uploads/\d+/confirm$`,
+		Route:          `/teams/%d/uploads/43/confirm`,
+		Role:           `team_member`,
+	},
+	"post_teams_join": roleEndPoint{
+		FormattedRegex: `/teams/join/$`,
+		Route:          `/teams/join/`,
+		Role:           `user`,
+	},
+	"post_team_leave": roleEndPoint{
+		FormattedRegex: `/teams/%d/leave$`,
+		Route:          `/teams/%d/leave`,
+		Role:           `team_member`,
+	},
+	"get_team_code": roleEndPoint{
+		FormattedRegex: `/teams/%d/code$`,
+		Route:          `/teams/%d/code`,
+		Role:           `team_member`,
+	},
+	"post_item_answer": roleEndPoint{
+		FormattedRegex: `/teams/%d/items/\d+/answer$`,
+		Route:          `/teams/%d/items/43/answer`,
+		Role:           `team_member`,
+	},
+	"post_item_checkin": roleEndPoint{
+		FormattedRegex: `/teams/%d/items/\d+/checkin$`,
+		Route:          `/teams/%d/items/43/checkin`,
+		Role:           `team_member`,
+	},
+	"post_item_checkpoint": roleEndPoint{
+		FormattedRegex: `/teams/%d/items/\d+/checkpoint$`,
+		Route:          `/teams/%d/items/43/checkpoint`,
+		Role:           `team_member`,
+	},
+	"get_team_hints": roleEndPoint{
+		FormattedRegex: `/teams/%d/items/\d+/hints/$`,
+		Route:          `/teams/%d/items/43/hints/`,
+		Role:           `team_member`,
+	},
+	"post_hint_unlock": roleEndPoint{
+		FormattedRegex: `/teams/%d/items/\d+/hints/unlock$`,
+		Route:          `/teams/%d/items/43/hints/unlock`,
+		Role:           `team_member`,
+	},
 	"post_teams_populate": roleEndPoint{
 		FormattedRegex: `/teams/populate/$`,
 		Route:          `/teams/populate/`,
@@ -271,6 +354,16 @@ var PermToRoleEndpoint = map[string]roleEndPoint{
 		Route:          `/users/%d/notifications/43`,
 		Role:           `user_owner`,
 	},
+	"delete_message": roleEndPoint{
+		FormattedRegex: `/users/%d/messages/\d+$`,
+		Route:          `/users/%d/messages/43`,
+		Role:           `user_owner`,
+	},
+	"get_messages": roleEndPoint{
+		FormattedRegex: `/users/%d/messages/$`,
+		Route:          `/users/%d/messages/`,
+		Role:           `user_owner`,
+	},
 	"get_notifications": roleEndPoint{
 		FormattedRegex: `/users/%d/notifications/$`,
 		Route:          `/users/%d/notifications/`,
@@ -328,6 +421,36 @@ var PermToRoleEndpoint = map[string]roleEndPoint{
 		Route:          `/hunts/%d/items/43`,
 		Role:           `hunt_editor`,
 	},
+	"post_hint": roleEndPoint{
+		FormattedRegex: `/hunts/%d/items/\d+/hints/$`,
+		Route:          `/hunts/%d/items/43/hints/`,
+		Role:           `hunt_owner`,
+	},
+	"get_hints": roleEndPoint{
+		FormattedRegex: `/hunts/%d/items/\d+/hints/$`,
+		Route:          `/hunts/%d/items/43/hints/`,
+		Role:           `hunt_owner`,
+	},
+	"delete_hint": roleEndPoint{
+		FormattedRegex: `/hunts/%d/items/\d+/hints/\d+$`,
+		Route:          `/hunts/%d/items/43/hints/43`,
+		Role:           `hunt_owner`,
+	},
+	"put_item_dependencies": roleEndPoint{
+		FormattedRegex: `/hunts/%d/items/\d+/dependencies$`,
+		Route:          `/hunts/%d/items/43/dependencies`,
+		Role:           `hunt_owner`,
+	},
+	"post_items_import": roleEndPoint{
+		FormattedRegex: `/hunts/%d/items/import$`,
+		Route:          `/hunts/%d/items/import`,
+		Role:           `hunt_owner`,
+	},
+	"get_items_export": roleEndPoint{
+		FormattedRegex: `/hunts/%d/items/export$`,
+		Route:          `/hunts/%d/items/export`,
+		Role:           `hunt_owner`,
+	},
 	"delete_invitation": roleEndPoint{
 		FormattedRegex: `/hunts/%d/invitations/\d+$`,
 		Route:          `/hunts/%d/invitations/43`,
@@ -358,6 +481,171 @@ var PermToRoleEndpoint = map[string]roleEndPoint{
 		Route:          `/hunts/%d/players/`,
 		Role:           `hunt_editor`,
 	},
+	"post_join_code": roleEndPoint{
+		FormattedRegex: `/hunts/%d/codes/$`,
+		Route:          `/hunts/%d/codes/`,
+		Role:           `hunt_owner`,
+	},
+	"get_join_codes": roleEndPoint{
+		FormattedRegex: `/hunts/%d/codes/$`,
+		Route:          `/hunts/%d/codes/`,
+		Role:           `hunt_owner`,
+	},
+	"delete_join_code": roleEndPoint{
+		FormattedRegex: `/hunts/%d/codes/\d+$`,
+		Route:          `/hunts/%d/codes/43`,
+		Role:           `hunt_owner`,
+	},
+	"post_hunts_join": roleEndPoint{
+		FormattedRegex: `/hunts/join/$`,
+		Route:          `/hunts/join/`,
+		Role:           `user`,
+	},
+	"post_waitlist": roleEndPoint{
+		FormattedRegex: `/hunts/\d+/waitlist/$`,
+		Route:          `/hunts/%d/waitlist/`,
+		Role:           `user`,
+	},
+	"get_waitlist": roleEndPoint{
+		FormattedRegex: `/hunts/%d/waitlist/$`,
+		Route:          `/hunts/%d/waitlist/`,
+		Role:           `hunt_owner`,
+	},
+	"delete_waitlist": roleEndPoint{
+		FormattedRegex: `/hunts/\d+/waitlist/\d+$`,
+		Route:          `/hunts/%d/waitlist/43`,
+		Role:           `user`,
+	},
+	"post_hunt_own_team": roleEndPoint{
+		FormattedRegex: `/hunts/%d/teams/new$`,
+		Route:          `/hunts/%d/teams/new`,
+		Role:           `hunt_member`,
+	},
+	"post_balance_preview": roleEndPoint{
+		FormattedRegex: `/hunts/%d/balance/preview$`,
+		Route:          `/hunts/%d/balance/preview`,
+		Role:           `hunt_owner`,
+	},
+	"post_balance": roleEndPoint{
+		FormattedRegex: `/hunts/%d/balance/$`,
+		Route:          `/hunts/%d/balance/`,
+		Role:           `hunt_owner`,
+	},
+	"get_flags": roleEndPoint{
+		FormattedRegex: `/hunts/%d/flags/$`,
+		Route:          `/hunts/%d/flags/`,
+		Role:           `hunt_owner`,
+	},
+	"delete_flag": roleEndPoint{
+		FormattedRegex: `/hunts/%d/flags/\d+$`,
+		Route:          `/hunts/%d/flags/43`,
+		Role:           `hunt_owner`,
+	},
+	"get_media_archive": roleEndPoint{
+		FormattedRegex: `/hunts/%d/media/archive$`,
+		Route:          `/hunts/%d/media/archive`,
+		Role:           `hunt_owner`,
+	},
+	"get_hunt_media": roleEndPoint{
+		FormattedRegex: `/hunts/%d/media/$`,
+		Route:          `/hunts/%d/media/`,
+		Role:           `hunt_member`,
+	},
+	"get_media_comments": roleEndPoint{
+		FormattedRegex: `/hunts/%d/media/\d+/comments/$`,
+		Route:          `/hunts/%d/media/43/comments/`,
+		Role:           `hunt_member`,
+	},
+	"post_media_comment": roleEndPoint{
+		FormattedRegex: `/hunts/%d/media/\d+/comments/$`,
+		Route:          `/hunts/%d/media/43/comments/`,
+		Role:           `hunt_member`,
+	},
+	"delete_media_comment": roleEndPoint{
+		FormattedRegex: `/hunts/%d/media/\d+/comments/\d+$`,
+		Route:          `/hunts/%d/media/43/comments/43`,
+		Role:           `hunt_member`,
+	},
+	"post_media_reaction": roleEndPoint{
+		FormattedRegex: `/hunts/%d/media/\d+/reactions/$`,
+		Route:          `/hunts/%d/media/43/reactions/`,
+		Role:           `hunt_member`,
+	},
+	"delete_media_reaction": roleEndPoint{
+		FormattedRegex: `/hunts/%d/media/\d+/reactions/$`,
+		Route:          `/hunts/%d/media/43/reactions/`,
+		Role:           `hunt_member`,
+	},
+	"post_media_vote": roleEndPoint{
+		FormattedRegex: `/hunts/%d/media/\d+/vote$`,
+		Route:          `/hunts/%d/media/43/vote`,
+		Role:           `hunt_member`,
+	},
+	"delete_media_vote": roleEndPoint{
+		FormattedRegex: `/hunts/%d/media/\d+/vote$`,
+		Route:          `/hunts/%d/media/43/vote`,
+		Role:           `hunt_member`,
+	},
+	"get_voting": roleEndPoint{
+		FormattedRegex: `/hunts/%d/voting$`,
+		Route:          `/hunts/%d/voting`,
+		Role:           `hunt_member`,
+	},
+	"put_voting": roleEndPoint{
+		FormattedRegex: `/hunts/%d/voting$`,
+		Route:          `/hunts/%d/voting`,
+		Role:           `hunt_owner`,
+	},
+	"post_voting_finalize": roleEndPoint{
+		FormattedRegex: `/hunts/%d/voting/finalize$`,
+		Route:          `/hunts/%d/voting/finalize`,
+		Role:           `hunt_owner`,
+	},
+	"get_hunt_events": roleEndPoint{
+		FormattedRegex: `/hunts/%d/events$`,
+		Route:          `/hunts/%d/events`,
+		Role:           `hunt_member`,
+	},
+	"post_announcement": roleEndPoint{
+		FormattedRegex: `/hunts/%d/announcements/$`,
+		Route:          `/hunts/%d/announcements/`,
+		Role:           `hunt_owner`,
+	},
+	"get_hunt_stats": roleEndPoint{
+		FormattedRegex: `/hunts/%d/stats$`,
+		Route:          `/hunts/%d/stats`,
+		Role:           `hunt_member`,
+	},
+	"get_hunt_tracks_export": roleEndPoint{
+		FormattedRegex: `/hunts/%d/locations/export$`,
+		Route:          `/hunts/%d/locations/export`,
+		Role:           `hunt_owner`,
+	},
+	"get_hunt_live_locations": roleEndPoint{
+		FormattedRegex: `/hunts/%d/locations/live$`,
+		Route:          `/hunts/%d/locations/live`,
+		Role:           `hunt_owner`,
+	},
+	"get_boundary": roleEndPoint{
+		FormattedRegex: `/hunts/%d/boundary$`,
+		Route:          `/hunts/%d/boundary`,
+		Role:           `hunt_member`,
+	},
+	"put_boundary": roleEndPoint{
+		FormattedRegex: `/hunts/%d/boundary$`,
+		Route:          `/hunts/%d/boundary`,
+		Role:           `hunt_owner`,
+	},
+	"delete_boundary": roleEndPoint{
+		FormattedRegex: `/hunts/%d/boundary$`,
+		Route:          `/hunts/%d/boundary`,
+		Role:           `hunt_owner`,
+	},
+	"get_hunt_alerts": roleEndPoint{
+		FormattedRegex: `/hunts/%d/alerts$`,
+		Route:          `/hunts/%d/alerts`,
+		Role:           `hunt_owner`,
+	},
 	"post_accept_hunt_invite": roleEndPoint{
 		FormattedRegex: `/hunts/\d+/invitations/\d+/accept$`,
 		Route:          `/hunts/43/invitations/43/accept`,
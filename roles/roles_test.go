@@ -115,10 +115,30 @@ func TestGenerateGetLocations(t *testing.T) {
 	testGeneratePermission(t, "get_locations", nil)
 }
 
+func TestGenerateGetLiveLocations(t *testing.T) {
+	testGeneratePermission(t, "get_live_locations", nil)
+}
+
 func TestGeneratePostLocation(t *testing.T) {
 	testGeneratePermission(t, "post_location", nil)
 }
 
+func TestGenerateGetTeamStats(t *testing.T) {
+	testGeneratePermission(t, "get_team_stats", nil)
+}
+
+func TestGenerateGetTeamAlerts(t *testing.T) {
+	testGeneratePermission(t, "get_team_alerts", nil)
+}
+
+func TestGenerateGetTrackExport(t *testing.T) {
+	testGeneratePermission(t, "get_track_export", nil)
+}
+
+func TestGeneratePostLocationBatch(t *testing.T) {
+	testGeneratePermission(t, "post_location_batch", nil)
+}
+
 func TestGenerateDeleteLocation(t *testing.T) {
 	testGeneratePermission(t, "delete_location", nil)
 }
@@ -203,6 +223,210 @@ func TestGeneratePatchItem(t *testing.T) {
 	testGeneratePermission(t, "patch_item", nil)
 }
 
+func TestGeneratePostJoinCode(t *testing.T) {
+	testGeneratePermission(t, "post_join_code", nil)
+}
+
+func TestGenerateGetJoinCodes(t *testing.T) {
+	testGeneratePermission(t, "get_join_codes", nil)
+}
+
+func TestGenerateDeleteJoinCode(t *testing.T) {
+	testGeneratePermission(t, "delete_join_code", nil)
+}
+
+func TestGeneratePostHuntsJoin(t *testing.T) {
+	testGeneratePermission(t, "post_hunts_join", nil)
+}
+
+func TestGeneratePostWaitlist(t *testing.T) {
+	testGeneratePermission(t, "post_waitlist", nil)
+}
+
+func TestGenerateGetWaitlist(t *testing.T) {
+	testGeneratePermission(t, "get_waitlist", nil)
+}
+
+func TestGenerateDeleteWaitlist(t *testing.T) {
+	testGeneratePermission(t, "delete_waitlist", nil)
+}
+
+func TestGenerateGetMessages(t *testing.T) {
+	testGeneratePermission(t, "get_messages", nil)
+}
+
+func TestGenerateDeleteMessage(t *testing.T) {
+	testGeneratePermission(t, "delete_message", nil)
+}
+
+func TestGeneratePostTeamsJoin(t *testing.T) {
+	testGeneratePermission(t, "post_teams_join", nil)
+}
+
+func TestGeneratePostTeamLeave(t *testing.T) {
+	testGeneratePermission(t, "post_team_leave", nil)
+}
+
+func TestGenerateGetTeamCode(t *testing.T) {
+	testGeneratePermission(t, "get_team_code", nil)
+}
+
+func TestGeneratePostHuntOwnTeam(t *testing.T) {
+	testGeneratePermission(t, "post_hunt_own_team", nil)
+}
+
+func TestGeneratePostBalancePreview(t *testing.T) {
+	testGeneratePermission(t, "post_balance_preview", nil)
+}
+
+func TestGeneratePostBalance(t *testing.T) {
+	testGeneratePermission(t, "post_balance", nil)
+}
+
+func TestGeneratePostItemAnswer(t *testing.T) {
+	testGeneratePermission(t, "post_item_answer", nil)
+}
+
+func TestGeneratePostItemCheckIn(t *testing.T) {
+	testGeneratePermission(t, "post_item_checkin", nil)
+}
+
+func TestGeneratePostItemCheckpoint(t *testing.T) {
+	testGeneratePermission(t, "post_item_checkpoint", nil)
+}
+
+func TestGenerateGetFlags(t *testing.T) {
+	testGeneratePermission(t, "get_flags", nil)
+}
+
+func TestGenerateDeleteFlag(t *testing.T) {
+	testGeneratePermission(t, "delete_flag", nil)
+}
+
+func TestGenerateGetMediaArchive(t *testing.T) {
+	testGeneratePermission(t, "get_media_archive", nil)
+}
+
+func TestGenerateGetHuntMedia(t *testing.T) {
+	testGeneratePermission(t, "get_hunt_media", nil)
+}
+
+func TestGenerateGetMediaComments(t *testing.T) {
+	testGeneratePermission(t, "get_media_comments", nil)
+}
+
+func TestGeneratePostMediaComment(t *testing.T) {
+	testGeneratePermission(t, "post_media_comment", nil)
+}
+
+func TestGenerateDeleteMediaComment(t *testing.T) {
+	testGeneratePermission(t, "delete_media_comment", nil)
+}
+
+func TestGeneratePostMediaReaction(t *testing.T) {
+	testGeneratePermission(t, "post_media_reaction", nil)
+}
+
+func TestGenerateDeleteMediaReaction(t *testing.T) {
+	testGeneratePermission(t, "delete_media_reaction", nil)
+}
+
+func TestGeneratePostMediaVote(t *testing.T) {
+	testGeneratePermission(t, "post_media_vote", nil)
+}
+
+func TestGenerateDeleteMediaVote(t *testing.T) {
+	testGeneratePermission(t, "delete_media_vote", nil)
+}
+
+func TestGenerateGetVoting(t *testing.T) {
+	testGeneratePermission(t, "get_voting", nil)
+}
+
+func TestGeneratePutVoting(t *testing.T) {
+	testGeneratePermission(t, "put_voting", nil)
+}
+
+func TestGeneratePostVotingFinalize(t *testing.T) {
+	testGeneratePermission(t, "post_voting_finalize", nil)
+}
+
+func TestGenerateGetHuntEvents(t *testing.T) {
+	testGeneratePermission(t, "get_hunt_events", nil)
+}
+
+func TestGeneratePostAnnouncement(t *testing.T) {
+	testGeneratePermission(t, "post_announcement", nil)
+}
+
+func TestGenerateGetHuntStats(t *testing.T) {
+	testGeneratePermission(t, "get_hunt_stats", nil)
+}
+
+func TestGenerateGetHuntTracksExport(t *testing.T) {
+	testGeneratePermission(t, "get_hunt_tracks_export", nil)
+}
+
+func TestGenerateGetHuntLiveLocations(t *testing.T) {
+	testGeneratePermission(t, "get_hunt_live_locations", nil)
+}
+
+func TestGenerateGetBoundary(t *testing.T) {
+	testGeneratePermission(t, "get_boundary", nil)
+}
+
+func TestGeneratePutBoundary(t *testing.T) {
+	testGeneratePermission(t, "put_boundary", nil)
+}
+
+func TestGenerateDeleteBoundary(t *testing.T) {
+	testGeneratePermission(t, "delete_boundary", nil)
+}
+
+func TestGenerateGetHuntAlerts(t *testing.T) {
+	testGeneratePermission(t, "get_hunt_alerts", nil)
+}
+
+func TestGenerateGetTeamHints(t *testing.T) {
+	testGeneratePermission(t, "get_team_hints", nil)
+}
+
+func TestGeneratePostHintUnlock(t *testing.T) {
+	testGeneratePermission(t, "post_hint_unlock", nil)
+}
+
+func TestGeneratePostHint(t *testing.T) {
+	testGeneratePermission(t, "post_hint", nil)
+}
+
+func TestGenerateGetHints(t *testing.T) {
+	testGeneratePermission(t, "get_hints", nil)
+}
+
+func TestGenerateDeleteHint(t *testing.T) {
+	testGeneratePermission(t, "delete_hint", nil)
+}
+
+func TestGeneratePutItemDependencies(t *testing.T) {
+	testGeneratePermission(t, "put_item_dependencies", nil)
+}
+
+func TestGeneratePostUpload(t *testing.T) {
+	testGeneratePermission(t, "post_upload", nil)
+}
+
+func TestGeneratePostUploadConfirm(t *testing.T) {
+	testGeneratePermission(t, "post_upload_confirm", nil)
+}
+
+func TestGeneratePostItemsImport(t *testing.T) {
+	testGeneratePermission(t, "post_items_import", nil)
+}
+
+func TestGenerateGetItemsExport(t *testing.T) {
+	testGeneratePermission(t, "get_items_export", nil)
+}
+
 //
 // role testing
 //
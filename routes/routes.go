@@ -4,6 +4,7 @@ import (
 	"github.com/cljohnson4343/scavenge/config"
 	"github.com/cljohnson4343/scavenge/hunts"
 	"github.com/cljohnson4343/scavenge/response"
+	"github.com/cljohnson4343/scavenge/storage"
 	"github.com/cljohnson4343/scavenge/teams"
 	"github.com/cljohnson4343/scavenge/users"
 	"github.com/go-chi/chi"
@@ -22,6 +23,7 @@ func Routes(env *config.Env) *chi.Mux {
 		r.Mount("/hunts", hunts.Routes(env))
 		r.Mount("/teams", teams.Routes(env))
 		r.Mount("/users", users.Routes(env))
+		r.Mount("/files", storage.Routes(env.Storage, teams.UploadOpen))
 	})
 
 	return router
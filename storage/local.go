@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// LocalStore stores blobs as files in a directory on the local disk
+type LocalStore struct {
+	uploadSigner
+	dir string
+}
+
+// NewLocalStore returns a store that keeps its blobs in the given directory,
+// creating it if need be. The urls of the blobs start with baseURL and
+// upload urls are signed with secret, which is required so the urls keep
+// working across restarts and between instances.
+func NewLocalStore(dir, baseURL string, secret []byte) (*LocalStore, *response.Error) {
+	if len(secret) == 0 {
+		return nil, response.NewError(
+			http.StatusInternalServerError,
+			"storage_secret: the local storage driver requires a secret to sign upload urls",
+		)
+	}
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error creating storage directory %s: %v",
+			dir,
+			err,
+		)
+	}
+
+	return &LocalStore{uploadSigner: newUploadSigner(baseURL, secret), dir: dir}, nil
+}
+
+// Put writes the file to the store's directory
+func (store *LocalStore) Put(key string, file io.ReadSeeker) (string, *response.Error) {
+	e := validateKey(key)
+	if e != nil {
+		return "", e
+	}
+
+	f, err := os.Create(filepath.Join(store.dir, key))
+	if err != nil {
+		return "", response.NewErrorf(
+			http.StatusInternalServerError,
+			"error creating file %s: %v",
+			key,
+			err,
+		)
+	}
+
+	_, err = io.Copy(f, file)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filepath.Join(store.dir, key))
+		return "", response.NewErrorf(
+			http.StatusInternalServerError,
+			"error writing file %s: %v",
+			key,
+			err,
+		)
+	}
+
+	return store.URL(key), nil
+}
+
+// Get opens the file stored under the given key
+func (store *LocalStore) Get(key string) (io.ReadCloser, *response.Error) {
+	e := validateKey(key)
+	if e != nil {
+		return nil, e
+	}
+
+	f, err := os.Open(filepath.Join(store.dir, key))
+	if os.IsNotExist(err) {
+		return nil, response.NewErrorf(http.StatusNotFound, "key: %s does not exist", key)
+	}
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error opening file %s: %v",
+			key,
+			err,
+		)
+	}
+
+	return f, nil
+}
+
+// Stat returns the size of the file stored under the given key
+func (store *LocalStore) Stat(key string) (int64, *response.Error) {
+	e := validateKey(key)
+	if e != nil {
+		return 0, e
+	}
+
+	info, err := os.Stat(filepath.Join(store.dir, key))
+	if os.IsNotExist(err) {
+		return 0, response.NewErrorf(http.StatusNotFound, "key: %s does not exist", key)
+	}
+	if err != nil {
+		return 0, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting info for file %s: %v",
+			key,
+			err,
+		)
+	}
+
+	return info.Size(), nil
+}
+
+// Delete removes the file stored under the given key
+func (store *LocalStore) Delete(key string) *response.Error {
+	e := validateKey(key)
+	if e != nil {
+		return e
+	}
+
+	err := os.Remove(filepath.Join(store.dir, key))
+	if os.IsNotExist(err) {
+		return response.NewErrorf(http.StatusNotFound, "key: %s does not exist", key)
+	}
+	if err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error deleting file %s: %v",
+			key,
+			err,
+		)
+	}
+
+	return nil
+}
+
+// List returns every file in the store's directory
+func (store *LocalStore) List() ([]BlobInfo, *response.Error) {
+	infos, err := ioutil.ReadDir(store.dir)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error listing storage directory %s: %v",
+			store.dir,
+			err,
+		)
+	}
+
+	blobs := make([]BlobInfo, 0, len(infos))
+	for _, info := range infos {
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		blobs = append(blobs, BlobInfo{
+			Key:     info.Name(),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	}
+
+	return blobs, nil
+}
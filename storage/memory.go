@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// MemoryStore keeps blobs in memory. It is meant for development and tests.
+type MemoryStore struct {
+	uploadSigner
+	mu    sync.RWMutex
+	blobs map[string]memoryBlob
+}
+
+// memoryBlob is a blob kept by a MemoryStore
+type memoryBlob struct {
+	data    []byte
+	modTime time.Time
+}
+
+// NewMemoryStore returns an empty store. The urls of the blobs start with
+// baseURL and upload urls are signed with a random secret, which is fine
+// since the blobs are gone once the process is.
+func NewMemoryStore(baseURL string) *MemoryStore {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+
+	return &MemoryStore{
+		uploadSigner: newUploadSigner(baseURL, secret),
+		blobs:        make(map[string]memoryBlob),
+	}
+}
+
+// Put keeps a copy of the file under the given key
+func (store *MemoryStore) Put(key string, file io.ReadSeeker) (string, *response.Error) {
+	e := validateKey(key)
+	if e != nil {
+		return "", e
+	}
+
+	b, err := ioutil.ReadAll(file)
+	if err != nil {
+		return "", response.NewErrorf(
+			http.StatusInternalServerError,
+			"error reading file %s: %v",
+			key,
+			err,
+		)
+	}
+
+	store.mu.Lock()
+	store.blobs[key] = memoryBlob{data: b, modTime: time.Now()}
+	store.mu.Unlock()
+
+	return store.URL(key), nil
+}
+
+// Get returns the blob stored under the given key
+func (store *MemoryStore) Get(key string) (io.ReadCloser, *response.Error) {
+	store.mu.RLock()
+	b, ok := store.blobs[key]
+	store.mu.RUnlock()
+
+	if !ok {
+		return nil, response.NewErrorf(http.StatusNotFound, "key: %s does not exist", key)
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(b.data)), nil
+}
+
+// Stat returns the size of the blob stored under the given key
+func (store *MemoryStore) Stat(key string) (int64, *response.Error) {
+	store.mu.RLock()
+	b, ok := store.blobs[key]
+	store.mu.RUnlock()
+
+	if !ok {
+		return 0, response.NewErrorf(http.StatusNotFound, "key: %s does not exist", key)
+	}
+
+	return int64(len(b.data)), nil
+}
+
+// Delete removes the blob stored under the given key
+func (store *MemoryStore) Delete(key string) *response.Error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if _, ok := store.blobs[key]; !ok {
+		return response.NewErrorf(http.StatusNotFound, "key: %s does not exist", key)
+	}
+
+	delete(store.blobs, key)
+	return nil
+}
+
+// List returns every blob in the store
+func (store *MemoryStore) List() ([]BlobInfo, *response.Error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	blobs := make([]BlobInfo, 0, len(store.blobs))
+	for key, b := range store.blobs {
+		blobs = append(blobs, BlobInfo{Key: key, Size: int64(len(b.data)), ModTime: b.modTime})
+	}
+
+	return blobs, nil
+}
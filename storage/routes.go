@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/go-chi/chi"
+)
+
+// signedStore is a store that accepts uploads sent to its signed urls
+type signedStore interface {
+	Store
+	verifyUpload(key string, q url.Values, contentType string, size int64, now time.Time) *response.Error
+}
+
+// UploadCheck returns an error if a file can no longer be uploaded to the
+// given key, even though the url it was sent to is signed and unexpired
+type UploadCheck func(key string) *response.Error
+
+// Routes returns a router that serves the blobs of the given store. It is
+// used by the local and memory drivers, whose urls point at it. Uploads are
+// refused when check, if given, returns an error for their key.
+func Routes(store Store, check UploadCheck) *chi.Mux {
+	router := chi.NewRouter()
+
+	router.Get("/{key}", getFileHandler(store))
+	router.Put("/{key}", putFileHandler(store, check))
+
+	return router
+}
+
+// swagger:route GET /files/{key} files getFileHandler
+//
+// Gets the blob stored under {key}.
+//
+// Produces:
+//	- application/octet-stream
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  404:
+func getFileHandler(store Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := chi.URLParam(r, "key")
+
+		file, e := store.Get(key)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+		defer file.Close()
+
+		contentType := mime.TypeByExtension(GetExt(key))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		w.Header().Set("Content-Type", contentType)
+
+		io.Copy(w, file)
+	}
+}
+
+// swagger:route PUT /files/{key} files putFileHandler
+//
+// Stores the request body under {key}. The request has to be sent to a
+// signed upload url and match the content type and size it was signed for.
+// Files can not be uploaded to a key once its upload has been confirmed.
+//
+// Consumes:
+//	- application/octet-stream
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  403:
+//  404:
+func putFileHandler(store Store, check UploadCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := chi.URLParam(r, "key")
+
+		signed, ok := store.(signedStore)
+		if !ok {
+			e := response.NewError(http.StatusNotFound, "key: the storage driver does not accept uploads")
+			e.Handle(w)
+			return
+		}
+
+		e := signed.verifyUpload(
+			key,
+			r.URL.Query(),
+			r.Header.Get("Content-Type"),
+			r.ContentLength,
+			time.Now(),
+		)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		if check != nil {
+			e = check(key)
+			if e != nil {
+				e.Handle(w)
+				return
+			}
+		}
+
+		b, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, r.ContentLength))
+		if err != nil {
+			e = response.NewErrorf(http.StatusBadRequest, "body: error reading upload: %v", err)
+			e.Handle(w)
+			return
+		}
+
+		_, e = signed.Put(key, bytes.NewReader(b))
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+	}
+}
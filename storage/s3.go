@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// S3Store stores blobs in an s3 bucket
+type S3Store struct {
+	s          *session.Session
+	uploader   *s3manager.Uploader
+	bucketName string
+}
+
+// NewS3Store sets up an s3 session for the given bucket
+func NewS3Store(region, id, secret, bucketName string) (*S3Store, *response.Error) {
+	s, err := session.NewSession(&aws.Config{
+		Region:      aws.String(region),
+		Credentials: credentials.NewStaticCredentials(id, secret, ""),
+	})
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error creating aws session: %v",
+			err,
+		)
+	}
+
+	return &S3Store{
+		s:          s,
+		uploader:   s3manager.NewUploader(s),
+		bucketName: bucketName,
+	}, nil
+}
+
+// Put uploads the file to the s3 bucket
+func (store *S3Store) Put(key string, file io.ReadSeeker) (string, *response.Error) {
+
+	obInput := &s3manager.UploadInput{
+		Body:   file,
+		Bucket: aws.String(store.bucketName),
+		Key:    aws.String(key),
+	}
+
+	result, err := store.uploader.Upload(obInput)
+	if err != nil {
+		var errMsg string
+		if awsErr, ok := err.(awserr.Error); ok {
+			errMsg = awsErr.Error()
+
+			if origErr := awsErr.OrigErr(); origErr != nil {
+				fmt.Printf("original error: %v", origErr)
+			}
+		}
+		return "", response.NewErrorf(
+			http.StatusInternalServerError,
+			"error uploading file to s3 bucket: %v",
+			errMsg,
+		)
+	}
+
+	return result.Location, nil
+}
+
+// Get downloads an object from the s3 bucket
+func (store *S3Store) Get(key string) (io.ReadCloser, *response.Error) {
+	svc := s3.New(store.s)
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(store.bucketName),
+		Key:    aws.String(key),
+	}
+
+	result, err := svc.GetObject(input)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, response.NewErrorf(
+				http.StatusNotFound,
+				"key: %s does not exist",
+				key,
+			)
+		}
+
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting s3 object: %v",
+			err,
+		)
+	}
+
+	return result.Body, nil
+}
+
+// PresignPut returns a presigned url that accepts a PUT of an object with the
+// given content type and size until the url expires
+func (store *S3Store) PresignPut(key, contentType string, size int64, expires time.Time) (string, *response.Error) {
+	svc := s3.New(store.s)
+
+	req, _ := svc.PutObjectRequest(&s3.PutObjectInput{
+		Bucket:        aws.String(store.bucketName),
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	})
+
+	url, err := req.Presign(time.Until(expires))
+	if err != nil {
+		return "", response.NewErrorf(
+			http.StatusInternalServerError,
+			"error presigning s3 upload: %v",
+			err,
+		)
+	}
+
+	return url, nil
+}
+
+// Stat returns the size of an object in the s3 bucket
+func (store *S3Store) Stat(key string) (int64, *response.Error) {
+	svc := s3.New(store.s)
+
+	result, err := svc.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(store.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "NotFound" {
+			return 0, response.NewErrorf(
+				http.StatusNotFound,
+				"key: %s does not exist",
+				key,
+			)
+		}
+
+		return 0, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error getting s3 object info: %v",
+			err,
+		)
+	}
+
+	return aws.Int64Value(result.ContentLength), nil
+}
+
+// URL returns the url of an object in the s3 bucket
+func (store *S3Store) URL(key string) string {
+	svc := s3.New(store.s)
+
+	req, _ := svc.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(store.bucketName),
+		Key:    aws.String(key),
+	})
+	if err := req.Build(); err != nil {
+		return ""
+	}
+
+	return req.HTTPRequest.URL.String()
+}
+
+// Delete deletes an object from the s3 media bucket
+func (store *S3Store) Delete(key string) *response.Error {
+	svc := s3.New(store.s)
+
+	input := &s3.DeleteObjectInput{
+		Bucket: aws.String(store.bucketName),
+		Key:    aws.String(key),
+	}
+
+	result, err := svc.DeleteObject(input)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			default:
+				fmt.Println(aerr.Error())
+			}
+		} else {
+			// Print the error, cast err to awserr.Error to get the Code and
+			// Message from an error.
+			fmt.Println(err.Error())
+		}
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"error deleting s3 object: %v",
+			err,
+		)
+	}
+
+	fmt.Println(result)
+	return nil
+}
+
+// List returns every object in the s3 bucket
+func (store *S3Store) List() ([]BlobInfo, *response.Error) {
+	svc := s3.New(store.s)
+
+	blobs := make([]BlobInfo, 0)
+	err := svc.ListObjectsV2Pages(
+		&s3.ListObjectsV2Input{Bucket: aws.String(store.bucketName)},
+		func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+			for _, obj := range page.Contents {
+				blobs = append(blobs, BlobInfo{
+					Key:     aws.StringValue(obj.Key),
+					Size:    aws.Int64Value(obj.Size),
+					ModTime: aws.TimeValue(obj.LastModified),
+				})
+			}
+			return true
+		},
+	)
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error listing s3 objects: %v",
+			err,
+		)
+	}
+
+	return blobs, nil
+}
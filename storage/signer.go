@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// uploadSigner emulates presigned upload urls for the stores that serve
+// their own files. The url's query holds the upload's constraints and an
+// hmac of them.
+type uploadSigner struct {
+	secret  []byte
+	baseURL string
+}
+
+// newUploadSigner returns a signer for urls under the given base url that
+// signs them with the given secret
+func newUploadSigner(baseURL string, secret []byte) uploadSigner {
+	return uploadSigner{secret: secret, baseURL: baseURL}
+}
+
+// sign returns the signature of an upload's constraints
+func (s *uploadSigner) sign(key, contentType, size, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + contentType + "\n" + size + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// URL returns the url the blob stored under the given key is fetched from
+func (s *uploadSigner) URL(key string) string {
+	return joinURL(s.baseURL, key)
+}
+
+// PresignPut returns a signed url that accepts a PUT of a file with the given
+// content type and size until the url expires
+func (s *uploadSigner) PresignPut(key, contentType string, size int64, expires time.Time) (string, *response.Error) {
+	e := validateKey(key)
+	if e != nil {
+		return "", e
+	}
+
+	sizeStr := strconv.FormatInt(size, 10)
+	expiresStr := strconv.FormatInt(expires.Unix(), 10)
+
+	q := url.Values{}
+	q.Set("type", contentType)
+	q.Set("size", sizeStr)
+	q.Set("expires", expiresStr)
+	q.Set("signature", s.sign(key, contentType, sizeStr, expiresStr))
+
+	return s.URL(key) + "?" + q.Encode(), nil
+}
+
+// verifyUpload checks that an upload matches the signed url it was sent to
+func (s *uploadSigner) verifyUpload(key string, q url.Values, contentType string, size int64, now time.Time) *response.Error {
+	expected := s.sign(key, q.Get("type"), q.Get("size"), q.Get("expires"))
+	if !hmac.Equal([]byte(expected), []byte(q.Get("signature"))) {
+		return response.NewError(http.StatusForbidden, "signature: the upload url is not valid")
+	}
+
+	expires, err := strconv.ParseInt(q.Get("expires"), 10, 64)
+	if err != nil || now.Unix() > expires {
+		return response.NewError(http.StatusForbidden, "expires: the upload url has expired")
+	}
+
+	if contentType != q.Get("type") {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"contentType: the upload has to be %s",
+			q.Get("type"),
+		)
+	}
+
+	if strconv.FormatInt(size, 10) != q.Get("size") {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"size: the upload has to be %s bytes",
+			q.Get("size"),
+		)
+	}
+
+	return nil
+}
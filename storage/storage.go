@@ -0,0 +1,142 @@
+// Package storage provides the blob stores that uploaded files are kept in.
+// The store is picked with the storage_driver config value: s3, local, or
+// memory. The local and memory drivers emulate presigned upload urls with
+// signed urls. The local driver signs them with the storage_secret config
+// value, which it requires, and the memory driver with a random secret.
+package storage
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/spf13/viper"
+)
+
+// Store is a place blobs can be put in, fetched from, and deleted from
+type Store interface {
+	// Put stores the file under the given key and returns the url the file
+	// can be fetched from
+	Put(key string, file io.ReadSeeker) (string, *response.Error)
+
+	// Get returns the blob stored under the given key. The caller is
+	// responsible for closing it.
+	Get(key string) (io.ReadCloser, *response.Error)
+
+	// Delete removes the blob stored under the given key
+	Delete(key string) *response.Error
+}
+
+// Presigner is a Store that can hand out urls that files are uploaded to
+// directly, without going through the api
+type Presigner interface {
+	Store
+
+	// PresignPut returns a url that accepts a PUT of a file with the given
+	// content type and size until the url expires
+	PresignPut(key, contentType string, size int64, expires time.Time) (string, *response.Error)
+
+	// Stat returns the size, in bytes, of the blob stored under the given key
+	Stat(key string) (int64, *response.Error)
+
+	// URL returns the url the blob stored under the given key is fetched from
+	URL(key string) string
+}
+
+// BlobInfo describes a blob in a store
+type BlobInfo struct {
+	// Key is the key the blob is stored under
+	Key string
+
+	// Size is the size of the blob in bytes
+	Size int64
+
+	// ModTime is when the blob was last written
+	ModTime time.Time
+}
+
+// Lister is a Store that can list the blobs stored in it
+type Lister interface {
+	Store
+
+	// List returns every blob in the store
+	List() ([]BlobInfo, *response.Error)
+}
+
+const (
+	// DriverS3 stores blobs in an s3 bucket
+	DriverS3 = "s3"
+
+	// DriverLocal stores blobs in a directory on the local disk
+	DriverLocal = "local"
+
+	// DriverMemory keeps blobs in memory. Blobs only last as long as the
+	// process.
+	DriverMemory = "memory"
+)
+
+// FromConfig returns the store for the storage_driver config value. The s3
+// driver is used when storage_driver is not set. filesURL is where the files
+// route is served and is the default base of the urls of the local and memory
+// drivers.
+func FromConfig(filesURL string) (Store, *response.Error) {
+	baseURL := viper.GetString("storage_base_url")
+	if baseURL == "" {
+		baseURL = filesURL
+	}
+
+	driver := viper.GetString("storage_driver")
+	switch driver {
+	case "", DriverS3:
+		return NewS3Store(
+			viper.GetString("s3_region"),
+			viper.GetString("s3_id"),
+			viper.GetString("s3_secret"),
+			viper.GetString("s3_bucket_name"),
+		)
+	case DriverLocal:
+		dir := viper.GetString("storage_local_dir")
+		if dir == "" {
+			dir = "./uploads"
+		}
+
+		return NewLocalStore(dir, baseURL, []byte(viper.GetString("storage_secret")))
+	case DriverMemory:
+		return NewMemoryStore(baseURL), nil
+	}
+
+	return nil, response.NewErrorf(
+		http.StatusInternalServerError,
+		"storage_driver: %s is not a storage driver",
+		driver,
+	)
+}
+
+// GetKey returns the object key from an objects url
+func GetKey(url string) string {
+	s := strings.Split(url, "/")
+	return s[len(s)-1]
+}
+
+// GetExt returns the ext, including the dot, for the file name
+func GetExt(fileName string) string {
+	s := strings.Split(fileName, ".")
+	return "." + s[len(s)-1]
+}
+
+// validateKey makes sure a key can be used as a file name without escaping
+// the store's directory
+func validateKey(key string) *response.Error {
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+		return response.NewErrorf(http.StatusBadRequest, "key: %s is not a valid key", key)
+	}
+
+	return nil
+}
+
+// joinURL returns the url of the key under the given base url
+func joinURL(baseURL, key string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/" + key
+}
@@ -0,0 +1,266 @@
+// +build unit
+
+package storage
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// testStore puts, gets, and deletes a blob in the given store
+func testStore(t *testing.T, store Store) {
+	url, e := store.Put("photo.jpg", strings.NewReader("image data"))
+	if e != nil {
+		t.Fatalf("expected no error putting got %s", e.JSON())
+	}
+	if url != "/files/photo.jpg" {
+		t.Errorf("expected url /files/photo.jpg got %s", url)
+	}
+
+	file, e := store.Get(GetKey(url))
+	if e != nil {
+		t.Fatalf("expected no error getting got %s", e.JSON())
+	}
+	b, err := ioutil.ReadAll(file)
+	file.Close()
+	if err != nil {
+		t.Fatalf("error reading blob: %v", err)
+	}
+	if string(b) != "image data" {
+		t.Errorf("expected image data got %s", b)
+	}
+
+	if e = store.Delete("photo.jpg"); e != nil {
+		t.Fatalf("expected no error deleting got %s", e.JSON())
+	}
+
+	if _, e = store.Get("photo.jpg"); e == nil {
+		t.Errorf("expected an error getting a deleted blob")
+	}
+	if e = store.Delete("photo.jpg"); e == nil || e.Code() != http.StatusNotFound {
+		t.Errorf("expected a not found error deleting a deleted blob")
+	}
+}
+
+// testLister lists the blobs in the given empty store
+func testLister(t *testing.T, store Lister) {
+	before := time.Now().Add(-time.Minute)
+	for _, key := range []string{"a.jpg", "b.png"} {
+		if _, e := store.Put(key, strings.NewReader(key)); e != nil {
+			t.Fatalf("expected no error putting got %s", e.JSON())
+		}
+	}
+
+	blobs, e := store.List()
+	if e != nil {
+		t.Fatalf("expected no error listing got %s", e.JSON())
+	}
+	if len(blobs) != 2 {
+		t.Fatalf("expected 2 blobs got %d", len(blobs))
+	}
+
+	for _, b := range blobs {
+		if b.Size != int64(len(b.Key)) {
+			t.Errorf("expected %s to be %d bytes got %d", b.Key, len(b.Key), b.Size)
+		}
+		if b.ModTime.Before(before) {
+			t.Errorf("expected %s to have been written recently got %v", b.Key, b.ModTime)
+		}
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore("/files/"))
+	testLister(t, NewMemoryStore("/files/"))
+}
+
+func TestLocalStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scavenge-storage")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, e := NewLocalStore(dir, "/files", []byte("secret"))
+	if e != nil {
+		t.Fatalf("expected no error got %s", e.JSON())
+	}
+
+	testStore(t, store)
+	testLister(t, store)
+}
+
+func TestLocalStoreRequiresSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scavenge-storage")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	_, e := NewLocalStore(dir, "/files", nil)
+	if e == nil {
+		t.Fatalf("expected an error creating a local store without a secret")
+	}
+}
+
+func TestLocalStoreRejectsPaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scavenge-storage")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, e := NewLocalStore(dir, "/files", []byte("secret"))
+	if e != nil {
+		t.Fatalf("expected no error got %s", e.JSON())
+	}
+
+	for _, key := range []string{"", "..", "../secret", `a\b`} {
+		if _, e = store.Put(key, strings.NewReader("data")); e == nil {
+			t.Errorf("expected an error putting key %q", key)
+		}
+		if _, e = store.Get(key); e == nil {
+			t.Errorf("expected an error getting key %q", key)
+		}
+	}
+}
+
+func TestGetKeyAndExt(t *testing.T) {
+	if key := GetKey("https://bucket.s3.amazonaws.com/abc.png"); key != "abc.png" {
+		t.Errorf("expected abc.png got %s", key)
+	}
+	if ext := GetExt("holiday.photo.png"); ext != ".png" {
+		t.Errorf("expected .png got %s", ext)
+	}
+}
+
+func TestGetFileHandler(t *testing.T) {
+	store := NewMemoryStore("/files/")
+	if _, e := store.Put("photo.png", strings.NewReader("png data")); e != nil {
+		t.Fatalf("expected no error got %s", e.JSON())
+	}
+
+	router := Routes(store, nil)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/photo.png", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code %d got %d", http.StatusOK, rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("expected content type image/png got %s", ct)
+	}
+	if rr.Body.String() != "png data" {
+		t.Errorf("expected png data got %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/missing.png", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected code %d got %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+// putSigned sends body to the given upload url
+func putSigned(router http.Handler, uploadURL, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPut, strings.TrimPrefix(uploadURL, "/files"), strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestPresignedUpload(t *testing.T) {
+	store := NewMemoryStore("/files/")
+	router := Routes(store, nil)
+
+	uploadURL, e := store.PresignPut("photo.jpg", "image/jpeg", 10, time.Now().Add(time.Minute))
+	if e != nil {
+		t.Fatalf("expected no error got %s", e.JSON())
+	}
+
+	cases := []struct {
+		name        string
+		url         string
+		contentType string
+		body        string
+		code        int
+	}{
+		{name: "wrong content type", url: uploadURL, contentType: "image/png", body: "0123456789", code: http.StatusBadRequest},
+		{name: "wrong size", url: uploadURL, contentType: "image/jpeg", body: "0123", code: http.StatusBadRequest},
+		{name: "tampered", url: strings.Replace(uploadURL, "size=10", "size=11", 1), contentType: "image/jpeg", body: "01234567890", code: http.StatusForbidden},
+		{name: "other key", url: strings.Replace(uploadURL, "photo.jpg", "other.jpg", 1), contentType: "image/jpeg", body: "0123456789", code: http.StatusForbidden},
+		{name: "valid", url: uploadURL, contentType: "image/jpeg", body: "0123456789", code: http.StatusOK},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rr := putSigned(router, c.url, c.contentType, c.body)
+			if rr.Code != c.code {
+				t.Errorf("expected code %d got %d: %s", c.code, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	size, e := store.Stat("photo.jpg")
+	if e != nil {
+		t.Fatalf("expected no error got %s", e.JSON())
+	}
+	if size != 10 {
+		t.Errorf("expected size 10 got %d", size)
+	}
+	if url := store.URL("photo.jpg"); url != "/files/photo.jpg" {
+		t.Errorf("expected url /files/photo.jpg got %s", url)
+	}
+}
+
+func TestPresignedUploadExpired(t *testing.T) {
+	store := NewMemoryStore("/files/")
+
+	uploadURL, e := store.PresignPut("photo.jpg", "image/jpeg", 4, time.Now().Add(-time.Minute))
+	if e != nil {
+		t.Fatalf("expected no error got %s", e.JSON())
+	}
+
+	rr := putSigned(Routes(store, nil), uploadURL, "image/jpeg", "data")
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected code %d got %d", http.StatusForbidden, rr.Code)
+	}
+
+	if _, e = store.Stat("photo.jpg"); e == nil {
+		t.Errorf("expected the expired upload to not be stored")
+	}
+}
+
+func TestPresignedUploadCheck(t *testing.T) {
+	store := NewMemoryStore("/files/")
+	confirmed := map[string]bool{"photo.jpg": true}
+	router := Routes(store, func(key string) *response.Error {
+		if confirmed[key] {
+			return response.NewError(http.StatusForbidden, "key: the upload has been confirmed")
+		}
+		return nil
+	})
+
+	uploadURL, e := store.PresignPut("photo.jpg", "image/jpeg", 4, time.Now().Add(time.Minute))
+	if e != nil {
+		t.Fatalf("expected no error got %s", e.JSON())
+	}
+
+	rr := putSigned(router, uploadURL, "image/jpeg", "data")
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected code %d got %d", http.StatusForbidden, rr.Code)
+	}
+
+	if _, e = store.Stat("photo.jpg"); e == nil {
+		t.Errorf("expected the upload to a confirmed key to not be stored")
+	}
+}
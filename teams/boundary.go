@@ -0,0 +1,121 @@
+package teams
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/events"
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// boundaryAlert returns the alert for the team being seen at the location,
+// or nil if the location is inside the boundary
+func boundaryAlert(boundary *db.HuntBoundaryDB, l *db.LocationDB) *db.BoundaryAlertDB {
+	distance := boundary.DistanceOutside(float64(l.Latitude), float64(l.Longitude))
+	if distance == 0 {
+		return nil
+	}
+
+	return &db.BoundaryAlertDB{
+		TeamID:     l.TeamID,
+		LocationID: l.ID,
+		Latitude:   l.Latitude,
+		Longitude:  l.Longitude,
+		Distance:   distance,
+		LeftAt:     l.TimeStamp,
+	}
+}
+
+// boundaryFlag returns the flag for a submission made from the given point,
+// or nil if the point is inside the boundary. The boundary can be nil.
+func boundaryFlag(boundary *db.HuntBoundaryDB, lat, lng float64) *db.SubmissionFlagDB {
+	if boundary == nil {
+		return nil
+	}
+
+	distance := boundary.DistanceOutside(lat, lng)
+	if distance == 0 {
+		return nil
+	}
+
+	return &db.SubmissionFlagDB{
+		Reason:    db.FlagOutOfBounds,
+		Detail:    fmt.Sprintf("submitted %.0f meters outside the hunt's boundary", distance),
+		Latitude:  float32(lat),
+		Longitude: float32(lng),
+	}
+}
+
+// teamBoundary returns the boundary of the team's hunt, nil if it has none
+func teamBoundary(teamID int) (*db.HuntBoundaryDB, *db.TeamDB, *response.Error) {
+	team, e := db.GetTeam(teamID)
+	if e != nil {
+		return nil, nil, e
+	}
+
+	boundary, e := db.GetHuntBoundary(team.HuntID)
+	if e != nil {
+		return nil, nil, e
+	}
+
+	return boundary, team, nil
+}
+
+// CheckBoundary checks the team's stored locations against the boundary of
+// its hunt. An alert is raised when the team is first seen outside, and is
+// closed when the team is seen inside again. Both are published to the
+// team and the hunt's owners. The locations have already been stored, so
+// errors are logged rather than returned.
+func CheckBoundary(teamID int, locs []*db.LocationDB) {
+	if len(locs) == 0 {
+		return
+	}
+
+	boundary, team, e := teamBoundary(teamID)
+	if e != nil {
+		log.Printf("error checking the boundary for team %d: %s\n", teamID, e.JSON())
+		return
+	}
+	if boundary == nil {
+		return
+	}
+
+	sorted := append([]*db.LocationDB{}, locs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].TimeStamp.Before(sorted[j].TimeStamp)
+	})
+
+	for _, l := range sorted {
+		alert := boundaryAlert(boundary, l)
+		if alert == nil {
+			returned, e := db.ResolveBoundaryAlert(teamID, l.TimeStamp)
+			if e != nil {
+				log.Printf("error checking the boundary for team %d: %s\n", teamID, e.JSON())
+				return
+			}
+
+			if returned != nil {
+				events.Publish(team.HuntID, teamID, events.Boundary, &events.BoundaryData{
+					Action: events.BoundaryReturned,
+					Alert:  returned,
+				})
+			}
+			continue
+		}
+
+		raised, e := alert.Insert()
+		if e != nil {
+			log.Printf("error checking the boundary for team %d: %s\n", teamID, e.JSON())
+			return
+		}
+
+		if raised {
+			events.Publish(team.HuntID, teamID, events.Boundary, &events.BoundaryData{
+				Action: events.BoundaryLeft,
+				Alert:  alert,
+			})
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// +build unit
+
+package teams
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/geo"
+)
+
+var testBoundary = &db.HuntBoundaryDB{
+	HuntID: 1,
+	Points: []geo.Point{
+		{Latitude: 40.0, Longitude: -74.0},
+		{Latitude: 40.0, Longitude: -73.9},
+		{Latitude: 40.1, Longitude: -73.9},
+		{Latitude: 40.1, Longitude: -74.0},
+	},
+}
+
+func TestBoundaryAlert(t *testing.T) {
+	at := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	inside := &db.LocationDB{ID: 1, TeamID: 2, Latitude: 40.05, Longitude: -73.95, TimeStamp: at}
+	if a := boundaryAlert(testBoundary, inside); a != nil {
+		t.Errorf("expected no alert inside the boundary got %+v", a)
+	}
+
+	outside := &db.LocationDB{ID: 3, TeamID: 2, Latitude: 40.11, Longitude: -73.95, TimeStamp: at}
+	a := boundaryAlert(testBoundary, outside)
+	if a == nil {
+		t.Fatalf("expected an alert outside the boundary")
+	}
+	if a.TeamID != 2 || a.LocationID != 3 || !a.LeftAt.Equal(at) || !a.Open() {
+		t.Errorf("expected an open alert for the location got %+v", a)
+	}
+	if a.Distance < 1000 || a.Distance > 1200 {
+		t.Errorf("expected the alert to be about 1100 meters outside got %.0f", a.Distance)
+	}
+}
+
+func TestBoundaryFlag(t *testing.T) {
+	if f := boundaryFlag(nil, 40.11, -73.95); f != nil {
+		t.Errorf("expected no flag without a boundary got %+v", f)
+	}
+
+	if f := boundaryFlag(testBoundary, 40.05, -73.95); f != nil {
+		t.Errorf("expected no flag inside the boundary got %+v", f)
+	}
+
+	f := boundaryFlag(testBoundary, 40.11, -73.95)
+	if f == nil {
+		t.Fatalf("expected a flag outside the boundary")
+	}
+	if f.Reason != db.FlagOutOfBounds {
+		t.Errorf("expected reason %s got %s", db.FlagOutOfBounds, f.Reason)
+	}
+}
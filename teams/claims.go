@@ -0,0 +1,272 @@
+package teams
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// SubmissionLocation is where a submission was made from. It is optional
+// unless the item has a target point.
+type SubmissionLocation struct {
+	Latitude  *float64 `json:"latitude,omitempty" valid:"-"`
+	Longitude *float64 `json:"longitude,omitempty" valid:"-"`
+}
+
+// validate validates the submission location
+func (loc *SubmissionLocation) validate() *response.Error {
+	if (loc.Latitude == nil) != (loc.Longitude == nil) {
+		return response.NewError(
+			http.StatusBadRequest,
+			"location: both latitude and longitude are required",
+		)
+	}
+
+	if loc.Latitude == nil {
+		return nil
+	}
+
+	req := CheckpointRequest{Latitude: *loc.Latitude, Longitude: *loc.Longitude}
+	return req.Validate(nil)
+}
+
+// AnswerRequest is the body of a text item submission
+type AnswerRequest struct {
+	Answer string `json:"answer" valid:"-"`
+
+	SubmissionLocation `valid:"-"`
+}
+
+// Validate validates the answer request
+func (req *AnswerRequest) Validate(r *http.Request) *response.Error {
+	if strings.TrimSpace(req.Answer) == "" {
+		return response.NewError(http.StatusBadRequest, "answer: an answer is required")
+	}
+
+	return req.SubmissionLocation.validate()
+}
+
+// CheckInRequest is the body of a checkin item submission. The code is
+// usually read from a QR code.
+type CheckInRequest struct {
+	Code string `json:"code" valid:"-"`
+
+	SubmissionLocation `valid:"-"`
+}
+
+// Validate validates the check in request
+func (req *CheckInRequest) Validate(r *http.Request) *response.Error {
+	if strings.TrimSpace(req.Code) == "" {
+		return response.NewError(http.StatusBadRequest, "code: a code is required")
+	}
+
+	return req.SubmissionLocation.validate()
+}
+
+// CheckpointRequest is the body of a gps item submission
+type CheckpointRequest struct {
+	Latitude  float64 `json:"latitude" valid:"-"`
+	Longitude float64 `json:"longitude" valid:"-"`
+}
+
+// Validate validates the checkpoint request
+func (req *CheckpointRequest) Validate(r *http.Request) *response.Error {
+	e := response.NewNilError()
+
+	if req.Latitude < -90 || req.Latitude > 90 {
+		e.Add(http.StatusBadRequest, "latitude: must be a number between -90 and 90")
+	}
+
+	if req.Longitude < -180 || req.Longitude > 180 {
+		e.Add(http.StatusBadRequest, "longitude: must be a number between -180 and 180")
+	}
+
+	return e.GetError()
+}
+
+// itemForTeam returns the team and the item with the given ids if the item is
+// part of the team's hunt
+func itemForTeam(teamID, itemID int) (*db.TeamDB, *db.ItemDB, *response.Error) {
+	team, e := db.GetTeam(teamID)
+	if e != nil {
+		return nil, nil, e
+	}
+
+	item, e := db.GetItem(itemID)
+	if e != nil {
+		return nil, nil, e
+	}
+
+	if item.HuntID != team.HuntID {
+		return nil, nil, response.NewErrorf(
+			http.StatusBadRequest,
+			"item_id: item %d is not part of team %d's hunt",
+			itemID,
+			teamID,
+		)
+	}
+
+	e = requireVisible(teamID, item)
+	if e != nil {
+		return nil, nil, e
+	}
+
+	return team, item, nil
+}
+
+// requireVisible returns an error if the item is hidden from the team, either
+// because the team has not completed the items it depends on or because it
+// is outside of its visible window
+func requireVisible(teamID int, item *db.ItemDB) *response.Error {
+	completed, e := db.GetCompletedItemIDs(teamID)
+	if e != nil {
+		return e
+	}
+
+	if !item.VisibleTo(completed, time.Now()) {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"item_id: item %d is not available to team %d",
+			item.ID,
+			teamID,
+		)
+	}
+
+	return nil
+}
+
+// claimableItem returns the item with the given id if it is of the given type
+// and part of the team's hunt
+func claimableItem(teamID, itemID int, itemType string) (*db.ItemDB, *response.Error) {
+	_, item, e := itemForTeam(teamID, itemID)
+	if e != nil {
+		return nil, e
+	}
+
+	if item.Type != itemType {
+		return nil, response.NewErrorf(
+			http.StatusBadRequest,
+			"item_id: item %d is a %s item, not a %s item",
+			itemID,
+			item.Type,
+			itemType,
+		)
+	}
+
+	return item, nil
+}
+
+// insertClaim verifies the location the claim was made from and stores it.
+// Items with a target point can not be claimed without a location.
+func insertClaim(item *db.ItemDB, claim *db.ItemClaimDB, loc *SubmissionLocation) (*db.ItemClaimDB, *response.Error) {
+	if loc.Latitude == nil {
+		if item.HasTarget() {
+			return nil, response.NewErrorf(
+				http.StatusBadRequest,
+				"location: item %d can only be claimed with your location",
+				item.ID,
+			)
+		}
+
+		e := claim.Insert()
+		if e != nil {
+			return nil, e
+		}
+
+		return claim, nil
+	}
+
+	claim.Latitude = float32(*loc.Latitude)
+	claim.Longitude = float32(*loc.Longitude)
+
+	flags, e := VerifySubmission(claim.TeamID, item, *loc.Latitude, *loc.Longitude, time.Now())
+	if e != nil {
+		return nil, e
+	}
+
+	e = claim.Insert()
+	if e != nil {
+		return nil, e
+	}
+
+	e = RecordFlags(flags, 0, claim.ID)
+	if e != nil {
+		return nil, e
+	}
+
+	return claim, nil
+}
+
+// SubmitAnswer claims the text item for the team if the answer matches one
+// of the item's answers
+func SubmitAnswer(teamID, itemID, userID int, req *AnswerRequest) (*db.ItemClaimDB, *response.Error) {
+	item, e := claimableItem(teamID, itemID, db.ItemTypeText)
+	if e != nil {
+		return nil, e
+	}
+
+	if !item.MatchesAnswer(req.Answer) {
+		return nil, response.NewError(http.StatusBadRequest, "answer: that is not the right answer")
+	}
+
+	claim := db.ItemClaimDB{
+		ItemID: itemID,
+		TeamID: teamID,
+		UserID: userID,
+		Answer: req.Answer,
+	}
+
+	return insertClaim(item, &claim, &req.SubmissionLocation)
+}
+
+// CheckIn claims the checkin item for the team if the code is the item's code
+func CheckIn(teamID, itemID, userID int, req *CheckInRequest) (*db.ItemClaimDB, *response.Error) {
+	item, e := claimableItem(teamID, itemID, db.ItemTypeCheckIn)
+	if e != nil {
+		return nil, e
+	}
+
+	if !item.MatchesCheckInCode(req.Code) {
+		return nil, response.NewError(http.StatusBadRequest, "code: that is not the right code")
+	}
+
+	claim := db.ItemClaimDB{
+		ItemID: itemID,
+		TeamID: teamID,
+		UserID: userID,
+		Answer: req.Code,
+	}
+
+	return insertClaim(item, &claim, &req.SubmissionLocation)
+}
+
+// ReachCheckpoint claims the gps item for the team if the given point is
+// within the item's radius of its checkpoint
+func ReachCheckpoint(teamID, itemID, userID int, req *CheckpointRequest) (*db.ItemClaimDB, *response.Error) {
+	item, e := claimableItem(teamID, itemID, db.ItemTypeGPS)
+	if e != nil {
+		return nil, e
+	}
+
+	dist := item.DistanceTo(req.Latitude, req.Longitude)
+	if dist > float64(item.Radius) {
+		return nil, response.NewErrorf(
+			http.StatusBadRequest,
+			"location: you are %.0f meters from the checkpoint, get within %d meters",
+			dist,
+			item.Radius,
+		)
+	}
+
+	claim := db.ItemClaimDB{
+		ItemID: itemID,
+		TeamID: teamID,
+		UserID: userID,
+	}
+	loc := SubmissionLocation{Latitude: &req.Latitude, Longitude: &req.Longitude}
+
+	return insertClaim(item, &claim, &loc)
+}
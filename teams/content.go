@@ -0,0 +1,164 @@
+package teams
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/cljohnson4343/scavenge/storage"
+)
+
+// uploadExtensions maps the content types media can be uploaded with to the
+// extensions files of that type can have. The first extension is the one
+// the file is stored with. Only the photo formats package imaging can strip
+// the metadata of are accepted, so not webp or heic.
+var uploadExtensions = map[string][]string{
+	"image/jpeg":      {".jpg", ".jpeg"},
+	"image/png":       {".png"},
+	"image/gif":       {".gif"},
+	"video/mp4":       {".mp4", ".m4v"},
+	"video/quicktime": {".mov", ".qt"},
+}
+
+// ftypBrands maps the major brands of ISO base media files, like mp4, mov,
+// and heic files, to their content type
+var ftypBrands = map[string]string{
+	"qt  ": "video/quicktime",
+	"heic": "image/heic",
+	"heix": "image/heic",
+	"hevc": "image/heic",
+	"hevx": "image/heic",
+	"mif1": "image/heic",
+	"msf1": "image/heic",
+	"isom": "video/mp4",
+	"iso2": "video/mp4",
+	"iso4": "video/mp4",
+	"iso5": "video/mp4",
+	"iso6": "video/mp4",
+	"mp41": "video/mp4",
+	"mp42": "video/mp4",
+	"avc1": "video/mp4",
+	"M4V ": "video/mp4",
+	"dash": "video/mp4",
+}
+
+// Scanner checks uploaded files before they are stored, for example with
+// an external virus scanner
+type Scanner interface {
+	// Scan returns an error if the file should not be stored
+	Scan(contentType string, data []byte) error
+}
+
+// nopScanner is the Scanner used when none is set. It accepts every file.
+type nopScanner struct{}
+
+// Scan accepts the file
+func (nopScanner) Scan(contentType string, data []byte) error {
+	return nil
+}
+
+// scanner checks every uploaded file
+var scanner Scanner = nopScanner{}
+
+// SetScanner sets the Scanner that checks uploaded files. A nil Scanner
+// accepts every file.
+func SetScanner(s Scanner) {
+	if s == nil {
+		s = nopScanner{}
+	}
+
+	scanner = s
+}
+
+// sniffContentType returns the content type of the file's contents, without
+// any parameters
+func sniffContentType(data []byte) string {
+	// ISO base media files start with an ftyp box that names their format
+	if len(data) >= 12 && string(data[4:8]) == "ftyp" {
+		if contentType, ok := ftypBrands[string(data[8:12])]; ok {
+			return contentType
+		}
+
+		return "application/octet-stream"
+	}
+
+	contentType := http.DetectContentType(data)
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+
+	return strings.TrimSpace(contentType)
+}
+
+// checkContent checks an uploaded file is at most limit bytes, is one of
+// the types media can be uploaded as, has an extension that matches its
+// type, and passes the scanner. The file's content type is returned.
+func checkContent(fileName string, data []byte, limit int64) (string, *response.Error) {
+	if len(data) == 0 {
+		return "", response.NewError(http.StatusBadRequest, "file: the file is empty")
+	}
+
+	if int64(len(data)) > limit {
+		return "", response.NewErrorf(
+			http.StatusBadRequest,
+			"file: the file is %d bytes but files can be at most %d bytes",
+			len(data),
+			limit,
+		)
+	}
+
+	contentType := sniffContentType(data)
+	exts, ok := uploadExtensions[contentType]
+	if !ok {
+		return "", response.NewErrorf(
+			http.StatusBadRequest,
+			"file: %s files can not be uploaded",
+			contentType,
+		)
+	}
+
+	ext := strings.ToLower(storage.GetExt(fileName))
+	matches := false
+	for _, e := range exts {
+		if e == ext {
+			matches = true
+			break
+		}
+	}
+	if !matches {
+		return "", response.NewErrorf(
+			http.StatusBadRequest,
+			"file: %s is a %s file and can not have the extension %s",
+			fileName,
+			contentType,
+			ext,
+		)
+	}
+
+	err := scanner.Scan(contentType, data)
+	if err != nil {
+		return "", response.NewErrorf(
+			http.StatusBadRequest,
+			"file: the file was rejected: %v",
+			err,
+		)
+	}
+
+	return contentType, nil
+}
+
+// uploadLimit returns the largest media file, in bytes, the team can upload
+func uploadLimit(teamID int) (int64, *response.Error) {
+	team, e := db.GetTeam(teamID)
+	if e != nil {
+		return 0, e
+	}
+
+	hunt, e := db.GetHunt(team.HuntID)
+	if e != nil {
+		return 0, e
+	}
+
+	return hunt.UploadLimit(), nil
+}
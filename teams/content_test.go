@@ -0,0 +1,102 @@
+// +build unit
+
+package teams
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+)
+
+// ftyp returns the start of an ISO base media file with the given brand
+func ftyp(brand string) []byte {
+	return append([]byte{0, 0, 0, 24, 'f', 't', 'y', 'p'}, []byte(brand+"\x00\x00\x00\x00")...)
+}
+
+func pngData(t *testing.T) []byte {
+	buf := bytes.Buffer{}
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatalf("error encoding png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestSniffContentType(t *testing.T) {
+	cases := []struct {
+		name        string
+		data        []byte
+		contentType string
+	}{
+		{name: "png", data: pngData(t), contentType: "image/png"},
+		{name: "jpeg", data: []byte("\xFF\xD8\xFF\xE0\x00\x10JFIF"), contentType: "image/jpeg"},
+		{name: "gif", data: []byte("GIF89a"), contentType: "image/gif"},
+		{name: "mp4", data: ftyp("isom"), contentType: "video/mp4"},
+		{name: "mov", data: ftyp("qt  "), contentType: "video/quicktime"},
+		{name: "heic", data: ftyp("heic"), contentType: "image/heic"},
+		{name: "unknown brand", data: ftyp("crx "), contentType: "application/octet-stream"},
+		{name: "html", data: []byte("<html><body></body></html>"), contentType: "text/html"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if contentType := sniffContentType(c.data); contentType != c.contentType {
+				t.Errorf("expected %s got %s", c.contentType, contentType)
+			}
+		})
+	}
+}
+
+// rejectScanner rejects every file
+type rejectScanner struct{}
+
+func (rejectScanner) Scan(contentType string, data []byte) error {
+	return errors.New("infected")
+}
+
+func TestCheckContent(t *testing.T) {
+	img := pngData(t)
+
+	cases := []struct {
+		name        string
+		fileName    string
+		data        []byte
+		limit       int64
+		scanner     Scanner
+		contentType string
+	}{
+		{name: "png", fileName: "photo.png", data: img, limit: 1 << 20, contentType: "image/png"},
+		{name: "upper case extension", fileName: "photo.PNG", data: img, limit: 1 << 20, contentType: "image/png"},
+		{name: "second extension", fileName: "clip.m4v", data: ftyp("mp42"), limit: 1 << 20, contentType: "video/mp4"},
+		{name: "mismatched extension", fileName: "photo.jpg", data: img, limit: 1 << 20},
+		{name: "no extension", fileName: "photo", data: img, limit: 1 << 20},
+		{name: "too large", fileName: "photo.png", data: img, limit: int64(len(img) - 1)},
+		{name: "empty", fileName: "photo.png", limit: 1 << 20},
+		{name: "not media", fileName: "page.html", data: []byte("<html></html>"), limit: 1 << 20},
+		{name: "heic", fileName: "photo.heic", data: ftyp("heic"), limit: 1 << 20},
+		{name: "rejected by scanner", fileName: "photo.png", data: img, limit: 1 << 20, scanner: rejectScanner{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			SetScanner(c.scanner)
+			defer SetScanner(nil)
+
+			contentType, e := checkContent(c.fileName, c.data, c.limit)
+			if c.contentType != "" {
+				if e != nil {
+					t.Fatalf("expected no error got %s", e.JSON())
+				}
+				if contentType != c.contentType {
+					t.Errorf("expected %s got %s", c.contentType, contentType)
+				}
+				return
+			}
+
+			if e == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
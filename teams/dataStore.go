@@ -78,9 +78,15 @@ func InsertTeam(userID int, team *Team) *response.Error {
 	return ownerRole.AddTo(userID)
 }
 
-// DeleteTeam deletes the team with the given teamID
+// DeleteTeam deletes the team with the given teamID. The freed team spot is
+// given to the hunt's waitlist.
 func DeleteTeam(teamID int) *response.Error {
-	e := db.DeleteTeam(teamID)
+	team, e := db.GetTeam(teamID)
+	if e != nil {
+		return e
+	}
+
+	e = db.DeleteTeam(teamID)
 	if e != nil {
 		return e
 	}
@@ -91,7 +97,7 @@ func DeleteTeam(teamID int) *response.Error {
 		return e
 	}
 
-	return nil
+	return PromoteWaitlist(team.HuntID)
 }
 
 // UpdateTeam executes a partial update of the team with the given id. NOTE:
@@ -135,13 +141,31 @@ func UpdateTeam(env *config.Env, team *Team) *response.Error {
 }
 
 // AddPlayer adds the given player to the given team and assigns the
-// necessary roles
+// necessary roles. A player that is moved off another team in the hunt loses
+// their roles for it.
 func AddPlayer(teamID int, playerID int) *response.Error {
-	e := db.TeamAddPlayer(teamID, playerID)
+	team, e := db.GetTeam(teamID)
 	if e != nil {
 		return e
 	}
 
+	oldTeamID, e := db.TeamIDForPlayer(team.HuntID, playerID)
+	if e != nil {
+		return e
+	}
+
+	e = db.TeamAddPlayer(teamID, playerID)
+	if e != nil {
+		return e
+	}
+
+	if oldTeamID != 0 && oldTeamID != teamID {
+		e = roles.RemoveTeamRoles(oldTeamID, playerID)
+		if e != nil {
+			return e
+		}
+	}
+
 	teamEditor := roles.New("team_editor", teamID)
 	e = teamEditor.AddTo(playerID)
 	if e != nil {
@@ -149,3 +173,24 @@ func AddPlayer(teamID int, playerID int) *response.Error {
 	}
 	return nil
 }
+
+// RemovePlayer removes the given player from the given team along with
+// their roles for it. The freed player spot is given to the hunt's waitlist.
+func RemovePlayer(teamID int, playerID int) *response.Error {
+	team, e := db.GetTeam(teamID)
+	if e != nil {
+		return e
+	}
+
+	e = db.TeamRemovePlayer(teamID, playerID)
+	if e != nil {
+		return e
+	}
+
+	e = roles.RemoveTeamRoles(teamID, playerID)
+	if e != nil {
+		return e
+	}
+
+	return PromoteWaitlist(team.HuntID)
+}
@@ -4,14 +4,16 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"os"
 
 	"github.com/cljohnson4343/scavenge/config"
 	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/events"
 	"github.com/cljohnson4343/scavenge/request"
 	"github.com/cljohnson4343/scavenge/response"
-	"github.com/cljohnson4343/scavenge/s3"
+	"github.com/cljohnson4343/scavenge/tracks"
 	"github.com/cljohnson4343/scavenge/users"
 	"github.com/go-chi/render"
 	"github.com/google/uuid"
@@ -103,12 +105,22 @@ func deleteTeamHandler(env *config.Env) http.HandlerFunc {
 			return
 		}
 
+		team, e := db.GetTeam(teamID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
 		e = DeleteTeam(teamID)
 		if e != nil {
 			e.Handle(w)
+			return
 		}
 
-		return
+		events.Publish(team.HuntID, teamID, events.Team, &events.TeamData{
+			TeamID: teamID,
+			Action: events.TeamDeleted,
+		})
 	})
 }
 
@@ -149,6 +161,11 @@ func createTeamHandler(env *config.Env) http.HandlerFunc {
 			return
 		}
 
+		events.Publish(team.HuntID, team.ID, events.Team, &events.TeamData{
+			TeamID: team.ID,
+			Action: events.TeamCreated,
+		})
+
 		render.JSON(w, r, &team)
 		return
 	})
@@ -194,9 +211,13 @@ func patchTeamHandler(env *config.Env) http.HandlerFunc {
 		e = UpdateTeam(env, &team)
 		if e != nil {
 			e.Handle(w)
+			return
 		}
 
-		return
+		events.PublishForTeam(teamID, events.Team, &events.TeamData{
+			TeamID: teamID,
+			Action: events.TeamUpdated,
+		})
 	})
 }
 
@@ -274,11 +295,132 @@ func createLocationHandler(env *config.Env) http.HandlerFunc {
 			return
 		}
 
+		events.PublishForTeam(teamID, events.Location, &location)
+		CheckBoundary(teamID, []*db.LocationDB{&location})
+
 		render.JSON(w, r, &location)
 		return
 	})
 }
 
+// swagger:route POST /teams/{teamID}/locations/batch location create createLocationBatchHandler
+//
+// Creates the given array of locations, which clients queue while they are
+// offline. A result is returned for each location, in the same order, that
+// says whether it was created, was a duplicate of a location the team
+// already has at that time, or was invalid.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func createLocationBatchHandler(env *config.Env) http.HandlerFunc {
+	return (func(w http.ResponseWriter, r *http.Request) {
+		teamID, e := request.GetIntURLParam(r, "teamID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxLocationBatchSize)
+		locations := make([]*db.LocationDB, 0)
+		e = request.Decode(r, &locations)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		results, e := CreateLocations(r, teamID, locations)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, results)
+		return
+	})
+}
+
+// swagger:route GET /teams/{teamID}/locations/export location exportTrackHandler
+//
+// Exports the team's track, and where its media was submitted, as GeoJSON,
+// GPX, or KML. The format query parameter picks the format, GeoJSON by
+// default. The from and to query parameters, RFC 3339 times, limit the
+// export to that range of time.
+//
+// Produces:
+//	- application/geo+json
+//	- application/gpx+xml
+//	- application/vnd.google-earth.kml+xml
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func exportTrackHandler(env *config.Env) http.HandlerFunc {
+	return (func(w http.ResponseWriter, r *http.Request) {
+		teamID, e := request.GetIntURLParam(r, "teamID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		format, e := tracks.Format(r)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		from, to, e := tracks.TimeRange(r)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		team, e := db.GetTeam(teamID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		locations, e := db.GetLocationsForTeamBetween(teamID, from, to)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		media, e := db.GetMediaForHunt(team.HuntID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		export := TrackExport(
+			team.Name,
+			locations,
+			media,
+			map[int]string{teamID: team.Name},
+			from,
+			to,
+		)
+		e = tracks.Write(w, format, fmt.Sprintf("team-%d-track", teamID), export)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+	})
+}
+
 // swagger:route DELETE /teams/{teamID}/locations/{locationID} delete location deleteLocationHandler
 //
 // Deletes the given location.
@@ -317,9 +459,49 @@ func deleteLocationHandler(env *config.Env) http.HandlerFunc {
 	})
 }
 
+// swagger:route GET /teams/{teamID}/locations/live location live liveLocationsHandler
+//
+// Opens a WebSocket connection for sharing the team's location live. Team
+// members send gps fixes as LiveFix messages and receive the team's
+// locations as LiveMessages when they are stored. The team's fixes are
+// stored at most once every live_location_interval.
+//
+// Schemes: ws, wss
+//
+// Responses:
+//  101:
+//  400:
+//  403:
+//  500:
+func liveLocationsHandler(env *config.Env) http.HandlerFunc {
+	return (func(w http.ResponseWriter, r *http.Request) {
+		teamID, e := request.GetIntURLParam(r, "teamID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		team, e := db.GetTeam(teamID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		conn, e := UpgradeLive(w, r)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		ServeLiveLocations(conn, team.HuntID, teamID)
+	})
+}
+
 // swagger:route GET /teams/{teamID}/media/ media getMediaForTeamHandler
 //
-// Lists all the info for the media files associated with a team.
+// Lists all the info for the media files associated with a team, including
+// the urls of their thumbnails. Only hunt owners see when and where photos
+// were taken.
 //
 // Consumes:
 // 	- application/json
@@ -342,7 +524,13 @@ func getMediaForTeamHandler(env *config.Env) http.HandlerFunc {
 			return
 		}
 
-		mediaMetaDBs, e := db.GetMediaMetasForTeam(teamID)
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		mediaMetaDBs, e := GetMediaForTeam(teamID, userID)
 		if e != nil {
 			e.Handle(w)
 		}
@@ -354,7 +542,11 @@ func getMediaForTeamHandler(env *config.Env) http.HandlerFunc {
 
 // swagger:route POST /teams/{teamID}/media/ media create createMediaHandler
 //
-// Stores the given media info.
+// Stores the given media info. Photos are stored upright without their
+// metadata and thumbnails are made for them. Photos that look like another
+// photo uploaded in the hunt are flagged for the hunt's owners. The file's
+// contents have to be an image or video that matches its extension and it
+// can be at most the hunt's max upload size.
 //
 // Consumes:
 // 	- application/json
@@ -378,8 +570,12 @@ func createMediaHandler(env *config.Env) http.HandlerFunc {
 			return
 		}
 
-		// Parse our multipart form, 10 << 20 specifies a maximum
-		// upload of 10 MB files.
+		// no hunt allows files larger than the max upload size limit, the
+		// extra megabyte leaves room for the json part
+		r.Body = http.MaxBytesReader(w, r.Body, db.MaxUploadSizeLimit+1<<20)
+
+		// Parse our multipart form, files larger than 10 MB are kept on
+		// disk instead of in memory.
 		err := r.ParseMultipartForm(10 << 20)
 		if err != nil {
 			e := response.NewErrorf(http.StatusBadRequest, "Invalid file: %v", err)
@@ -399,19 +595,8 @@ func createMediaHandler(env *config.Env) http.HandlerFunc {
 		}
 		defer file.Close()
 
-		key := uuid.New()
-		url, e := s3.Upload(
-			key.String()+s3.GetExt(handler.Filename),
-			file,
-		)
-		if e != nil {
-			e.Handle(w)
-			return
-		}
-
 		media := db.MediaMetaDB{}
 		media.TeamID = teamID
-		media.URL = url
 
 		jsonBody, _, err := r.FormFile("json")
 		if err != nil {
@@ -436,12 +621,82 @@ func createMediaHandler(env *config.Env) http.HandlerFunc {
 			return
 		}
 
+		data, err := ioutil.ReadAll(file)
+		if err != nil {
+			e := response.NewErrorf(
+				http.StatusBadRequest,
+				"error reading 'file' from request: %v",
+				err,
+			)
+			e.Handle(w)
+			return
+		}
+
+		limit, e := uploadLimit(teamID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		contentType, e := checkContent(handler.Filename, data, limit)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		// the stored file's extension comes from its contents, not its name
+		key := uuid.New()
+		thumbnails, e := storeMedia(
+			env.Storage,
+			key.String()+uploadExtensions[contentType][0],
+			data,
+			&media,
+		)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		flags, e := verifyMedia(&media)
+		if e == nil {
+			var duplicates []*db.SubmissionFlagDB
+			duplicates, e = findDuplicates(&media)
+			flags = append(flags, duplicates...)
+		}
+		if e != nil {
+			deleteErr := deleteMediaFiles(env.Storage, media.URL, thumbnails)
+			if deleteErr != nil {
+				e.AddError(deleteErr)
+			}
+			e.Handle(w)
+			return
+		}
+
 		e = media.Insert(teamID)
 		if e != nil {
 			e.Handle(w)
 			return
 		}
 
+		e = saveThumbnails(&media, thumbnails)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		e = RecordFlags(flags, media.ID, 0)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		events.PublishSubmission(&events.SubmissionData{
+			TeamID:  teamID,
+			ItemID:  media.ItemID,
+			MediaID: media.ID,
+			URL:     media.URL,
+		})
+
 		render.JSON(w, r, &media)
 		return
 	})
@@ -449,7 +704,8 @@ func createMediaHandler(env *config.Env) http.HandlerFunc {
 
 // swagger:route DELETE /teams/{teamID}/media/{mediaID} delete media deleteMediaHandler
 //
-// Deletes the given media.
+// Deletes the given media. Its file and thumbnails are deleted from storage
+// shortly after.
 //
 // Consumes:
 // 	- application/json
@@ -476,19 +732,13 @@ func deleteMediaHandler(env *config.Env) http.HandlerFunc {
 			return
 		}
 
-		url, e := db.DeleteMedia(mediaID, teamID)
-		if e != nil {
-			e.Handle(w)
-			return
-		}
-
-		e = s3.Delete(s3.GetKey(url))
+		_, e = db.DeleteMedia(mediaID, teamID)
 		if e != nil {
 			e.Handle(w)
 			return
 		}
 
-		return
+		events.PublishScore(teamID)
 	})
 }
 
@@ -547,9 +797,45 @@ func populateMediaDBHandler(env *config.Env) http.HandlerFunc {
 	}
 }
 
+// swagger:route GET /teams/{teamID}/stats stats getTeamStatsHandler
+//
+// Gets how the team moved during its hunt: the distance it covered, its
+// moving time, its average and fastest speeds, and how long it took to
+// complete each item.
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+// 	400:
+//  500:
+func getTeamStatsHandler(env *config.Env) http.HandlerFunc {
+	return (func(w http.ResponseWriter, r *http.Request) {
+		teamID, e := request.GetIntURLParam(r, "teamID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		stats, e := GetTeamStats(teamID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, stats)
+		return
+	})
+}
+
 // swagger:route GET /teams/{teamID}/points/ points getTeamPointsHandler
 //
-// Gets the point total for team.
+// Gets the point total for team along with the points earned in each item
+// category, the adjustments made to its points, like best photo bonuses, and
+// the points spent on hints.
 //
 // Consumes:
 // 	- application/json
@@ -577,10 +863,33 @@ func getTeamPointsHandler(env *config.Env) http.HandlerFunc {
 			return
 		}
 
+		categories, e := db.GetTeamPointsByCategory(teamID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		adjustments, e := db.GetScoreAdjustmentsForTeam(teamID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		earned := 0
+		for _, c := range categories {
+			earned += c.Points
+		}
+		for _, a := range adjustments {
+			earned += a.Points
+		}
+
 		type pts struct {
-			Points int `json:"points"`
+			Points      int                     `json:"points"`
+			Categories  []*db.CategoryPointsDB  `json:"categories"`
+			Adjustments []*db.ScoreAdjustmentDB `json:"adjustments"`
+			HintPenalty int                     `json:"hintPenalty"`
 		}
-		pt := pts{pointTotal}
+		pt := pts{pointTotal, categories, adjustments, earned - pointTotal}
 
 		render.JSON(w, r, pt)
 		return
@@ -666,6 +975,12 @@ func getAddPlayerHandler(env *config.Env) http.HandlerFunc {
 			e.Handle(w)
 			return
 		}
+
+		events.PublishForTeam(teamID, events.Team, &events.TeamData{
+			TeamID: teamID,
+			Action: events.PlayerJoined,
+			UserID: reqBody.PlayerID,
+		})
 		// TODO add roles
 	})
 }
@@ -700,11 +1015,554 @@ func getRemovePlayerHandler(env *config.Env) http.HandlerFunc {
 			return
 		}
 
-		e = db.TeamRemovePlayer(teamID, playerID)
+		e = RemovePlayer(teamID, playerID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		events.PublishForTeam(teamID, events.Team, &events.TeamData{
+			TeamID: teamID,
+			Action: events.PlayerRemoved,
+			UserID: playerID,
+		})
+	})
+}
+
+// swagger:route POST /teams/join/ team join joinTeamHandler
+//
+// Joins the team with the given team code. The team's hunt must let
+// players manage their own teams and the player can not already be
+// on a team in the hunt.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func joinTeamHandler(env *config.Env) http.HandlerFunc {
+	return (func(w http.ResponseWriter, r *http.Request) {
+		req := JoinTeamRequest{}
+		e := request.DecodeAndValidate(r, &req)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
 		if e != nil {
 			e.Handle(w)
 			return
 		}
 
+		team, e := JoinTeamByCode(req.Code, userID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		events.Publish(team.HuntID, team.ID, events.Team, &events.TeamData{
+			TeamID: team.ID,
+			Action: events.PlayerJoined,
+			UserID: userID,
+		})
+
+		render.JSON(w, r, team)
+	})
+}
+
+// swagger:route POST /teams/{teamID}/leave team leave leaveTeamHandler
+//
+// Removes the current user from the team. The team is deleted when
+// its last player leaves.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func leaveTeamHandler(env *config.Env) http.HandlerFunc {
+	return (func(w http.ResponseWriter, r *http.Request) {
+		teamID, e := request.GetIntURLParam(r, "teamID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		// the team is deleted when its last player leaves
+		team, e := db.GetTeam(teamID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		e = LeaveTeam(teamID, userID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		events.Publish(team.HuntID, teamID, events.Team, &events.TeamData{
+			TeamID: teamID,
+			Action: events.PlayerRemoved,
+			UserID: userID,
+		})
+	})
+}
+
+// swagger:route GET /teams/{teamID}/code team code getTeamCodeHandler
+//
+// Gets the code other players can use to join the team.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func getTeamCodeHandler(env *config.Env) http.HandlerFunc {
+	return (func(w http.ResponseWriter, r *http.Request) {
+		teamID, e := request.GetIntURLParam(r, "teamID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		code, e := GetTeamCode(teamID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, map[string]interface{}{
+			"teamID": teamID,
+			"code":   code,
+		})
+	})
+}
+
+// swagger:route POST /teams/{teamID}/items/{itemID}/answer item answer submitAnswerHandler
+//
+// Claims a text item by answering it. Answers are matched ignoring case
+// and extra whitespace.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func submitAnswerHandler(env *config.Env) http.HandlerFunc {
+	return (func(w http.ResponseWriter, r *http.Request) {
+		teamID, e := request.GetIntURLParam(r, "teamID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		itemID, e := request.GetIntURLParam(r, "itemID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		req := AnswerRequest{}
+		e = request.DecodeAndValidate(r, &req)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		claim, e := SubmitAnswer(teamID, itemID, userID, &req)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		events.PublishSubmission(&events.SubmissionData{
+			TeamID:  teamID,
+			ItemID:  itemID,
+			ClaimID: claim.ID,
+		})
+
+		render.JSON(w, r, claim)
+	})
+}
+
+// swagger:route POST /teams/{teamID}/items/{itemID}/checkin item checkin checkInHandler
+//
+// Claims a checkin item with the item's code.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func checkInHandler(env *config.Env) http.HandlerFunc {
+	return (func(w http.ResponseWriter, r *http.Request) {
+		teamID, e := request.GetIntURLParam(r, "teamID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		itemID, e := request.GetIntURLParam(r, "itemID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		req := CheckInRequest{}
+		e = request.DecodeAndValidate(r, &req)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		claim, e := CheckIn(teamID, itemID, userID, &req)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		events.PublishSubmission(&events.SubmissionData{
+			TeamID:  teamID,
+			ItemID:  itemID,
+			ClaimID: claim.ID,
+		})
+
+		render.JSON(w, r, claim)
+	})
+}
+
+// swagger:route POST /teams/{teamID}/items/{itemID}/checkpoint item checkpoint reachCheckpointHandler
+//
+// Claims a gps item by reporting a location within the item's radius of
+// its checkpoint.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func reachCheckpointHandler(env *config.Env) http.HandlerFunc {
+	return (func(w http.ResponseWriter, r *http.Request) {
+		teamID, e := request.GetIntURLParam(r, "teamID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		itemID, e := request.GetIntURLParam(r, "itemID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		req := CheckpointRequest{}
+		e = request.DecodeAndValidate(r, &req)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		claim, e := ReachCheckpoint(teamID, itemID, userID, &req)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		events.PublishSubmission(&events.SubmissionData{
+			TeamID:  teamID,
+			ItemID:  itemID,
+			ClaimID: claim.ID,
+		})
+
+		render.JSON(w, r, claim)
+	})
+}
+
+// swagger:route GET /teams/{teamID}/items/{itemID}/hints/ hint team getTeamHintsHandler
+//
+// Gets the hints for the item that have been revealed to the team and the
+// cost of unlocking the next one.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func getTeamHintsHandler(env *config.Env) http.HandlerFunc {
+	return (func(w http.ResponseWriter, r *http.Request) {
+		teamID, e := request.GetIntURLParam(r, "teamID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		itemID, e := request.GetIntURLParam(r, "itemID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		hints, e := GetTeamHints(teamID, itemID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, hints)
+	})
+}
+
+// swagger:route POST /teams/{teamID}/items/{itemID}/hints/unlock hint unlock unlockHintHandler
+//
+// Reveals the next hint for the item to the team. The hint's cost is
+// taken from the team's points.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func unlockHintHandler(env *config.Env) http.HandlerFunc {
+	return (func(w http.ResponseWriter, r *http.Request) {
+		teamID, e := request.GetIntURLParam(r, "teamID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		itemID, e := request.GetIntURLParam(r, "itemID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		hints, e := UnlockNextHint(teamID, itemID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		events.PublishScore(teamID)
+
+		render.JSON(w, r, hints)
+	})
+}
+
+// swagger:route POST /teams/{teamID}/uploads/ uploads createUploadSlotHandler
+//
+// Gets a url the team can upload a media file straight to storage with. The
+// file has to be sent with the requested content type and size before the
+// url expires. Confirm the upload to create the media.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  500:
+func createUploadSlotHandler(env *config.Env) http.HandlerFunc {
+	return (func(w http.ResponseWriter, r *http.Request) {
+		teamID, e := request.GetIntURLParam(r, "teamID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		userID, e := users.GetUserID(r.Context())
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		req := UploadSlotRequest{}
+		e = request.DecodeAndValidate(r, &req)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		slot, e := CreateUploadSlot(env.Storage, teamID, userID, &req)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, slot)
+	})
+}
+
+// swagger:route POST /teams/{teamID}/uploads/{uploadID}/confirm uploads confirmUploadHandler
+//
+// Creates the media for a file that was uploaded with the given upload.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+//  404:
+//  500:
+func confirmUploadHandler(env *config.Env) http.HandlerFunc {
+	return (func(w http.ResponseWriter, r *http.Request) {
+		teamID, e := request.GetIntURLParam(r, "teamID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		uploadID, e := request.GetIntURLParam(r, "uploadID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		req := ConfirmUploadRequest{}
+		e = request.DecodeAndValidate(r, &req)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		media, e := ConfirmUpload(env.Storage, teamID, uploadID, &req)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		events.PublishSubmission(&events.SubmissionData{
+			TeamID:  teamID,
+			ItemID:  media.ItemID,
+			MediaID: media.ID,
+			URL:     media.URL,
+		})
+
+		render.JSON(w, r, media)
+	})
+}
+
+// swagger:route GET /teams/{teamID}/alerts boundary getTeamAlertsHandler
+//
+// Gets the alerts raised when the team left its hunt's boundary, newest
+// first.
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+// 	400:
+//  500:
+func getTeamAlertsHandler(env *config.Env) http.HandlerFunc {
+	return (func(w http.ResponseWriter, r *http.Request) {
+		teamID, e := request.GetIntURLParam(r, "teamID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		alerts, e := db.GetBoundaryAlertsForTeam(teamID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, alerts)
+		return
 	})
 }
@@ -0,0 +1,135 @@
+package teams
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// TeamHints are the hints for an item that a team can see
+//
+// swagger:model TeamHints
+type TeamHints struct {
+
+	// The id of the item the hints are for
+	ItemID int `json:"itemID"`
+
+	// The hints that have been revealed to the team, in order
+	Hints []*db.HintDB `json:"hints"`
+
+	// The number of hints the team has not seen yet
+	Locked int `json:"locked"`
+
+	// The points it costs to unlock the next hint. Left out once every hint
+	// has been revealed.
+	NextCost *int `json:"nextCost,omitempty"`
+}
+
+// revealHints splits the hints into the ones a team can see and the ones it
+// can not. A hint can be seen once the team unlocks it or once its reveal
+// time, counted from the hunt's start, has passed.
+func revealHints(
+	hints []*db.HintDB,
+	unlocked map[int]bool,
+	start, now time.Time,
+) ([]*db.HintDB, []*db.HintDB) {
+	visible := make([]*db.HintDB, 0, len(hints))
+	locked := make([]*db.HintDB, 0, len(hints))
+
+	for _, h := range hints {
+		revealAt := h.RevealedAt(start)
+		if unlocked[h.ID] || (!revealAt.IsZero() && !now.Before(revealAt)) {
+			visible = append(visible, h)
+		} else {
+			locked = append(locked, h)
+		}
+	}
+
+	return visible, locked
+}
+
+// teamHints returns the revealed and locked hints for the item
+func teamHints(teamID, itemID int) ([]*db.HintDB, []*db.HintDB, *response.Error) {
+	team, _, e := itemForTeam(teamID, itemID)
+	if e != nil {
+		return nil, nil, e
+	}
+
+	hunt, e := db.GetHunt(team.HuntID)
+	if e != nil {
+		return nil, nil, e
+	}
+
+	hints, e := db.GetHintsForItem(itemID)
+	if e != nil {
+		return nil, nil, e
+	}
+
+	unlocked, e := db.GetUnlockedHintIDs(teamID, itemID)
+	if e != nil {
+		return nil, nil, e
+	}
+
+	visible, locked := revealHints(hints, unlocked, hunt.StartTime, time.Now())
+	return visible, locked, nil
+}
+
+// newTeamHints builds the TeamHints for the item
+func newTeamHints(itemID int, visible, locked []*db.HintDB) *TeamHints {
+	th := TeamHints{
+		ItemID: itemID,
+		Hints:  visible,
+		Locked: len(locked),
+	}
+
+	if len(locked) > 0 {
+		cost := locked[0].Cost
+		th.NextCost = &cost
+	}
+
+	return &th
+}
+
+// GetTeamHints returns the hints for the item that the team can see
+func GetTeamHints(teamID, itemID int) (*TeamHints, *response.Error) {
+	visible, locked, e := teamHints(teamID, itemID)
+	if e != nil {
+		return nil, e
+	}
+
+	return newTeamHints(itemID, visible, locked), nil
+}
+
+// UnlockNextHint reveals the next hint for the item to the team. The hint's
+// cost is taken from the team's points.
+func UnlockNextHint(teamID, itemID int) (*TeamHints, *response.Error) {
+	visible, locked, e := teamHints(teamID, itemID)
+	if e != nil {
+		return nil, e
+	}
+
+	if len(locked) == 0 {
+		return nil, response.NewErrorf(
+			http.StatusBadRequest,
+			"item_id: every hint for item %d has already been revealed",
+			itemID,
+		)
+	}
+
+	next := locked[0]
+	_, e = db.UnlockHint(next.ID, teamID)
+	if e != nil {
+		return nil, e
+	}
+
+	// a later hint could already have been revealed by time
+	visible = append(visible, next)
+	sort.Slice(visible, func(i, j int) bool {
+		return visible[i].Position < visible[j].Position
+	})
+
+	return newTeamHints(itemID, visible, locked[1:]), nil
+}
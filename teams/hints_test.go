@@ -0,0 +1,95 @@
+// +build unit
+
+package teams
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+)
+
+func TestRevealHints(t *testing.T) {
+	start := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	hints := []*db.HintDB{
+		{ID: 1, Position: 1, Cost: 5},
+		{ID: 2, Position: 2, Cost: 10, RevealAfter: 30},
+		{ID: 3, Position: 3, Cost: 20},
+	}
+
+	cases := []struct {
+		name     string
+		unlocked map[int]bool
+		now      time.Time
+		visible  []int
+		locked   []int
+	}{
+		{name: "nothing revealed", now: start, locked: []int{1, 2, 3}},
+		{name: "revealed by time", now: start.Add(30 * time.Minute), visible: []int{2}, locked: []int{1, 3}},
+		{name: "not yet revealed by time", now: start.Add(29 * time.Minute), locked: []int{1, 2, 3}},
+		{
+			name:     "unlocked",
+			unlocked: map[int]bool{1: true},
+			now:      start,
+			visible:  []int{1},
+			locked:   []int{2, 3},
+		},
+		{
+			name:     "unlocked and revealed",
+			unlocked: map[int]bool{1: true, 3: true},
+			now:      start.Add(time.Hour),
+			visible:  []int{1, 2, 3},
+		},
+	}
+
+	ids := func(hints []*db.HintDB) []int {
+		out := make([]int, 0, len(hints))
+		for _, h := range hints {
+			out = append(out, h.ID)
+		}
+		return out
+	}
+
+	equal := func(a, b []int) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			visible, locked := revealHints(hints, c.unlocked, start, c.now)
+
+			if !equal(ids(visible), c.visible) {
+				t.Errorf("expected visible hints %v got %v", c.visible, ids(visible))
+			}
+			if !equal(ids(locked), c.locked) {
+				t.Errorf("expected locked hints %v got %v", c.locked, ids(locked))
+			}
+		})
+	}
+}
+
+func TestNewTeamHints(t *testing.T) {
+	visible := []*db.HintDB{{ID: 1, Position: 1}}
+	locked := []*db.HintDB{{ID: 2, Position: 2, Cost: 15}}
+
+	th := newTeamHints(43, visible, locked)
+	if th.Locked != 1 {
+		t.Errorf("expected 1 locked hint got %d", th.Locked)
+	}
+	if th.NextCost == nil || *th.NextCost != 15 {
+		t.Errorf("expected the next hint to cost 15 got %v", th.NextCost)
+	}
+
+	th = newTeamHints(43, append(visible, locked...), nil)
+	if th.NextCost != nil {
+		t.Errorf("expected no next cost once every hint is revealed got %d", *th.NextCost)
+	}
+}
@@ -0,0 +1,293 @@
+package teams
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/events"
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/cljohnson4343/scavenge/websocket"
+	"github.com/spf13/viper"
+)
+
+const (
+	// defaultLiveInterval is the least time between two stored fixes of a
+	// team when the live_location_interval config value is not set
+	defaultLiveInterval = 3 * time.Second
+
+	// livePingInterval is how often live connections are pinged so proxies
+	// do not close them and dead ones are found
+	livePingInterval = 30 * time.Second
+
+	// liveWriteTimeout is how long a write to a live connection can take
+	// before the connection is dropped
+	liveWriteTimeout = 10 * time.Second
+
+	// liveReadLimit is the largest message, in bytes, a client can send
+	liveReadLimit = 4096
+)
+
+// the types of live location messages
+const (
+	LiveLocation = "location"
+	LiveError    = "error"
+)
+
+// liveInterval returns the least time between two stored fixes of a team
+func liveInterval() time.Duration {
+	interval := viper.GetDuration("live_location_interval")
+	if interval <= 0 {
+		return defaultLiveInterval
+	}
+
+	return interval
+}
+
+// UpgradeLive upgrades the request to a live location connection. Browsers
+// can connect from the api's host or one of the websocket_origins in the
+// config.
+func UpgradeLive(w http.ResponseWriter, r *http.Request) (*websocket.Conn, *response.Error) {
+	return websocket.Upgrade(w, r, viper.GetStringSlice("websocket_origins"))
+}
+
+// LiveFix is a gps fix a team member sends over a live location connection
+//
+// swagger:model LiveFix
+type LiveFix struct {
+	// the latitude
+	//
+	// required: true
+	Latitude float32 `json:"latitude"`
+
+	// the longitude
+	//
+	// required: true
+	Longitude float32 `json:"longitude"`
+
+	// when the fix was taken
+	//
+	// required: false
+	TimeStamp time.Time `json:"timestamp"`
+}
+
+// location returns the fix as a location of the team. Fixes without a time,
+// or with a time after they were received, get the time they were received.
+func (f *LiveFix) location(teamID int, received time.Time) *db.LocationDB {
+	l := db.LocationDB{
+		TeamID:    teamID,
+		Latitude:  f.Latitude,
+		Longitude: f.Longitude,
+		TimeStamp: f.TimeStamp,
+	}
+	if l.TimeStamp.IsZero() || l.TimeStamp.After(received) {
+		l.TimeStamp = received
+	}
+
+	return &l
+}
+
+// LiveMessage is a message the server sends over a live location connection
+//
+// swagger:model LiveMessage
+type LiveMessage struct {
+	// the type of the message, location or error
+	//
+	// required: true
+	Type string `json:"type"`
+
+	// the location of a team, for location messages
+	//
+	// required: false
+	Location *db.LocationDB `json:"location,omitempty"`
+
+	// what went wrong, for error messages, in the same form as the errors
+	// returned by the other endpoints
+	//
+	// required: false
+	Errors json.RawMessage `json:"errors,omitempty"`
+}
+
+// liveError returns an error message for the error
+func liveError(e *response.Error) *LiveMessage {
+	return &LiveMessage{Type: LiveError, Errors: e.JSON()}
+}
+
+// fixThrottle limits how often the fixes of each team are stored, no matter
+// how many of the team's members are connected
+type fixThrottle struct {
+	mu     sync.Mutex
+	stored map[int]time.Time
+}
+
+// allow returns whether or not a fix of the team can be stored at the given
+// time and, if it can, records that one was
+func (t *fixThrottle) allow(teamID int, now time.Time, interval time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if last, ok := t.stored[teamID]; ok && now.Sub(last) < interval {
+		return false
+	}
+
+	t.stored[teamID] = now
+	return true
+}
+
+// throttle limits the fixes stored by the live connections on this server
+var throttle = fixThrottle{stored: make(map[int]time.Time)}
+
+// readFixes sends the fixes read from the connection until reading fails or
+// done is closed. Messages that are not fixes are answered with an error.
+func readFixes(conn *websocket.Conn, fixes chan<- *LiveFix, done <-chan struct{}) {
+	defer close(fixes)
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		fix := LiveFix{}
+		if err = json.Unmarshal(data, &fix); err != nil {
+			conn.WriteJSON(liveError(response.NewErrorf(
+				http.StatusBadRequest,
+				"fix: invalid fix: %v",
+				err,
+			)))
+			continue
+		}
+
+		select {
+		case fixes <- &fix:
+		case <-done:
+			return
+		}
+	}
+}
+
+// ServeLiveLocations serves a live location connection in the hunt until it
+// is closed. Members of the team with the given id send their fixes and
+// receive the team's locations as they are stored. A teamID of 0 is for the
+// hunt's owners, who receive the locations of every team but can not send
+// fixes. The last known locations are sent when the connection opens.
+func ServeLiveLocations(conn *websocket.Conn, huntID, teamID int) {
+	defer conn.Close()
+	conn.SetReadLimit(liveReadLimit)
+
+	sub := events.Subscribe(huntID)
+	defer sub.Close()
+
+	send := func(msg *LiveMessage) bool {
+		conn.SetWriteDeadline(time.Now().Add(liveWriteTimeout))
+		return conn.WriteJSON(msg) == nil
+	}
+
+	latest, e := db.GetLatestLocationsForHunt(huntID)
+	if e != nil {
+		log.Printf("error getting latest locations for hunt %d: %s\n", huntID, e.JSON())
+	}
+	for _, l := range latest {
+		if teamID != 0 && l.TeamID != teamID {
+			continue
+		}
+		if !send(&LiveMessage{Type: LiveLocation, Location: l}) {
+			return
+		}
+	}
+
+	fixes := make(chan *LiveFix)
+	done := make(chan struct{})
+	defer close(done)
+	go readFixes(conn, fixes, done)
+
+	interval := liveInterval()
+	flush := time.NewTicker(interval)
+	defer flush.Stop()
+	ping := time.NewTicker(livePingInterval)
+	defer ping.Stop()
+
+	// the newest fix that has not been stored yet because the team's last
+	// fix was stored too recently
+	var pending *db.LocationDB
+
+	store := func() bool {
+		if !throttle.allow(teamID, time.Now(), interval) {
+			return true
+		}
+
+		l := pending
+		pending = nil
+		if e := l.Insert(teamID); e != nil {
+			return send(liveError(e))
+		}
+
+		events.Publish(huntID, teamID, events.Location, l)
+		CheckBoundary(teamID, []*db.LocationDB{l})
+		return true
+	}
+
+	for {
+		select {
+		case fix, ok := <-fixes:
+			if !ok {
+				return
+			}
+
+			if teamID == 0 {
+				if !send(liveError(response.NewError(
+					http.StatusForbidden,
+					"fix: only team members can send fixes",
+				))) {
+					return
+				}
+				continue
+			}
+
+			// postgres stores times to the microsecond
+			l := fix.location(teamID, time.Now().Truncate(time.Microsecond))
+			if e := l.Validate(nil); e != nil {
+				if !send(liveError(e)) {
+					return
+				}
+				continue
+			}
+
+			pending = l
+			if !store() {
+				return
+			}
+		case event, ok := <-sub.C:
+			if !ok {
+				// the connection fell behind; the client reconnects and gets
+				// the latest locations
+				conn.WriteClose(websocket.CloseGoingAway, "too far behind")
+				return
+			}
+
+			if event.Type != events.Location || !events.Visible(event, teamID, teamID == 0) {
+				continue
+			}
+
+			l := db.LocationDB{}
+			if err := json.Unmarshal(event.Data, &l); err != nil {
+				continue
+			}
+			if !send(&LiveMessage{Type: LiveLocation, Location: &l}) {
+				return
+			}
+		case <-flush.C:
+			if pending != nil && !store() {
+				return
+			}
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(liveWriteTimeout))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
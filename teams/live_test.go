@@ -0,0 +1,59 @@
+// +build unit
+
+package teams
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFixThrottle(t *testing.T) {
+	now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	throttle := fixThrottle{stored: make(map[int]time.Time)}
+	interval := 3 * time.Second
+
+	if !throttle.allow(1, now, interval) {
+		t.Errorf("expected a team's first fix to be allowed")
+	}
+	if throttle.allow(1, now.Add(time.Second), interval) {
+		t.Errorf("expected a fix within the interval to be throttled")
+	}
+	if !throttle.allow(2, now.Add(time.Second), interval) {
+		t.Errorf("expected other teams to not be throttled")
+	}
+	if !throttle.allow(1, now.Add(interval), interval) {
+		t.Errorf("expected a fix after the interval to be allowed")
+	}
+	if throttle.allow(1, now.Add(interval+time.Second), interval) {
+		t.Errorf("expected the interval to start at the last stored fix")
+	}
+}
+
+func TestLiveFixLocation(t *testing.T) {
+	received := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	earlier := received.Add(-time.Minute)
+
+	cases := []struct {
+		name     string
+		taken    time.Time
+		expected time.Time
+	}{
+		{"no time", time.Time{}, received},
+		{"taken earlier", earlier, earlier},
+		{"clock ahead", received.Add(time.Minute), received},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fix := LiveFix{Latitude: 40.7829, Longitude: -73.9654, TimeStamp: c.taken}
+			l := fix.location(7, received)
+
+			if l.TeamID != 7 || l.Latitude != fix.Latitude || l.Longitude != fix.Longitude {
+				t.Errorf("expected the fix's team and position got %+v", l)
+			}
+			if !l.TimeStamp.Equal(c.expected) {
+				t.Errorf("expected time %v got %v", c.expected, l.TimeStamp)
+			}
+		})
+	}
+}
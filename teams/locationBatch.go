@@ -0,0 +1,140 @@
+package teams
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/events"
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+const (
+	// maxLocationBatch is the most locations that can be sent in one batch
+	maxLocationBatch = 1000
+
+	// maxLocationBatchSize is the largest, in bytes, a batch of locations
+	// can be
+	maxLocationBatchSize = 256 << 10
+)
+
+// the statuses of the locations in a batch
+const (
+	// the location was stored
+	BatchCreated = "created"
+
+	// the team already has a location at the location's time
+	BatchDuplicate = "duplicate"
+
+	// the location is invalid and was not stored
+	BatchInvalid = "invalid"
+)
+
+// LocationBatchResult is what happened to a location of a batch
+//
+// swagger:model LocationBatchResult
+type LocationBatchResult struct {
+	// the index of the location in the batch
+	//
+	// required: true
+	Index int `json:"index"`
+
+	// created, duplicate, or invalid
+	//
+	// required: true
+	Status string `json:"status"`
+
+	// the id of the location, if it was created
+	//
+	// required: false
+	LocationID int `json:"locationID,omitempty"`
+
+	// why the location is invalid, in the same form as the errors returned
+	// by the other endpoints
+	//
+	// required: false
+	Errors json.RawMessage `json:"errors,omitempty"`
+}
+
+// checkLocationBatch returns a result for each location of the batch, with
+// the invalid ones marked, along with the valid locations and their indexes
+// in the batch. Locations without a team id are given the team's.
+func checkLocationBatch(r *http.Request, teamID int, locs []*db.LocationDB) ([]*LocationBatchResult, []*db.LocationDB, []int) {
+	results := make([]*LocationBatchResult, len(locs))
+	valid := make([]*db.LocationDB, 0, len(locs))
+	indexes := make([]int, 0, len(locs))
+	for i, l := range locs {
+		results[i] = &LocationBatchResult{Index: i}
+		if l == nil {
+			l = &db.LocationDB{}
+			locs[i] = l
+		}
+
+		if l.TeamID == 0 {
+			l.TeamID = teamID
+		}
+
+		e := l.Validate(r)
+		if e == nil && l.TeamID != teamID {
+			e = response.NewErrorf(
+				http.StatusBadRequest,
+				"team_id: team_id must match the URL team id %d",
+				teamID,
+			)
+		}
+		if e != nil {
+			results[i].Status = BatchInvalid
+			results[i].Errors = e.JSON()
+			continue
+		}
+
+		valid = append(valid, l)
+		indexes = append(indexes, i)
+	}
+
+	return results, valid, indexes
+}
+
+// CreateLocations stores the team's batch of locations, which clients queue
+// while they are offline, and returns what happened to each of them. The
+// locations that are valid are stored in a single insert. The newest stored
+// location is published to the hunt's events.
+func CreateLocations(r *http.Request, teamID int, locs []*db.LocationDB) ([]*LocationBatchResult, *response.Error) {
+	if len(locs) > maxLocationBatch {
+		return nil, response.NewErrorf(
+			http.StatusBadRequest,
+			"locations: a batch can have at most %d locations",
+			maxLocationBatch,
+		)
+	}
+
+	results, valid, indexes := checkLocationBatch(r, teamID, locs)
+	inserted, e := db.InsertLocations(teamID, valid)
+	if e != nil {
+		return nil, e
+	}
+
+	var newest *db.LocationDB
+	stored := make([]*db.LocationDB, 0, len(valid))
+	for j, l := range valid {
+		result := results[indexes[j]]
+		if !inserted[j] {
+			result.Status = BatchDuplicate
+			continue
+		}
+
+		result.Status = BatchCreated
+		result.LocationID = l.ID
+		stored = append(stored, l)
+		if newest == nil || l.TimeStamp.After(newest.TimeStamp) {
+			newest = l
+		}
+	}
+
+	if newest != nil {
+		events.PublishForTeam(teamID, events.Location, newest)
+	}
+	CheckBoundary(teamID, stored)
+
+	return results, nil
+}
@@ -0,0 +1,54 @@
+// +build unit
+
+package teams
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+)
+
+func TestCheckLocationBatch(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	locs := []*db.LocationDB{
+		{Latitude: 40.7829, Longitude: -73.9654, TimeStamp: past},
+		{TeamID: 7, Latitude: 40.7830, Longitude: -73.9655, TimeStamp: past.Add(time.Second)},
+		{TeamID: 8, Latitude: 40.7830, Longitude: -73.9655, TimeStamp: past},
+		{Latitude: 91, Longitude: -73.9655, TimeStamp: past},
+		{Latitude: 40.7830, Longitude: -73.9655, TimeStamp: time.Now().Add(time.Hour)},
+		nil,
+	}
+
+	r := httptest.NewRequest("POST", "/teams/7/locations/batch", nil)
+	results, valid, indexes := checkLocationBatch(r, 7, locs)
+
+	if len(results) != len(locs) {
+		t.Fatalf("expected %d results got %d", len(locs), len(results))
+	}
+	for i, result := range results {
+		if result.Index != i {
+			t.Errorf("expected result %d to have index %d got %d", i, i, result.Index)
+		}
+	}
+
+	if len(valid) != 2 || len(indexes) != 2 || indexes[0] != 0 || indexes[1] != 1 {
+		t.Fatalf("expected the first two locations to be valid got %v", indexes)
+	}
+	if valid[0].TeamID != 7 {
+		t.Errorf("expected a location without a team to get the team's id got %d", valid[0].TeamID)
+	}
+
+	for i := 2; i < len(locs); i++ {
+		if results[i].Status != BatchInvalid {
+			t.Errorf("expected location %d to be invalid got %q", i, results[i].Status)
+		}
+		if len(results[i].Errors) == 0 {
+			t.Errorf("expected location %d to have errors", i)
+		}
+	}
+	if results[0].Status != "" || results[1].Status != "" {
+		t.Errorf("expected valid locations to not have a status until they are stored")
+	}
+}
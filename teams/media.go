@@ -0,0 +1,165 @@
+package teams
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/imaging"
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/cljohnson4343/scavenge/roles"
+	"github.com/cljohnson4343/scavenge/storage"
+)
+
+// thumbnailKey returns the storage key of a thumbnail of the file stored
+// under the given key
+func thumbnailKey(key string, size int) string {
+	return strings.TrimSuffix(key, storage.GetExt(key)) + "_" + strconv.Itoa(size) + ".jpg"
+}
+
+// storeMedia stores an uploaded media file under the given key and sets the
+// media's url. Photos have their EXIF capture time, location, and hash
+// recorded on the media and are stored upright without their metadata, along
+// with their thumbnails. Jpegs that can not be processed are stored with
+// their metadata stripped, or rejected if that fails too. Photos in formats
+// that can not be processed at all are rejected. Other files, like videos,
+// are stored as is. The returned thumbnails are saved once the media has
+// been inserted.
+func storeMedia(store storage.Store, key string, data []byte, media *db.MediaMetaDB) ([]*db.ThumbnailDB, *response.Error) {
+	// the capture details only ever come from the file itself
+	media.HideCaptureDetails()
+	media.PHash = 0
+
+	public := data
+	res, err := imaging.Process(data)
+	contentType := sniffContentType(data)
+	if err == imaging.ErrNotImage && strings.HasPrefix(contentType, "image/") {
+		return nil, response.NewErrorf(
+			http.StatusBadRequest,
+			"media: %s photos can not be stripped of their metadata",
+			contentType,
+		)
+	}
+	if err != nil && err != imaging.ErrNotImage {
+		log.Printf("error processing media %s: %v\n", key, err)
+
+		// the original may still have its location
+		stripped, err := imaging.StripMetadata(data)
+		if err != nil && err != imaging.ErrNotImage {
+			return nil, response.NewErrorf(
+				http.StatusBadRequest,
+				"media: the photo can not be read: %v",
+				err,
+			)
+		}
+		if stripped != nil {
+			public = stripped
+		}
+	}
+
+	if res != nil && res.EXIF != nil {
+		media.TakenAt = res.EXIF.TakenAt
+		if res.EXIF.HasLocation {
+			media.TakenLatitude = float32(res.EXIF.Latitude)
+			media.TakenLongitude = float32(res.EXIF.Longitude)
+		}
+	}
+	if res != nil {
+		media.PHash = int64(res.Hash)
+	}
+	if res != nil && res.Public != nil {
+		public = res.Public
+	}
+
+	url, e := store.Put(key, bytes.NewReader(public))
+	if e != nil {
+		return nil, e
+	}
+	media.URL = url
+
+	if res == nil {
+		return nil, nil
+	}
+
+	thumbnails := make([]*db.ThumbnailDB, 0, len(res.Thumbnails))
+	for _, t := range res.Thumbnails {
+		thumbURL, e := store.Put(thumbnailKey(key, t.Size), bytes.NewReader(t.Data))
+		if e != nil {
+			deleteMediaFiles(store, media.URL, thumbnails)
+			return nil, e
+		}
+
+		thumbnails = append(thumbnails, &db.ThumbnailDB{
+			Size:   t.Size,
+			Width:  t.Width,
+			Height: t.Height,
+			URL:    thumbURL,
+		})
+	}
+
+	return thumbnails, nil
+}
+
+// saveThumbnails inserts the thumbnails of the given media
+func saveThumbnails(media *db.MediaMetaDB, thumbnails []*db.ThumbnailDB) *response.Error {
+	for _, t := range thumbnails {
+		t.MediaID = media.ID
+
+		e := t.Insert()
+		if e != nil {
+			return e
+		}
+	}
+
+	media.Thumbnails = thumbnails
+	return nil
+}
+
+// deleteMediaFiles removes a media file and its thumbnails from storage
+func deleteMediaFiles(store storage.Store, url string, thumbnails []*db.ThumbnailDB) *response.Error {
+	e := response.NewNilError()
+
+	deleteErr := store.Delete(storage.GetKey(url))
+	if deleteErr != nil {
+		e.AddError(deleteErr)
+	}
+
+	for _, t := range thumbnails {
+		deleteErr = store.Delete(storage.GetKey(t.URL))
+		if deleteErr != nil {
+			e.AddError(deleteErr)
+		}
+	}
+
+	return e.GetError()
+}
+
+// GetMediaForTeam returns the team's media. The EXIF capture details are
+// only shown to the owners of the team's hunt.
+func GetMediaForTeam(teamID, userID int) ([]*db.MediaMetaDB, *response.Error) {
+	team, e := db.GetTeam(teamID)
+	if e != nil {
+		return nil, e
+	}
+
+	isOwner, e := roles.UserHasRole("hunt_owner", team.HuntID, userID)
+	if e != nil {
+		return nil, e
+	}
+
+	medias, e := db.GetMediaMetasForTeam(teamID)
+	if medias == nil {
+		return nil, e
+	}
+
+	if !isOwner {
+		for _, m := range medias {
+			m.HideCaptureDetails()
+		}
+	}
+
+	return medias, e
+}
@@ -47,11 +47,28 @@ func Routes(env *config.Env) *chi.Mux {
 	router.Delete("/{teamID}", deleteTeamHandler(env))                         // tested
 	router.Post("/", createTeamHandler(env))                                   // tested
 	router.Patch("/{teamID}", patchTeamHandler(env))
+	router.Get("/{teamID}/stats", getTeamStatsHandler(env))
+	router.Get("/{teamID}/alerts", getTeamAlertsHandler(env))
+
+	// self service routes
+	router.Post("/join/", joinTeamHandler(env))
+	router.Post("/{teamID}/leave", leaveTeamHandler(env))
+	router.Get("/{teamID}/code", getTeamCodeHandler(env))
+
+	// item submission routes
+	router.Post("/{teamID}/items/{itemID}/answer", submitAnswerHandler(env))
+	router.Post("/{teamID}/items/{itemID}/checkin", checkInHandler(env))
+	router.Post("/{teamID}/items/{itemID}/checkpoint", reachCheckpointHandler(env))
+	router.Get("/{teamID}/items/{itemID}/hints/", getTeamHintsHandler(env))
+	router.Post("/{teamID}/items/{itemID}/hints/unlock", unlockHintHandler(env))
 
 	// location routes
 	router.Get("/{teamID}/locations/", getLocationsForTeamHandler(env))           // tested
 	router.Post("/{teamID}/locations/", createLocationHandler(env))               // tested
 	router.Delete("/{teamID}/locations/{locationID}", deleteLocationHandler(env)) // tested
+	router.Post("/{teamID}/locations/batch", createLocationBatchHandler(env))
+	router.Get("/{teamID}/locations/export", exportTrackHandler(env))
+	router.Get("/{teamID}/locations/live", liveLocationsHandler(env))
 
 	// media routes
 	router.Get("/{teamID}/media/", getMediaForTeamHandler(env))         // tested
@@ -59,5 +76,9 @@ func Routes(env *config.Env) *chi.Mux {
 	router.Delete("/{teamID}/media/{mediaID}", deleteMediaHandler(env)) // tested
 	router.Post("/populate/", populateMediaDBHandler(env))
 
+	// direct upload routes
+	router.Post("/{teamID}/uploads/", createUploadSlotHandler(env))
+	router.Post("/{teamID}/uploads/{uploadID}/confirm", confirmUploadHandler(env))
+
 	return router
 }
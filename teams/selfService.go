@@ -0,0 +1,194 @@
+package teams
+
+import (
+	"net/http"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/joincode"
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/cljohnson4343/scavenge/roles"
+)
+
+// JoinTeamRequest is the body of a request to join a team by its code
+type JoinTeamRequest struct {
+	Code string `json:"code" valid:"-"`
+}
+
+// Validate validates the join team request
+func (req *JoinTeamRequest) Validate(r *http.Request) *response.Error {
+	if joincode.Normalize(req.Code) == "" {
+		return response.NewError(http.StatusBadRequest, "code: a team code is required")
+	}
+
+	return nil
+}
+
+// requireSelfService returns an error if the given hunt does not let
+// players manage their own teams
+func requireSelfService(huntID int) *response.Error {
+	hunt, e := db.GetHunt(huntID)
+	if e != nil {
+		return e
+	}
+
+	if hunt.SelfServiceTeams == nil || !*hunt.SelfServiceTeams {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"hunt_id: hunt %d does not let players manage their own teams",
+			huntID,
+		)
+	}
+
+	return nil
+}
+
+// requireNoTeam returns an error if the user is already on a team in the
+// given hunt
+func requireNoTeam(huntID, userID int) *response.Error {
+	teamID, e := db.TeamIDForPlayer(huntID, userID)
+	if e != nil {
+		return e
+	}
+
+	if teamID != 0 {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"user_id: you are already on team %d in this hunt, leave it first",
+			teamID,
+		)
+	}
+
+	return nil
+}
+
+// CreateOwnTeam creates the team in a self service hunt, adds the user to
+// it and makes the user its team_owner
+func CreateOwnTeam(huntID, userID int, team *Team) *response.Error {
+	e := requireSelfService(huntID)
+	if e != nil {
+		return e
+	}
+
+	e = requireNoTeam(huntID, userID)
+	if e != nil {
+		return e
+	}
+
+	team.ID = 0
+	team.HuntID = huntID
+	e = team.Insert()
+	if e != nil {
+		return e
+	}
+
+	// the player is added before the owner role is assigned so the role
+	// is tied to the player's spot on the team. LeaveTeam removes it.
+	e = AddPlayer(team.ID, userID)
+	if e != nil {
+		deleteErr := db.DeleteTeam(team.ID)
+		if deleteErr != nil {
+			e.AddError(deleteErr)
+		}
+		return e
+	}
+
+	ownerRole := roles.New("team_owner", team.ID)
+	return ownerRole.AddTo(userID)
+}
+
+// JoinTeamByCode adds the user to the team with the given code. The user
+// joins the team's hunt if they have not already.
+func JoinTeamByCode(code string, userID int) (*Team, *response.Error) {
+	teamDB, e := db.GetTeamByJoinCode(joincode.Normalize(code))
+	if e != nil {
+		return nil, e
+	}
+
+	e = requireSelfService(teamDB.HuntID)
+	if e != nil {
+		return nil, e
+	}
+
+	e = requireNoTeam(teamDB.HuntID, userID)
+	if e != nil {
+		return nil, e
+	}
+
+	e = AddPlayer(teamDB.ID, userID)
+	if e != nil {
+		return nil, e
+	}
+
+	huntEditor := roles.New("hunt_editor", teamDB.HuntID)
+	e = huntEditor.AddTo(userID)
+	if e != nil {
+		return nil, e
+	}
+
+	return &Team{*teamDB}, nil
+}
+
+// LeaveTeam removes the user from the team along with their roles for it,
+// like team_owner. A team is deleted once its last player leaves. Either way
+// the freed spot is given to the hunt's waitlist.
+func LeaveTeam(teamID, userID int) *response.Error {
+	team, e := db.GetTeam(teamID)
+	if e != nil {
+		return e
+	}
+
+	e = requireSelfService(team.HuntID)
+	if e != nil {
+		return e
+	}
+
+	e = db.TeamRemovePlayer(teamID, userID)
+	if e != nil {
+		return e
+	}
+
+	e = roles.RemoveTeamRoles(teamID, userID)
+	if e != nil {
+		return e
+	}
+
+	players, e := db.GetUsersForTeam(teamID)
+	if e != nil {
+		return e
+	}
+
+	if len(players) == 0 {
+		return DeleteTeam(teamID)
+	}
+
+	return PromoteWaitlist(team.HuntID)
+}
+
+// GetTeamCode returns the code players use to join the given team. The
+// code is generated the first time it is asked for.
+func GetTeamCode(teamID int) (string, *response.Error) {
+	var e *response.Error
+	for i := 0; i < joincode.MaxAttempts; i++ {
+		newCode, err := joincode.Generate()
+		if err != nil {
+			return "", response.NewErrorf(
+				http.StatusInternalServerError,
+				"error generating team code: %v",
+				err,
+			)
+		}
+
+		var code string
+		code, e = db.TeamJoinCode(teamID, newCode)
+		if e == nil {
+			return code, nil
+		}
+
+		// only retry if the generated code collided with an existing one
+		if _, ok := e.ErrorsByKey()["code"]; !ok {
+			return "", e
+		}
+	}
+
+	return "", e
+}
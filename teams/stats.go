@@ -0,0 +1,220 @@
+package teams
+
+import (
+	"sort"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/geo"
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// minMovingSpeed is the slowest, in meters per second, a team can go between
+// two locations and be counted as moving. Slower than that is gps drift
+// while the team is standing still.
+const minMovingSpeed float64 = 0.5
+
+// ItemSplit is how long a team took to complete an item
+//
+// swagger:model ItemSplit
+type ItemSplit struct {
+	*db.ItemSubmissionDB
+
+	// the seconds since the team's previous submission, or since the hunt
+	// started for the team's first submission
+	Seconds float64 `json:"seconds"`
+}
+
+// TeamStats is how a team moved during a hunt
+//
+// swagger:model TeamStats
+type TeamStats struct {
+	// the id of the team
+	TeamID int `json:"teamID"`
+
+	// the name of the team
+	TeamName string `json:"teamName"`
+
+	// the number of locations the team reported
+	Locations int `json:"locations"`
+
+	// the meters the team moved, along great circles between its locations
+	Distance float64 `json:"distance"`
+
+	// the seconds between the team's first and last location
+	ElapsedTime float64 `json:"elapsedTime"`
+
+	// the seconds the team spent moving
+	MovingTime float64 `json:"movingTime"`
+
+	// the team's average speed while moving, in meters per second
+	AverageSpeed float64 `json:"averageSpeed"`
+
+	// the team's fastest speed between two locations, in meters per second
+	MaxSpeed float64 `json:"maxSpeed"`
+
+	// the items the team completed in the order it completed them
+	Items []*ItemSplit `json:"items"`
+}
+
+// addMovement adds the distance and times of the locations, which must be
+// ordered by time, to the stats. Moves between locations slower than
+// minMovingSpeed are the team standing still, and moves faster than
+// maxSpeed are gps errors, so neither is counted as moving.
+func (s *TeamStats) addMovement(locs []*db.LocationDB, maxSpeed float64) {
+	s.Locations = len(locs)
+	if len(locs) < 2 {
+		return
+	}
+
+	s.ElapsedTime = locs[len(locs)-1].TimeStamp.Sub(locs[0].TimeStamp).Seconds()
+
+	prev := locs[0]
+	for _, l := range locs[1:] {
+		elapsed := l.TimeStamp.Sub(prev.TimeStamp).Seconds()
+		if elapsed <= 0 {
+			continue
+		}
+
+		dist := geo.Distance(
+			float64(prev.Latitude),
+			float64(prev.Longitude),
+			float64(l.Latitude),
+			float64(l.Longitude),
+		)
+		speed := dist / elapsed
+
+		// skip the location entirely so a single bad fix does not count
+		// as moving away and back
+		if speed > maxSpeed {
+			continue
+		}
+		prev = l
+
+		if speed < minMovingSpeed {
+			continue
+		}
+
+		s.Distance += dist
+		s.MovingTime += elapsed
+		if speed > s.MaxSpeed {
+			s.MaxSpeed = speed
+		}
+	}
+
+	if s.MovingTime > 0 {
+		s.AverageSpeed = s.Distance / s.MovingTime
+	}
+}
+
+// itemSplits returns how long the team took to complete each of its
+// submissions, which must be ordered by time, starting from the given time
+func itemSplits(subs []*db.ItemSubmissionDB, start time.Time) []*ItemSplit {
+	splits := make([]*ItemSplit, 0, len(subs))
+	last := start
+	for _, sub := range subs {
+		split := ItemSplit{ItemSubmissionDB: sub}
+		if !last.IsZero() && sub.SubmittedAt.After(last) {
+			split.Seconds = sub.SubmittedAt.Sub(last).Seconds()
+		}
+		last = sub.SubmittedAt
+
+		splits = append(splits, &split)
+	}
+
+	return splits
+}
+
+// huntStats returns the stats of each of the teams from their locations and
+// submissions, which are ordered by team and then time
+func huntStats(
+	teamDBs []*db.TeamDB,
+	locs []*db.LocationDB,
+	subs []*db.ItemSubmissionDB,
+	start time.Time,
+	maxSpeed float64,
+) []*TeamStats {
+	locsByTeam := make(map[int][]*db.LocationDB, len(teamDBs))
+	for _, l := range locs {
+		locsByTeam[l.TeamID] = append(locsByTeam[l.TeamID], l)
+	}
+
+	subsByTeam := make(map[int][]*db.ItemSubmissionDB, len(teamDBs))
+	for _, sub := range subs {
+		subsByTeam[sub.TeamID] = append(subsByTeam[sub.TeamID], sub)
+	}
+
+	stats := make([]*TeamStats, 0, len(teamDBs))
+	for _, t := range teamDBs {
+		s := TeamStats{TeamID: t.ID, TeamName: t.Name}
+		s.addMovement(locsByTeam[t.ID], maxSpeed)
+		s.Items = itemSplits(subsByTeam[t.ID], start)
+
+		stats = append(stats, &s)
+	}
+
+	// the teams that moved the farthest come first
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].Distance > stats[j].Distance
+	})
+
+	return stats
+}
+
+// GetTeamStats returns how the team with the given id moved during its hunt
+func GetTeamStats(teamID int) (*TeamStats, *response.Error) {
+	team, e := db.GetTeam(teamID)
+	if e != nil {
+		return nil, e
+	}
+
+	hunt, e := db.GetHunt(team.HuntID)
+	if e != nil {
+		return nil, e
+	}
+
+	locs, e := db.GetLocationsForTeamBetween(teamID, time.Time{}, time.Time{})
+	if e != nil {
+		return nil, e
+	}
+
+	subs, e := db.GetItemSubmissions(team.HuntID, teamID)
+	if e != nil {
+		return nil, e
+	}
+
+	stats := huntStats(
+		[]*db.TeamDB{team},
+		locs,
+		subs,
+		hunt.StartTime,
+		maxTravelSpeed(),
+	)
+	return stats[0], nil
+}
+
+// GetHuntStats returns how every team in the hunt with the given id moved
+// during the hunt, the teams that moved the farthest first
+func GetHuntStats(huntID int) ([]*TeamStats, *response.Error) {
+	hunt, e := db.GetHunt(huntID)
+	if e != nil {
+		return nil, e
+	}
+
+	teamDBs, e := db.TeamsForHunt(huntID)
+	if e != nil {
+		return nil, e
+	}
+
+	locs, e := db.GetLocationsForHuntBetween(huntID, time.Time{}, time.Time{})
+	if e != nil {
+		return nil, e
+	}
+
+	subs, e := db.GetItemSubmissions(huntID, 0)
+	if e != nil {
+		return nil, e
+	}
+
+	return huntStats(teamDBs, locs, subs, hunt.StartTime, maxTravelSpeed()), nil
+}
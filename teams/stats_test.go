@@ -0,0 +1,128 @@
+// +build unit
+
+package teams
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/geo"
+)
+
+func TestAddMovement(t *testing.T) {
+	start := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	newLoc := func(lat float32, minutes int) *db.LocationDB {
+		return &db.LocationDB{
+			TeamID:    1,
+			Latitude:  lat,
+			Longitude: -73.9654,
+			TimeStamp: start.Add(time.Duration(minutes) * time.Minute),
+		}
+	}
+
+	locs := []*db.LocationDB{
+		newLoc(40.7800, 0),
+		// walking north
+		newLoc(40.7810, 1),
+		newLoc(40.7820, 2),
+		// a gps error far away that is skipped
+		newLoc(41.7820, 3),
+		// standing still with a little drift
+		newLoc(40.78201, 4),
+		newLoc(40.78200, 5),
+		// running north
+		newLoc(40.7840, 6),
+	}
+
+	s := TeamStats{}
+	s.addMovement(locs, 40)
+
+	walk := geo.Distance(40.7800, -73.9654, 40.7810, -73.9654)
+	run := geo.Distance(40.7820, -73.9654, 40.7840, -73.9654)
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1 }
+
+	if s.Locations != len(locs) {
+		t.Errorf("expected %d locations got %d", len(locs), s.Locations)
+	}
+	if !near(s.Distance, 2*walk+run) {
+		t.Errorf("expected a distance of %.0f got %.0f", 2*walk+run, s.Distance)
+	}
+	if s.ElapsedTime != 360 {
+		t.Errorf("expected 360 elapsed seconds got %.0f", s.ElapsedTime)
+	}
+	if s.MovingTime != 180 {
+		t.Errorf("expected 180 moving seconds got %.0f", s.MovingTime)
+	}
+	if !near(s.MaxSpeed, run/60) {
+		t.Errorf("expected a max speed of %.2f got %.2f", run/60, s.MaxSpeed)
+	}
+	if !near(s.AverageSpeed, s.Distance/s.MovingTime) {
+		t.Errorf("expected the average speed while moving got %.2f", s.AverageSpeed)
+	}
+
+	single := TeamStats{}
+	single.addMovement(locs[:1], 40)
+	if single.Distance != 0 || single.MovingTime != 0 || single.Locations != 1 {
+		t.Errorf("expected a single location to not move got %+v", single)
+	}
+}
+
+func TestItemSplits(t *testing.T) {
+	start := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	subs := []*db.ItemSubmissionDB{
+		{TeamID: 1, ItemID: 43, SubmittedAt: start.Add(10 * time.Minute)},
+		{TeamID: 1, ItemID: 44, SubmittedAt: start.Add(25 * time.Minute)},
+	}
+
+	splits := itemSplits(subs, start)
+	if len(splits) != 2 {
+		t.Fatalf("expected 2 splits got %d", len(splits))
+	}
+	if splits[0].Seconds != 600 || splits[1].Seconds != 900 {
+		t.Errorf("expected 600 and 900 seconds got %.0f and %.0f", splits[0].Seconds, splits[1].Seconds)
+	}
+
+	// a submission before the hunt started has no split
+	splits = itemSplits(subs, start.Add(time.Hour))
+	if splits[0].Seconds != 0 {
+		t.Errorf("expected no split before the start got %.0f", splits[0].Seconds)
+	}
+}
+
+func TestHuntStats(t *testing.T) {
+	start := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	teamDBs := []*db.TeamDB{
+		{ID: 1, Name: "Geese"},
+		{ID: 2, Name: "Ducks"},
+		{ID: 3, Name: "Swans"},
+	}
+	locs := []*db.LocationDB{
+		{TeamID: 1, Latitude: 40.7800, Longitude: -73.9654, TimeStamp: start},
+		{TeamID: 1, Latitude: 40.7810, Longitude: -73.9654, TimeStamp: start.Add(time.Minute)},
+		{TeamID: 2, Latitude: 40.7800, Longitude: -73.9654, TimeStamp: start},
+		{TeamID: 2, Latitude: 40.7820, Longitude: -73.9654, TimeStamp: start.Add(time.Minute)},
+	}
+	subs := []*db.ItemSubmissionDB{
+		{TeamID: 2, ItemID: 43, SubmittedAt: start.Add(time.Minute)},
+	}
+
+	stats := huntStats(teamDBs, locs, subs, start, 40)
+	if len(stats) != 3 {
+		t.Fatalf("expected stats for every team got %d", len(stats))
+	}
+
+	order := []string{"Ducks", "Geese", "Swans"}
+	for i, name := range order {
+		if stats[i].TeamName != name {
+			t.Errorf("expected %s at %d got %s", name, i, stats[i].TeamName)
+		}
+	}
+	if len(stats[0].Items) != 1 || stats[0].Items[0].Seconds != 60 {
+		t.Errorf("expected the Ducks' item split got %+v", stats[0].Items)
+	}
+	if stats[2].Items == nil || len(stats[2].Items) != 0 {
+		t.Errorf("expected a team without submissions to have no items")
+	}
+}
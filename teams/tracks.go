@@ -0,0 +1,74 @@
+package teams
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/tracks"
+)
+
+// between returns whether or not the time is in the range. A zero from or to
+// leaves that end of the range open.
+func between(t, from, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || !t.After(to))
+}
+
+// TrackExport returns the locations as tracks, one for each team in
+// teamNames, and the media of those teams submitted from the given time to
+// the given time as waypoints. Locations must be ordered by team and then
+// time. Media is placed where the team was when it was submitted.
+func TrackExport(
+	name string,
+	locs []*db.LocationDB,
+	media []*db.HuntMediaDB,
+	teamNames map[int]string,
+	from, to time.Time,
+) *tracks.Export {
+	export := tracks.Export{Name: name}
+
+	var track *tracks.Track
+	for _, l := range locs {
+		teamName, ok := teamNames[l.TeamID]
+		if !ok {
+			continue
+		}
+
+		if track == nil || track.TeamID != l.TeamID {
+			track = &tracks.Track{TeamID: l.TeamID, Name: teamName}
+			export.Tracks = append(export.Tracks, track)
+		}
+
+		track.Points = append(track.Points, &tracks.Point{
+			Latitude:  float64(l.Latitude),
+			Longitude: float64(l.Longitude),
+			Time:      l.TimeStamp,
+		})
+	}
+
+	for _, m := range media {
+		teamName, ok := teamNames[m.TeamID]
+		if !ok || !between(m.Location.TimeStamp, from, to) {
+			continue
+		}
+
+		wpName := fmt.Sprintf("%s: media %d", teamName, m.ID)
+		if m.ItemName != "" {
+			wpName = fmt.Sprintf("%s: %s", teamName, m.ItemName)
+		}
+
+		export.Waypoints = append(export.Waypoints, &tracks.Waypoint{
+			Point: tracks.Point{
+				Latitude:  float64(m.Location.Latitude),
+				Longitude: float64(m.Location.Longitude),
+				Time:      m.Location.TimeStamp,
+			},
+			MediaID: m.ID,
+			TeamID:  m.TeamID,
+			Name:    wpName,
+			URL:     m.URL,
+		})
+	}
+
+	return &export
+}
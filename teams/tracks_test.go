@@ -0,0 +1,58 @@
+// +build unit
+
+package teams
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+)
+
+func TestTrackExport(t *testing.T) {
+	start := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	locs := []*db.LocationDB{
+		{TeamID: 1, Latitude: 40.7829, Longitude: -73.9654, TimeStamp: start},
+		{TeamID: 1, Latitude: 40.7833, Longitude: -73.9650, TimeStamp: start.Add(time.Minute)},
+		{TeamID: 2, Latitude: 40.7812, Longitude: -73.9665, TimeStamp: start},
+		{TeamID: 3, Latitude: 40.7812, Longitude: -73.9665, TimeStamp: start},
+	}
+
+	newMedia := func(id, teamID int, itemName string, at time.Time) *db.HuntMediaDB {
+		m := db.HuntMediaDB{ItemName: itemName}
+		m.ID = id
+		m.TeamID = teamID
+		m.URL = "https://example.com/media.jpg"
+		m.Location = db.LocationDB{Latitude: 40.78, Longitude: -73.96, TimeStamp: at}
+		return &m
+	}
+	media := []*db.HuntMediaDB{
+		newMedia(7, 1, "fountain", start.Add(time.Minute)),
+		newMedia(8, 2, "", start.Add(time.Minute)),
+		newMedia(9, 2, "", start.Add(time.Hour)),
+		newMedia(10, 3, "", start.Add(time.Minute)),
+	}
+
+	teamNames := map[int]string{1: "Geese", 2: "Ducks"}
+	export := TrackExport("Hunt", locs, media, teamNames, start, start.Add(30*time.Minute))
+
+	if len(export.Tracks) != 2 {
+		t.Fatalf("expected a track for each named team got %d", len(export.Tracks))
+	}
+	if export.Tracks[0].Name != "Geese" || len(export.Tracks[0].Points) != 2 {
+		t.Errorf("expected Geese's track to have 2 points got %+v", export.Tracks[0])
+	}
+	if export.Tracks[1].Name != "Ducks" || len(export.Tracks[1].Points) != 1 {
+		t.Errorf("expected Ducks' track to have 1 point got %+v", export.Tracks[1])
+	}
+
+	if len(export.Waypoints) != 2 {
+		t.Fatalf("expected the media in the range to be waypoints got %d", len(export.Waypoints))
+	}
+	if export.Waypoints[0].Name != "Geese: fountain" {
+		t.Errorf("expected the item's name got %s", export.Waypoints[0].Name)
+	}
+	if export.Waypoints[1].Name != "Ducks: media 8" {
+		t.Errorf("expected the media's id got %s", export.Waypoints[1].Name)
+	}
+}
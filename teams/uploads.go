@@ -0,0 +1,321 @@
+package teams
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/cljohnson4343/scavenge/storage"
+	"github.com/google/uuid"
+)
+
+// uploadExpiration is how long an upload url works for
+const uploadExpiration = 15 * time.Minute
+
+// UploadSlotRequest asks for a url to upload a media file to
+type UploadSlotRequest struct {
+	// the content type of the file
+	//
+	// required: true
+	ContentType string `json:"contentType" valid:"-"`
+
+	// the size, in bytes, of the file
+	//
+	// required: true
+	Size int64 `json:"size" valid:"-"`
+}
+
+// Validate validates the upload slot request
+func (req *UploadSlotRequest) Validate(r *http.Request) *response.Error {
+	e := response.NewNilError()
+
+	req.ContentType = strings.ToLower(strings.TrimSpace(req.ContentType))
+	if _, ok := uploadExtensions[req.ContentType]; !ok {
+		e.Addf(http.StatusBadRequest, "contentType: %s files can not be uploaded", req.ContentType)
+	}
+
+	if req.Size < 1 || req.Size > db.MaxUploadSizeLimit {
+		e.Addf(http.StatusBadRequest, "size: files have to be between 1 and %d bytes", db.MaxUploadSizeLimit)
+	}
+
+	return e.GetError()
+}
+
+// UploadSlot is where and how a media file is uploaded
+type UploadSlot struct {
+	db.UploadDB
+
+	// the url to upload the file to
+	URL string `json:"url"`
+
+	// the http method to upload the file with
+	Method string `json:"method"`
+
+	// the headers the upload has to be sent with
+	Headers map[string]string `json:"headers"`
+}
+
+// CreateUploadSlot hands out a presigned url the team uploads a media file
+// to. The file has to be uploaded with the requested content type and size,
+// which can be at most the hunt's max upload size.
+func CreateUploadSlot(store storage.Store, teamID, userID int, req *UploadSlotRequest) (*UploadSlot, *response.Error) {
+	presigner, ok := store.(storage.Presigner)
+	if !ok {
+		return nil, response.NewError(
+			http.StatusBadRequest,
+			"storage: the storage driver does not support direct uploads",
+		)
+	}
+
+	limit, e := uploadLimit(teamID)
+	if e != nil {
+		return nil, e
+	}
+
+	if req.Size > limit {
+		return nil, response.NewErrorf(
+			http.StatusBadRequest,
+			"size: files can be at most %d bytes",
+			limit,
+		)
+	}
+
+	upload := db.UploadDB{
+		TeamID:      teamID,
+		UserID:      userID,
+		Key:         uuid.New().String() + uploadExtensions[req.ContentType][0],
+		ContentType: req.ContentType,
+		Size:        req.Size,
+		ExpiresAt:   time.Now().Add(uploadExpiration).UTC(),
+	}
+
+	url, e := presigner.PresignPut(upload.Key, upload.ContentType, upload.Size, upload.ExpiresAt)
+	if e != nil {
+		return nil, e
+	}
+
+	e = upload.Insert()
+	if e != nil {
+		return nil, e
+	}
+
+	return &UploadSlot{
+		UploadDB: upload,
+		URL:      url,
+		Method:   http.MethodPut,
+		Headers:  map[string]string{"Content-Type": upload.ContentType},
+	}, nil
+}
+
+// ConfirmUploadRequest describes the media file that was uploaded
+type ConfirmUploadRequest struct {
+	// the id of the item the media is for, if any
+	//
+	// required: false
+	ItemID int `json:"itemID" valid:"-"`
+
+	// where the media was taken
+	//
+	// required: true
+	Location db.LocationDB `json:"location" valid:"-"`
+}
+
+// Validate is a dummy function. The location is validated once the team it
+// belongs to is known.
+func (req *ConfirmUploadRequest) Validate(r *http.Request) *response.Error {
+	return nil
+}
+
+// ConfirmUpload creates the media row for a file that was uploaded to the
+// team's upload slot. The file has to have been uploaded with the size the
+// slot was created for and its contents have to match the slot's content
+// type. Files that are rejected are deleted. Accepted files are stored under
+// a new key, with photos processed like media uploaded through the api. The
+// upload is confirmed, and the file uploaded to the slot is deleted, once
+// the media row exists.
+func ConfirmUpload(store storage.Store, teamID, uploadID int, req *ConfirmUploadRequest) (*db.MediaMetaDB, *response.Error) {
+	presigner, ok := store.(storage.Presigner)
+	if !ok {
+		return nil, response.NewError(
+			http.StatusBadRequest,
+			"storage: the storage driver does not support direct uploads",
+		)
+	}
+
+	upload, e := db.GetUpload(uploadID, teamID)
+	if e != nil {
+		return nil, e
+	}
+
+	if !upload.ConfirmedAt.IsZero() {
+		return nil, response.NewErrorf(
+			http.StatusBadRequest,
+			"upload: upload %d has already been confirmed",
+			upload.ID,
+		)
+	}
+
+	size, e := presigner.Stat(upload.Key)
+	if e != nil {
+		return nil, response.NewErrorf(
+			http.StatusBadRequest,
+			"upload: the file for upload %d has not been uploaded",
+			upload.ID,
+		)
+	}
+
+	if size != upload.Size {
+		return nil, response.NewErrorf(
+			http.StatusBadRequest,
+			"upload: the file is %d bytes but upload %d is for %d bytes",
+			size,
+			upload.ID,
+			upload.Size,
+		)
+	}
+
+	media := db.MediaMetaDB{
+		TeamID:   teamID,
+		ItemID:   req.ItemID,
+		Location: req.Location,
+	}
+	media.Location.TeamID = teamID
+
+	e = media.Location.Validate(nil)
+	if e != nil {
+		return nil, e
+	}
+
+	flags, e := verifyMedia(&media)
+	if e != nil {
+		return nil, e
+	}
+
+	file, e := presigner.Get(upload.Key)
+	if e != nil {
+		return nil, e
+	}
+	data, err := ioutil.ReadAll(file)
+	file.Close()
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"error reading the file for upload %d: %v",
+			upload.ID,
+			err,
+		)
+	}
+
+	e = checkUpload(upload, data)
+	if e != nil {
+		deleteErr := presigner.Delete(upload.Key)
+		if deleteErr != nil {
+			e.AddError(deleteErr)
+		}
+		return nil, e
+	}
+
+	// the checked file is stored under a new key, photos upright and without
+	// their metadata, since the slot's url works until it expires
+	thumbnails, e := storeMedia(presigner, uuid.New().String()+storage.GetExt(upload.Key), data, &media)
+	if e != nil {
+		return nil, e
+	}
+
+	duplicates, e := findDuplicates(&media)
+	if e == nil {
+		flags = append(flags, duplicates...)
+		e = media.Insert(teamID)
+	}
+	if e != nil {
+		deleteErr := deleteMediaFiles(presigner, media.URL, thumbnails)
+		if deleteErr != nil {
+			e.AddError(deleteErr)
+		}
+		return nil, e
+	}
+
+	// the upload is only confirmed once its media exists so a confirmation
+	// that failed above can be retried. Of two concurrent confirmations only
+	// one succeeds and the other's media is removed.
+	e = upload.Confirm()
+	if e != nil {
+		_, deleteErr := db.DeleteMedia(media.ID, teamID)
+		if deleteErr != nil {
+			e.AddError(deleteErr)
+		}
+
+		deleteErr = deleteMediaFiles(presigner, media.URL, thumbnails)
+		if deleteErr != nil {
+			e.AddError(deleteErr)
+		}
+		return nil, e
+	}
+
+	deleteErr := presigner.Delete(upload.Key)
+	if deleteErr != nil {
+		log.Printf("error deleting the file for upload %d: %s\n", upload.ID, deleteErr.JSON())
+	}
+
+	e = saveThumbnails(&media, thumbnails)
+	if e != nil {
+		return nil, e
+	}
+
+	e = RecordFlags(flags, media.ID, 0)
+	if e != nil {
+		return nil, e
+	}
+
+	return &media, nil
+}
+
+// checkUpload checks the contents of the file uploaded to the slot
+func checkUpload(upload *db.UploadDB, data []byte) *response.Error {
+	limit, e := uploadLimit(upload.TeamID)
+	if e != nil {
+		return e
+	}
+
+	contentType, e := checkContent(upload.Key, data, limit)
+	if e != nil {
+		return e
+	}
+
+	if contentType != upload.ContentType {
+		return response.NewErrorf(
+			http.StatusBadRequest,
+			"file: the file is a %s file but upload %d is for a %s file",
+			contentType,
+			upload.ID,
+			upload.ContentType,
+		)
+	}
+
+	return nil
+}
+
+// UploadOpen returns an error unless the key belongs to an upload that has
+// not been confirmed, so the file of a confirmed upload can not be replaced
+// through its url. It is checked by the drivers that accept uploads
+// themselves.
+func UploadOpen(key string) *response.Error {
+	upload, e := db.GetUploadByKey(key)
+	if e != nil {
+		return e
+	}
+
+	if !upload.ConfirmedAt.IsZero() {
+		return response.NewErrorf(
+			http.StatusForbidden,
+			"key: upload %d has already been confirmed",
+			upload.ID,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+// +build unit
+
+package teams
+
+import (
+	"testing"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/storage"
+)
+
+func TestUploadSlotRequestValidate(t *testing.T) {
+	cases := []struct {
+		name  string
+		req   UploadSlotRequest
+		valid bool
+	}{
+		{name: "jpeg", req: UploadSlotRequest{ContentType: "image/jpeg", Size: 1024}, valid: true},
+		{name: "mixed case type", req: UploadSlotRequest{ContentType: " Video/MP4 ", Size: 1024}, valid: true},
+		{name: "largest", req: UploadSlotRequest{ContentType: "image/png", Size: db.MaxUploadSizeLimit}, valid: true},
+		{name: "unsupported type", req: UploadSlotRequest{ContentType: "application/pdf", Size: 1024}},
+		{name: "empty", req: UploadSlotRequest{ContentType: "image/png"}},
+		{name: "too large", req: UploadSlotRequest{ContentType: "image/png", Size: db.MaxUploadSizeLimit + 1}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e := c.req.Validate(nil)
+			if c.valid && e != nil {
+				t.Errorf("expected no error got %s", e.JSON())
+			}
+			if !c.valid && e == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestMemoryStoreIsPresigner(t *testing.T) {
+	var store storage.Store = storage.NewMemoryStore("/files/")
+	if _, ok := store.(storage.Presigner); !ok {
+		t.Errorf("expected the memory store to support direct uploads")
+	}
+}
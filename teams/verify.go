@@ -0,0 +1,271 @@
+package teams
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/geo"
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/spf13/viper"
+)
+
+const (
+	// defaultMaxTravelSpeed is the fastest, in meters per second, a team is
+	// expected to move when the max_travel_speed config value is not set.
+	// It is roughly highway speed.
+	defaultMaxTravelSpeed float64 = 40
+
+	// travelSlack is the distance, in meters, a team can move no matter how
+	// little time has passed. It covers the error in phone gps readings.
+	travelSlack float64 = 50
+
+	// defaultDuplicateDistance is how many of their 64 bits the hashes of
+	// two photos can differ by for them to count as the same photo when the
+	// duplicate_photo_distance config value is not set
+	defaultDuplicateDistance = 10
+)
+
+// maxTravelSpeed returns the fastest a team is expected to move in meters
+// per second
+func maxTravelSpeed() float64 {
+	speed := viper.GetFloat64("max_travel_speed")
+	if speed <= 0 {
+		return defaultMaxTravelSpeed
+	}
+
+	return speed
+}
+
+// duplicateDistance returns how many bits the hashes of two photos can differ
+// by for them to count as the same photo
+func duplicateDistance() int {
+	distance := viper.GetInt("duplicate_photo_distance")
+	if distance <= 0 {
+		return defaultDuplicateDistance
+	}
+
+	return distance
+}
+
+// checkSubmission returns the flags for a submission for the item, which can
+// be nil, made from the given point at the given time. prev is the team's
+// last location before the submission, if any.
+func checkSubmission(
+	item *db.ItemDB,
+	lat, lng float64,
+	at time.Time,
+	prev *db.LocationDB,
+	maxSpeed float64,
+) []*db.SubmissionFlagDB {
+	flags := make([]*db.SubmissionFlagDB, 0)
+	newFlag := func(reason, detail string) *db.SubmissionFlagDB {
+		f := db.SubmissionFlagDB{
+			Reason:    reason,
+			Detail:    detail,
+			Latitude:  float32(lat),
+			Longitude: float32(lng),
+		}
+		if item != nil {
+			f.ItemID = item.ID
+		}
+		return &f
+	}
+
+	if item != nil && item.HasTarget() {
+		dist := item.DistanceTo(lat, lng)
+		if dist > float64(item.Radius) {
+			flags = append(flags, newFlag(
+				db.FlagOutsideGeofence,
+				fmt.Sprintf(
+					"submitted %.0f meters from the target, the radius is %d meters",
+					dist,
+					item.Radius,
+				),
+			))
+		}
+	}
+
+	if prev != nil && prev.TimeStamp.Before(at) {
+		dist := geo.Distance(float64(prev.Latitude), float64(prev.Longitude), lat, lng)
+		elapsed := at.Sub(prev.TimeStamp).Seconds()
+		if dist > maxSpeed*elapsed+travelSlack {
+			flags = append(flags, newFlag(
+				db.FlagImpossibleTravel,
+				fmt.Sprintf(
+					"moved %.0f meters in %.0f seconds since location %d, %.0f meters per second",
+					dist,
+					elapsed,
+					prev.ID,
+					dist/elapsed,
+				),
+			))
+		}
+	}
+
+	return flags
+}
+
+// VerifySubmission checks a submission the team made for the item, which can
+// be nil, from the given point at the given time. An error is returned if the
+// item rejects submissions from outside its radius, or the hunt rejects
+// submissions from outside its boundary, and this one is. Otherwise the flags
+// for the submission are returned and should be recorded with RecordFlags
+// once the submission is stored.
+func VerifySubmission(
+	teamID int,
+	item *db.ItemDB,
+	lat, lng float64,
+	at time.Time,
+) ([]*db.SubmissionFlagDB, *response.Error) {
+	prev, e := db.GetPreviousLocation(teamID, at)
+	if e != nil {
+		return nil, e
+	}
+
+	flags := checkSubmission(item, lat, lng, at, prev, maxTravelSpeed())
+	for _, f := range flags {
+		f.TeamID = teamID
+
+		if f.Reason == db.FlagOutsideGeofence && item.RejectsOutsideTarget() {
+			return nil, response.NewErrorf(
+				http.StatusBadRequest,
+				"location: %s",
+				f.Detail,
+			)
+		}
+	}
+
+	boundary, _, e := teamBoundary(teamID)
+	if e != nil {
+		return nil, e
+	}
+
+	if f := boundaryFlag(boundary, lat, lng); f != nil {
+		if boundary.RejectSubmissions {
+			return nil, response.NewErrorf(
+				http.StatusBadRequest,
+				"location: %s",
+				f.Detail,
+			)
+		}
+
+		f.TeamID = teamID
+		if item != nil {
+			f.ItemID = item.ID
+		}
+		flags = append(flags, f)
+	}
+
+	return flags, nil
+}
+
+// RecordFlags stores the flags for the media or item claim with the given id
+func RecordFlags(flags []*db.SubmissionFlagDB, mediaID, claimID int) *response.Error {
+	e := response.NewNilError()
+	for _, f := range flags {
+		f.MediaID = mediaID
+		f.ClaimID = claimID
+
+		flagErr := f.Insert()
+		if flagErr != nil {
+			e.AddError(flagErr)
+		}
+	}
+
+	return e.GetError()
+}
+
+// verifyMedia checks the media's item, if it has one, can be claimed with
+// media and verifies the location the media was taken at. Like claims, the
+// media is checked as of the time it is received. The media's own timestamp
+// comes from the client, and backdating it would skip the travel check.
+func verifyMedia(media *db.MediaMetaDB) ([]*db.SubmissionFlagDB, *response.Error) {
+	var item *db.ItemDB
+	if media.ItemID != 0 {
+		var e *response.Error
+		item, e = db.GetItem(media.ItemID)
+		if e != nil {
+			return nil, e
+		}
+
+		// only photo and video items are claimed by uploading media
+		if !item.ClaimedWithMedia() {
+			return nil, response.NewErrorf(
+				http.StatusBadRequest,
+				"item_id: item %d is a %s item and can not be claimed with media",
+				item.ID,
+				item.Type,
+			)
+		}
+
+		e = requireVisible(media.TeamID, item)
+		if e != nil {
+			return nil, e
+		}
+	}
+
+	return VerifySubmission(
+		media.TeamID,
+		item,
+		float64(media.Location.Latitude),
+		float64(media.Location.Longitude),
+		time.Now(),
+	)
+}
+
+// duplicateFlags returns the flags for the photos in the hunt that the media
+// looks like. A photo the team uploaded again for the same item is not a
+// duplicate.
+func duplicateFlags(media *db.MediaMetaDB, matches []*db.SimilarMediaDB) []*db.SubmissionFlagDB {
+	flags := make([]*db.SubmissionFlagDB, 0)
+	for _, m := range matches {
+		if m.MediaID == media.ID {
+			continue
+		}
+		if m.TeamID == media.TeamID && m.ItemID == media.ItemID {
+			continue
+		}
+
+		detail := fmt.Sprintf("looks like media %d uploaded by team %d", m.MediaID, m.TeamID)
+		if m.ItemID != 0 {
+			detail += fmt.Sprintf(" for item %d", m.ItemID)
+		}
+		detail += fmt.Sprintf(", %d of 64 hash bits differ", m.Distance)
+
+		flags = append(flags, &db.SubmissionFlagDB{
+			TeamID:       media.TeamID,
+			ItemID:       media.ItemID,
+			MatchMediaID: m.MediaID,
+			Reason:       db.FlagDuplicatePhoto,
+			Detail:       detail,
+			Latitude:     media.Location.Latitude,
+			Longitude:    media.Location.Longitude,
+		})
+	}
+
+	return flags
+}
+
+// findDuplicates returns the flags for the photos uploaded in the media's hunt
+// that the media's photo looks like. Media without a hash, like videos, are
+// not checked.
+func findDuplicates(media *db.MediaMetaDB) ([]*db.SubmissionFlagDB, *response.Error) {
+	if media.PHash == 0 {
+		return nil, nil
+	}
+
+	matches, e := db.GetSimilarMedia(
+		media.TeamID,
+		media.ItemID,
+		media.ID,
+		media.PHash,
+		duplicateDistance(),
+	)
+	if e != nil {
+		return nil, e
+	}
+
+	return duplicateFlags(media, matches), nil
+}
@@ -0,0 +1,152 @@
+// +build unit
+
+package teams
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/db"
+)
+
+func TestCheckSubmission(t *testing.T) {
+	now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// the target is in central park, the other points are measured from it
+	target := db.ItemDB{ID: 43, Latitude: 40.7829, Longitude: -73.9654, Radius: 100}
+	noTarget := db.ItemDB{ID: 44}
+
+	prev := db.LocationDB{
+		ID:        7,
+		Latitude:  40.7829,
+		Longitude: -73.9654,
+		TimeStamp: now.Add(-time.Minute),
+	}
+
+	cases := []struct {
+		name     string
+		item     *db.ItemDB
+		lat, lng float64
+		prev     *db.LocationDB
+		reasons  []string
+	}{
+		{name: "at the target", item: &target, lat: 40.7829, lng: -73.9654},
+		{name: "just inside the radius", item: &target, lat: 40.7837, lng: -73.9654},
+		{
+			name:    "outside the radius",
+			item:    &target,
+			lat:     40.7929,
+			lng:     -73.9654,
+			reasons: []string{db.FlagOutsideGeofence},
+		},
+		{name: "no target", item: &noTarget, lat: 10, lng: 10},
+		{name: "no item", lat: 10, lng: 10},
+		{name: "walking pace", item: &noTarget, lat: 40.7839, lng: -73.9654, prev: &prev},
+		{
+			name:    "across the city in a minute",
+			item:    &noTarget,
+			lat:     40.7128,
+			lng:     -74.006,
+			prev:    &prev,
+			reasons: []string{db.FlagImpossibleTravel},
+		},
+		{
+			name:    "outside the radius and too fast",
+			item:    &target,
+			lat:     40.7128,
+			lng:     -74.006,
+			prev:    &prev,
+			reasons: []string{db.FlagOutsideGeofence, db.FlagImpossibleTravel},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			flags := checkSubmission(c.item, c.lat, c.lng, now, c.prev, defaultMaxTravelSpeed)
+
+			if len(flags) != len(c.reasons) {
+				t.Fatalf("expected %d flags got %d: %+v", len(c.reasons), len(flags), flags)
+			}
+
+			for i, f := range flags {
+				if f.Reason != c.reasons[i] {
+					t.Errorf("expected reason %s got %s", c.reasons[i], f.Reason)
+				}
+				if c.item != nil && f.ItemID != c.item.ID {
+					t.Errorf("expected item id %d got %d", c.item.ID, f.ItemID)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckSubmissionIgnoresLaterLocations(t *testing.T) {
+	now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	later := db.LocationDB{Latitude: 40.7128, Longitude: -74.006, TimeStamp: now.Add(time.Minute)}
+
+	flags := checkSubmission(nil, 40.7829, -73.9654, now, &later, defaultMaxTravelSpeed)
+	if len(flags) != 0 {
+		t.Errorf("expected no flags got %+v", flags)
+	}
+}
+
+func TestSubmissionLocationValidate(t *testing.T) {
+	lat, lng, bad := 40.7, -74.0, 200.0
+
+	cases := []struct {
+		name  string
+		loc   SubmissionLocation
+		valid bool
+	}{
+		{name: "no location", loc: SubmissionLocation{}, valid: true},
+		{name: "full location", loc: SubmissionLocation{Latitude: &lat, Longitude: &lng}, valid: true},
+		{name: "missing longitude", loc: SubmissionLocation{Latitude: &lat}},
+		{name: "bad latitude", loc: SubmissionLocation{Latitude: &bad, Longitude: &lng}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e := c.loc.validate()
+			if c.valid && e != nil {
+				t.Errorf("expected no error got %s", e.JSON())
+			}
+			if !c.valid && e == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestDuplicateFlags(t *testing.T) {
+	media := db.MediaMetaDB{
+		ID:       10,
+		TeamID:   1,
+		ItemID:   43,
+		Location: db.LocationDB{Latitude: 40.7829, Longitude: -73.9654},
+	}
+
+	matches := []*db.SimilarMediaDB{
+		{MediaID: 10, TeamID: 1, ItemID: 43},
+		{MediaID: 11, TeamID: 1, ItemID: 43, Distance: 1},
+		{MediaID: 12, TeamID: 2, ItemID: 44, Distance: 2},
+		{MediaID: 13, TeamID: 1, ItemID: 45, Distance: 3},
+	}
+
+	flags := duplicateFlags(&media, matches)
+	if len(flags) != 2 {
+		t.Fatalf("expected 2 flags got %d: %+v", len(flags), flags)
+	}
+
+	for i, id := range []int{12, 13} {
+		f := flags[i]
+		if f.MatchMediaID != id {
+			t.Errorf("expected match media id %d got %d", id, f.MatchMediaID)
+		}
+		if f.Reason != db.FlagDuplicatePhoto {
+			t.Errorf("expected reason %s got %s", db.FlagDuplicatePhoto, f.Reason)
+		}
+		if f.TeamID != media.TeamID || f.ItemID != media.ItemID {
+			t.Errorf("expected the flag to be for team %d and item %d got %+v", media.TeamID, media.ItemID, f)
+		}
+	}
+}
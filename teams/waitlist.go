@@ -0,0 +1,234 @@
+package teams
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/cljohnson4343/scavenge/db"
+	"github.com/cljohnson4343/scavenge/joincode"
+	"github.com/cljohnson4343/scavenge/response"
+	"github.com/cljohnson4343/scavenge/roles"
+)
+
+// isInvited returns whether or not the user has an open invitation to the
+// hunt
+func isInvited(huntID, userID int) (bool, *response.Error) {
+	invitations, e := db.GetHuntInvitationsByUserID(userID)
+	if e != nil {
+		return false, e
+	}
+
+	for _, i := range invitations {
+		if i.HuntID == huntID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// waitlistAccess checks that the user of the entry may join the hunt's
+// waitlist. Players waiting for a spot on a team must already be playing in
+// the hunt and not be on a team. Users waiting to create a team must be
+// playing in, or invited to, the hunt or else have a join code for it. The
+// join code is returned when one has to be redeemed, nil otherwise.
+func waitlistAccess(entry *db.WaitlistEntryDB) (*db.HuntJoinCodeDB, *response.Error) {
+	isPlayer, e := db.IsHuntPlayer(entry.HuntID, entry.UserID)
+	if e != nil {
+		return nil, e
+	}
+
+	if isPlayer {
+		return nil, requireNoTeam(entry.HuntID, entry.UserID)
+	}
+
+	if entry.TeamName == "" {
+		return nil, response.NewErrorf(
+			http.StatusForbidden,
+			"teamID: join hunt %d before waiting for a spot on one of its teams",
+			entry.HuntID,
+		)
+	}
+
+	invited, e := isInvited(entry.HuntID, entry.UserID)
+	if e != nil {
+		return nil, e
+	}
+
+	if invited {
+		return nil, nil
+	}
+
+	if entry.Code == "" {
+		return nil, response.NewErrorf(
+			http.StatusForbidden,
+			"code: an invitation or join code for hunt %d is required",
+			entry.HuntID,
+		)
+	}
+
+	c, e := db.GetHuntJoinCodeByCode(joincode.Normalize(entry.Code))
+	if e != nil {
+		return nil, e
+	}
+
+	if c.HuntID != entry.HuntID {
+		return nil, response.NewError(http.StatusBadRequest, "code: invalid join code")
+	}
+
+	return c, nil
+}
+
+// JoinWaitlist adds the entry to the waitlist of its hunt. Only full hunts,
+// for team entries, and full teams, for player entries, have a waitlist.
+// Users without access to the hunt redeem the entry's join code, which is
+// given back if the entry can not be added.
+func JoinWaitlist(entry *db.WaitlistEntryDB) *response.Error {
+	hunt, e := db.GetHunt(entry.HuntID)
+	if e != nil {
+		return e
+	}
+
+	code, e := waitlistAccess(entry)
+	if e != nil {
+		return e
+	}
+	entry.Code = ""
+
+	if entry.TeamName != "" {
+		teams, e := db.TeamsForHunt(entry.HuntID)
+		if e != nil {
+			return e
+		}
+
+		if len(teams) < hunt.MaxTeams {
+			return response.NewErrorf(
+				http.StatusBadRequest,
+				"teamName: hunt %d has an open team spot, create the team instead",
+				entry.HuntID,
+			)
+		}
+	} else {
+		team, e := db.GetTeam(entry.TeamID)
+		if e != nil {
+			return e
+		}
+
+		if team.HuntID != entry.HuntID {
+			return response.NewErrorf(
+				http.StatusBadRequest,
+				"teamID: team %d is not part of hunt %d",
+				entry.TeamID,
+				entry.HuntID,
+			)
+		}
+
+		players, e := db.GetUsersForTeam(entry.TeamID)
+		if e != nil {
+			return e
+		}
+
+		if hunt.MaxPlayersPerTeam == 0 || len(players) < hunt.MaxPlayersPerTeam {
+			return response.NewErrorf(
+				http.StatusBadRequest,
+				"teamID: team %d has an open spot, join the team instead",
+				entry.TeamID,
+			)
+		}
+	}
+
+	if code != nil {
+		code, e = db.RedeemHuntJoinCode(code.Code)
+		if e != nil {
+			return e
+		}
+	}
+
+	e = entry.Insert()
+	if e != nil {
+		if code != nil {
+			releaseErr := db.ReleaseHuntJoinCode(code.ID)
+			if releaseErr != nil {
+				e.AddError(releaseErr)
+			}
+		}
+		return e
+	}
+
+	// a spot could have opened up between the capacity check and the insert
+	return PromoteWaitlist(entry.HuntID)
+}
+
+// addPromotedRoles gives a promoted user the roles of a player on the team
+func addPromotedRoles(huntID, teamID, userID int) *response.Error {
+	e := roles.New("hunt_editor", huntID).AddTo(userID)
+	if e != nil {
+		return e
+	}
+
+	return roles.New("team_editor", teamID).AddTo(userID)
+}
+
+// PromoteWaitlist gives any open team or player spots in the given hunt to
+// the users waiting for them. Promoted users are added to the hunt and the
+// team, get the same roles they would have gotten by creating or joining
+// the team themselves, and are sent a message.
+func PromoteWaitlist(huntID int) *response.Error {
+	promotions, e := db.PromoteWaitlist(huntID)
+	if e != nil {
+		return e
+	}
+
+	if len(promotions) == 0 {
+		return nil
+	}
+
+	hunt, e := db.GetHunt(huntID)
+	if e != nil {
+		return e
+	}
+
+	e = response.NewNilError()
+	for _, p := range promotions {
+		msg := db.UserMessageDB{UserID: p.UserID, HuntID: huntID}
+
+		var roleErr *response.Error
+		switch {
+		case p.TeamName != "" && p.TeamID == 0:
+			msg.Body = fmt.Sprintf(
+				"A team spot opened up in %s but the name %s was taken. Join the waitlist again with a new name.",
+				hunt.Name,
+				p.TeamName,
+			)
+			roleErr = roles.New("hunt_editor", huntID).AddTo(p.UserID)
+		case p.TeamName != "":
+			msg.Body = fmt.Sprintf(
+				"A team spot opened up in %s and your team %s was created.",
+				hunt.Name,
+				p.TeamName,
+			)
+			roleErr = addPromotedRoles(huntID, p.TeamID, p.UserID)
+			if roleErr == nil {
+				roleErr = roles.New("team_owner", p.TeamID).AddTo(p.UserID)
+			}
+		default:
+			msg.Body = fmt.Sprintf(
+				"A spot opened up on your team in %s and you were added to it.",
+				hunt.Name,
+			)
+			roleErr = addPromotedRoles(huntID, p.TeamID, p.UserID)
+		}
+
+		if roleErr != nil {
+			e.AddError(roleErr)
+			continue
+		}
+
+		msgErr := msg.Insert()
+		if msgErr != nil {
+			e.AddError(msgErr)
+		}
+	}
+
+	return e.GetError()
+}
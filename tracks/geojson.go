@@ -0,0 +1,82 @@
+package tracks
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// feature is a GeoJSON feature
+type feature struct {
+	Type       string                 `json:"type"`
+	Geometry   geometry               `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// geometry is a GeoJSON point or line string
+type geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// position returns the point as a GeoJSON position, which is longitude first
+func (p *Point) position() []float64 {
+	return []float64{p.Longitude, p.Latitude}
+}
+
+// EncodeGeoJSON writes the export as a GeoJSON FeatureCollection. Each track
+// is a LineString, or a Point if it has a single point, with the times of
+// its points in the coordTimes property. Each waypoint is a Point.
+func EncodeGeoJSON(w io.Writer, export *Export) error {
+	features := make([]*feature, 0, len(export.Tracks)+len(export.Waypoints))
+
+	for _, t := range export.Tracks {
+		if len(t.Points) == 0 {
+			continue
+		}
+
+		positions := make([][]float64, 0, len(t.Points))
+		times := make([]string, 0, len(t.Points))
+		for _, p := range t.Points {
+			positions = append(positions, p.position())
+			times = append(times, p.Time.UTC().Format(time.RFC3339))
+		}
+
+		g := geometry{Type: "LineString", Coordinates: positions}
+		if len(positions) == 1 {
+			g = geometry{Type: "Point", Coordinates: positions[0]}
+		}
+
+		features = append(features, &feature{
+			Type:     "Feature",
+			Geometry: g,
+			Properties: map[string]interface{}{
+				"kind":       "track",
+				"teamID":     t.TeamID,
+				"name":       t.Name,
+				"coordTimes": times,
+			},
+		})
+	}
+
+	for _, wp := range export.Waypoints {
+		features = append(features, &feature{
+			Type:     "Feature",
+			Geometry: geometry{Type: "Point", Coordinates: wp.position()},
+			Properties: map[string]interface{}{
+				"kind":    "media",
+				"mediaID": wp.MediaID,
+				"teamID":  wp.TeamID,
+				"name":    wp.Name,
+				"url":     wp.URL,
+				"time":    wp.Time.UTC().Format(time.RFC3339),
+			},
+		})
+	}
+
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"type":     "FeatureCollection",
+		"name":     export.Name,
+		"features": features,
+	})
+}
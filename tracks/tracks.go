@@ -0,0 +1,155 @@
+// Package tracks exports the paths teams took during a hunt, along with
+// where their media was submitted, as GeoJSON, GPX, or KML so they can be
+// viewed in mapping apps like Google Earth.
+package tracks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// the supported export formats
+const (
+	GeoJSON = "geojson"
+	GPX     = "gpx"
+	KML     = "kml"
+)
+
+// contentTypes maps each format to its content type
+var contentTypes = map[string]string{
+	GeoJSON: "application/geo+json",
+	GPX:     "application/gpx+xml",
+	KML:     "application/vnd.google-earth.kml+xml",
+}
+
+// Point is a position at a time
+type Point struct {
+	Latitude  float64
+	Longitude float64
+	Time      time.Time
+}
+
+// Track is the path a team took, ordered by time
+type Track struct {
+	TeamID int
+	Name   string
+	Points []*Point
+}
+
+// Waypoint is where a team submitted a media file
+type Waypoint struct {
+	Point
+
+	MediaID int
+	TeamID  int
+	Name    string
+	URL     string
+}
+
+// Export is a set of tracks and waypoints to export
+type Export struct {
+	Name      string
+	Tracks    []*Track
+	Waypoints []*Waypoint
+}
+
+// Format returns the export format requested by the format query
+// parameter, GeoJSON if there is none
+func Format(r *http.Request) (string, *response.Error) {
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		return GeoJSON, nil
+	}
+
+	if _, ok := contentTypes[format]; !ok {
+		return "", response.NewErrorf(
+			http.StatusBadRequest,
+			"format: %s is not one of geojson, gpx, or kml",
+			format,
+		)
+	}
+
+	return format, nil
+}
+
+// TimeRange returns the range of time requested by the from and to query
+// parameters, which are RFC 3339 times. A missing parameter is returned as
+// the zero time, leaving that end of the range open.
+func TimeRange(r *http.Request) (time.Time, time.Time, *response.Error) {
+	var times [2]time.Time
+	e := response.NewNilError()
+	for i, param := range []string{"from", "to"} {
+		str := r.URL.Query().Get(param)
+		if str == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, str)
+		if err != nil {
+			e.Addf(
+				http.StatusBadRequest,
+				"%s: %s is not an RFC 3339 time",
+				param,
+				str,
+			)
+			continue
+		}
+		times[i] = t
+	}
+
+	if e = e.GetError(); e != nil {
+		return time.Time{}, time.Time{}, e
+	}
+
+	if !times[0].IsZero() && !times[1].IsZero() && times[1].Before(times[0]) {
+		return time.Time{}, time.Time{}, response.NewError(
+			http.StatusBadRequest,
+			"to: to must not be before from",
+		)
+	}
+
+	return times[0], times[1], nil
+}
+
+// Encode writes the export in the given format
+func Encode(w io.Writer, format string, export *Export) error {
+	switch format {
+	case GeoJSON:
+		return EncodeGeoJSON(w, export)
+	case GPX:
+		return EncodeGPX(w, export)
+	case KML:
+		return EncodeKML(w, export)
+	}
+
+	return fmt.Errorf("tracks: unknown format %s", format)
+}
+
+// Write responds with the export in the given format as a file named
+// filename, which is given the format's extension
+func Write(w http.ResponseWriter, format, filename string, export *Export) *response.Error {
+	buf := bytes.Buffer{}
+	if err := Encode(&buf, format, export); err != nil {
+		return response.NewErrorf(
+			http.StatusInternalServerError,
+			"error encoding %s: %v",
+			format,
+			err,
+		)
+	}
+
+	w.Header().Set("Content-Type", contentTypes[format])
+	w.Header().Set(
+		"Content-Disposition",
+		fmt.Sprintf("attachment; filename=\"%s.%s\"", filename, format),
+	)
+	w.Write(buf.Bytes())
+
+	return nil
+}
@@ -0,0 +1,253 @@
+// +build unit
+
+package tracks
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newExport() *Export {
+	start := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	return &Export{
+		Name: "Central Park Hunt",
+		Tracks: []*Track{
+			{
+				TeamID: 1,
+				Name:   "Geese",
+				Points: []*Point{
+					{Latitude: 40.7829, Longitude: -73.9654, Time: start},
+					{Latitude: 40.7833, Longitude: -73.9650, Time: start.Add(time.Minute)},
+				},
+			},
+			{TeamID: 2, Name: "Ducks", Points: []*Point{
+				{Latitude: 40.7812, Longitude: -73.9665, Time: start},
+			}},
+			{TeamID: 3, Name: "Swans"},
+		},
+		Waypoints: []*Waypoint{
+			{
+				Point:   Point{Latitude: 40.7833, Longitude: -73.9650, Time: start.Add(time.Minute)},
+				MediaID: 7,
+				TeamID:  1,
+				Name:    "Geese: fountain",
+				URL:     "https://example.com/media/7.jpg",
+			},
+		},
+	}
+}
+
+func TestEncodeGeoJSON(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := EncodeGeoJSON(&buf, newExport()); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	collection := struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"features"`
+	}{}
+	if err := json.Unmarshal(buf.Bytes(), &collection); err != nil {
+		t.Fatalf("expected valid json got %v", err)
+	}
+
+	if collection.Type != "FeatureCollection" {
+		t.Errorf("expected a FeatureCollection got %s", collection.Type)
+	}
+
+	// the track without points is skipped
+	if len(collection.Features) != 3 {
+		t.Fatalf("expected 3 features got %d", len(collection.Features))
+	}
+
+	cases := []struct {
+		geometry    string
+		coordinates string
+		kind        string
+	}{
+		{"LineString", "[[-73.9654,40.7829],[-73.965,40.7833]]", "track"},
+		{"Point", "[-73.9665,40.7812]", "track"},
+		{"Point", "[-73.965,40.7833]", "media"},
+	}
+	for i, c := range cases {
+		f := collection.Features[i]
+		if f.Geometry.Type != c.geometry {
+			t.Errorf("feature %d: expected a %s got %s", i, c.geometry, f.Geometry.Type)
+		}
+		if string(f.Geometry.Coordinates) != c.coordinates {
+			t.Errorf("feature %d: expected %s got %s", i, c.coordinates, f.Geometry.Coordinates)
+		}
+		if f.Properties["kind"] != c.kind {
+			t.Errorf("feature %d: expected kind %s got %v", i, c.kind, f.Properties["kind"])
+		}
+	}
+
+	times, _ := collection.Features[0].Properties["coordTimes"].([]interface{})
+	if len(times) != 2 || times[1] != "2019-06-01T12:01:00Z" {
+		t.Errorf("expected the times of the track's points got %v", times)
+	}
+	if collection.Features[2].Properties["url"] != "https://example.com/media/7.jpg" {
+		t.Errorf("expected the media's url got %v", collection.Features[2].Properties["url"])
+	}
+}
+
+func TestEncodeGPX(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := EncodeGPX(&buf, newExport()); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Errorf("expected an xml declaration got %q", buf.String())
+	}
+
+	doc := struct {
+		Xmlns     string `xml:"xmlns,attr"`
+		Waypoints []struct {
+			Lat  float64 `xml:"lat,attr"`
+			Name string  `xml:"name"`
+			Link struct {
+				Href string `xml:"href,attr"`
+			} `xml:"link"`
+		} `xml:"wpt"`
+		Tracks []struct {
+			Name     string `xml:"name"`
+			Segments []struct {
+				Points []struct {
+					Lat  float64 `xml:"lat,attr"`
+					Lon  float64 `xml:"lon,attr"`
+					Time string  `xml:"time"`
+				} `xml:"trkpt"`
+			} `xml:"trkseg"`
+		} `xml:"trk"`
+	}{}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("expected valid xml got %v", err)
+	}
+
+	if doc.Xmlns != "http://www.topografix.com/GPX/1/1" {
+		t.Errorf("expected the GPX 1.1 namespace got %s", doc.Xmlns)
+	}
+	if len(doc.Waypoints) != 1 || doc.Waypoints[0].Name != "Geese: fountain" ||
+		doc.Waypoints[0].Link.Href != "https://example.com/media/7.jpg" {
+		t.Errorf("expected the media waypoint got %+v", doc.Waypoints)
+	}
+	if len(doc.Tracks) != 2 {
+		t.Fatalf("expected 2 tracks got %d", len(doc.Tracks))
+	}
+
+	trk := doc.Tracks[0]
+	if trk.Name != "Geese" || len(trk.Segments) != 1 || len(trk.Segments[0].Points) != 2 {
+		t.Fatalf("expected a single segment with 2 points got %+v", trk)
+	}
+	p := trk.Segments[0].Points[1]
+	if p.Lat != 40.7833 || p.Lon != -73.965 || p.Time != "2019-06-01T12:01:00Z" {
+		t.Errorf("expected the track's second point got %+v", p)
+	}
+}
+
+func TestEncodeKML(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := EncodeKML(&buf, newExport()); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	got := buf.String()
+	expected := []string{
+		`<kml xmlns="http://www.opengis.net/kml/2.2" xmlns:gx="http://www.google.com/kml/ext/2.2">`,
+		`<name>Central Park Hunt</name>`,
+		`<gx:Track>`,
+		`<when>2019-06-01T12:01:00Z</when>`,
+		`<gx:coord>-73.965 40.7833 0</gx:coord>`,
+		`<coordinates>-73.965,40.7833,0</coordinates>`,
+		`<description>https://example.com/media/7.jpg</description>`,
+	}
+	for _, str := range expected {
+		if !strings.Contains(got, str) {
+			t.Errorf("expected the kml to contain %s got %s", str, got)
+		}
+	}
+
+	if n := strings.Count(got, "<Placemark>"); n != 3 {
+		t.Errorf("expected 3 placemarks got %d", n)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		query    string
+		expected string
+		err      bool
+	}{
+		{"", GeoJSON, false},
+		{"?format=GPX", GPX, false},
+		{"?format=kml", KML, false},
+		{"?format=shp", "", true},
+	}
+
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "/teams/1/locations/export"+c.query, nil)
+		format, e := Format(r)
+		if c.err != (e != nil) {
+			t.Errorf("%q: expected error %v got %v", c.query, c.err, e != nil)
+		}
+		if format != c.expected {
+			t.Errorf("%q: expected %s got %s", c.query, c.expected, format)
+		}
+	}
+}
+
+func TestTimeRange(t *testing.T) {
+	cases := []struct {
+		name  string
+		query string
+		from  time.Time
+		to    time.Time
+		err   bool
+	}{
+		{name: "none"},
+		{
+			name:  "both",
+			query: "?from=2019-06-01T12:00:00Z&to=2019-06-01T14:00:00Z",
+			from:  time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC),
+			to:    time.Date(2019, 6, 1, 14, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "from",
+			query: "?from=2019-06-01T12:00:00Z",
+			from:  time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{name: "invalid", query: "?to=yesterday", err: true},
+		{name: "backwards", query: "?from=2019-06-01T14:00:00Z&to=2019-06-01T12:00:00Z", err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/teams/1/locations/export"+c.query, nil)
+			from, to, e := TimeRange(r)
+			if c.err {
+				if e == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if e != nil {
+				t.Fatalf("expected no error got %s", e.JSON())
+			}
+			if !from.Equal(c.from) || !to.Equal(c.to) {
+				t.Errorf("expected %v to %v got %v to %v", c.from, c.to, from, to)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package tracks
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// the GPX 1.1 document structure
+type gpxDoc struct {
+	XMLName   xml.Name    `xml:"gpx"`
+	Version   string      `xml:"version,attr"`
+	Creator   string      `xml:"creator,attr"`
+	Xmlns     string      `xml:"xmlns,attr"`
+	Name      string      `xml:"metadata>name,omitempty"`
+	Waypoints []*gpxPoint `xml:"wpt"`
+	Tracks    []*gpxTrack `xml:"trk"`
+}
+
+type gpxPoint struct {
+	Lat  float64  `xml:"lat,attr"`
+	Lon  float64  `xml:"lon,attr"`
+	Time string   `xml:"time"`
+	Name string   `xml:"name,omitempty"`
+	Link *gpxLink `xml:"link,omitempty"`
+}
+
+type gpxLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type gpxTrack struct {
+	Name     string      `xml:"name"`
+	Segments []*gpxPoint `xml:"trkseg>trkpt"`
+}
+
+// gpxTime formats the time the way GPX expects, in UTC
+func gpxTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// EncodeGPX writes the export as a GPX 1.1 document with a track for each
+// team and a waypoint for each media file
+func EncodeGPX(w io.Writer, export *Export) error {
+	doc := gpxDoc{
+		Version: "1.1",
+		Creator: "scavenge",
+		Xmlns:   "http://www.topografix.com/GPX/1/1",
+		Name:    export.Name,
+	}
+
+	for _, wp := range export.Waypoints {
+		p := gpxPoint{
+			Lat:  wp.Latitude,
+			Lon:  wp.Longitude,
+			Time: gpxTime(wp.Time),
+			Name: wp.Name,
+		}
+		if wp.URL != "" {
+			p.Link = &gpxLink{Href: wp.URL}
+		}
+		doc.Waypoints = append(doc.Waypoints, &p)
+	}
+
+	for _, t := range export.Tracks {
+		if len(t.Points) == 0 {
+			continue
+		}
+
+		trk := gpxTrack{Name: t.Name}
+		for _, p := range t.Points {
+			trk.Segments = append(trk.Segments, &gpxPoint{
+				Lat:  p.Latitude,
+				Lon:  p.Longitude,
+				Time: gpxTime(p.Time),
+			})
+		}
+		doc.Tracks = append(doc.Tracks, &trk)
+	}
+
+	return encodeXML(w, &doc)
+}
+
+// the KML 2.2 document structure. Tracks use the gx:Track extension so
+// Google Earth can play them back over time.
+type kmlDoc struct {
+	XMLName  xml.Name `xml:"kml"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	XmlnsGx  string   `xml:"xmlns:gx,attr"`
+	Document kmlDocument
+}
+
+type kmlDocument struct {
+	Name       string          `xml:"name,omitempty"`
+	Placemarks []*kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name        string    `xml:"name"`
+	Description string    `xml:"description,omitempty"`
+	When        string    `xml:"TimeStamp>when,omitempty"`
+	Point       *kmlPoint `xml:"Point,omitempty"`
+	Track       *kmlTrack `xml:"gx:Track,omitempty"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlTrack struct {
+	When  []string `xml:"when"`
+	Coord []string `xml:"gx:coord"`
+}
+
+// EncodeKML writes the export as a KML 2.2 document with a placemark for
+// each team's track and each media file
+func EncodeKML(w io.Writer, export *Export) error {
+	doc := kmlDoc{
+		Xmlns:    "http://www.opengis.net/kml/2.2",
+		XmlnsGx:  "http://www.google.com/kml/ext/2.2",
+		Document: kmlDocument{Name: export.Name},
+	}
+
+	for _, t := range export.Tracks {
+		if len(t.Points) == 0 {
+			continue
+		}
+
+		trk := kmlTrack{}
+		for _, p := range t.Points {
+			trk.When = append(trk.When, gpxTime(p.Time))
+			trk.Coord = append(trk.Coord, fmt.Sprintf("%g %g 0", p.Longitude, p.Latitude))
+		}
+		doc.Document.Placemarks = append(doc.Document.Placemarks, &kmlPlacemark{
+			Name:  t.Name,
+			Track: &trk,
+		})
+	}
+
+	for _, wp := range export.Waypoints {
+		doc.Document.Placemarks = append(doc.Document.Placemarks, &kmlPlacemark{
+			Name:        wp.Name,
+			Description: wp.URL,
+			When:        gpxTime(wp.Time),
+			Point: &kmlPoint{
+				Coordinates: fmt.Sprintf("%g,%g,0", wp.Longitude, wp.Latitude),
+			},
+		})
+	}
+
+	return encodeXML(w, &doc)
+}
+
+// encodeXML writes the document with an xml declaration
+func encodeXML(w io.Writer, doc interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -370,3 +370,75 @@ func DeleteNotificationHandler() http.HandlerFunc {
 		return
 	}
 }
+
+// swagger:route GET /users/{userID}/messages/ get user messages
+//
+// Gets the messages for the user with the given id, newest first.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+func getMessagesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, e := request.GetIntURLParam(r, "userID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		messages, e := db.GetMessagesForUser(userID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		render.JSON(w, r, messages)
+	}
+}
+
+// swagger:route DELETE /users/{userID}/messages/{messageID} delete user message
+//
+// Deletes the message with the given id.
+//
+// Consumes:
+// 	- application/json
+//
+// Produces:
+//	- application/json
+//
+// Schemes: http, https
+//
+// Responses:
+// 	200:
+//  400:
+func deleteMessageHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, e := request.GetIntURLParam(r, "userID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		messageID, e := request.GetIntURLParam(r, "messageID")
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		e = db.DeleteUserMessage(messageID, userID)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+
+		return
+	}
+}
@@ -52,6 +52,9 @@ func Routes(env *config.Env) *chi.Mux {
 			"/{userID}/notifications/{notificationID}",
 			DeleteNotificationHandler(),
 		)
+
+		r.Get("/{userID}/messages/", getMessagesHandler())
+		r.Delete("/{userID}/messages/{messageID}", deleteMessageHandler())
 	})
 
 	return router
@@ -20,6 +20,7 @@ import (
 	c "github.com/cljohnson4343/scavenge/config"
 	"github.com/cljohnson4343/scavenge/db"
 	"github.com/cljohnson4343/scavenge/response"
+	"github.com/cljohnson4343/scavenge/storage"
 )
 
 var env *config.Env
@@ -41,7 +42,7 @@ func TestMain(m *testing.M) {
 	d := db.InitDB("testing")
 	defer db.Shutdown(d)
 
-	env = c.CreateEnv(d)
+	env = c.CreateEnv(d, storage.NewMemoryStore(c.FilesURL))
 	response.SetDevMode(true)
 
 	reqBody, err := json.Marshal(&newUser)
@@ -0,0 +1,284 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// the message types, which are the opcodes of their frames
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+
+	continuationFrame = 0
+)
+
+// the close codes used by the server
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseNoStatus        = 1005
+	CloseInvalidData     = 1007
+	ClosePolicyViolation = 1008
+	CloseTooBig          = 1009
+)
+
+// defaultReadLimit is the largest message, in bytes, read by default
+const defaultReadLimit = 64 << 10
+
+// ErrClosed is returned when writing to a connection that has been closed
+var ErrClosed = errors.New("websocket: the connection is closed")
+
+// CloseError is returned by ReadMessage once the connection was closed by
+// the client or because the client broke the protocol
+type CloseError struct {
+	// the close code
+	Code int
+
+	// why the connection was closed
+	Text string
+}
+
+// Error returns the close code and reason
+func (e *CloseError) Error() string {
+	if e.Text == "" {
+		return fmt.Sprintf("websocket: closed with code %d", e.Code)
+	}
+
+	return fmt.Sprintf("websocket: closed with code %d: %s", e.Code, e.Text)
+}
+
+// Conn is a WebSocket connection. One goroutine can read from it while
+// others write to it.
+type Conn struct {
+	conn net.Conn
+	br   *bufio.Reader
+
+	readLimit int64
+
+	writeMu   sync.Mutex
+	closeSent bool
+}
+
+// newConn returns a connection that reads from br, if it is not nil, and
+// then from conn
+func newConn(conn net.Conn, br *bufio.Reader) *Conn {
+	if br == nil {
+		br = bufio.NewReader(conn)
+	}
+
+	return &Conn{conn: conn, br: br, readLimit: defaultReadLimit}
+}
+
+// SetReadLimit sets the largest message, in bytes, that can be read. Larger
+// messages close the connection.
+func (c *Conn) SetReadLimit(limit int64) {
+	c.readLimit = limit
+}
+
+// SetReadDeadline sets when a pending read fails
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline sets when a pending write fails
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+// Close closes the connection without the closing handshake
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// protocolError closes the connection because the client broke the
+// protocol and returns the matching CloseError
+func (c *Conn) protocolError(code int, text string) error {
+	c.WriteClose(code, text)
+	return &CloseError{Code: code, Text: text}
+}
+
+// readFrame reads the next frame and unmasks its payload
+func (c *Conn) readFrame() (bool, int, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.br, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin := header[0]&0x80 != 0
+	op := int(header[0] & 0x0f)
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, c.protocolError(CloseProtocolError, "extensions are not supported")
+	}
+
+	// every frame a client sends must be masked
+	if header[1]&0x80 == 0 {
+		return false, 0, nil, c.protocolError(CloseProtocolError, "frames must be masked")
+	}
+
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	if op >= CloseMessage && (!fin || length > 125) {
+		return false, 0, nil, c.protocolError(CloseProtocolError, "invalid control frame")
+	}
+
+	if length > uint64(c.readLimit) {
+		return false, 0, nil, c.protocolError(CloseTooBig, "message too big")
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return fin, op, payload, nil
+}
+
+// ReadMessage returns the next text or binary message and its type. Pings
+// are answered and pongs are skipped. Once the client closes the
+// connection, the close is answered and a *CloseError is returned.
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	msgType := 0
+	var msg []byte
+
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch op {
+		case PingMessage:
+			if err = c.WriteMessage(PongMessage, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case PongMessage:
+			continue
+		case CloseMessage:
+			closeErr := CloseError{Code: CloseNoStatus}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Text = string(payload[2:])
+			}
+
+			code := closeErr.Code
+			if code == CloseNoStatus {
+				code = CloseNormal
+			}
+			c.WriteClose(code, "")
+			return 0, nil, &closeErr
+		case TextMessage, BinaryMessage:
+			if msgType != 0 {
+				return 0, nil, c.protocolError(CloseProtocolError, "expected a continuation frame")
+			}
+			msgType = op
+			msg = payload
+		case continuationFrame:
+			if msgType == 0 {
+				return 0, nil, c.protocolError(CloseProtocolError, "unexpected continuation frame")
+			}
+			if int64(len(msg)+len(payload)) > c.readLimit {
+				return 0, nil, c.protocolError(CloseTooBig, "message too big")
+			}
+			msg = append(msg, payload...)
+		default:
+			return 0, nil, c.protocolError(CloseProtocolError, "unknown opcode")
+		}
+
+		if !fin {
+			continue
+		}
+
+		if msgType == TextMessage && !utf8.Valid(msg) {
+			return 0, nil, c.protocolError(CloseInvalidData, "text messages must be utf-8")
+		}
+
+		return msgType, msg, nil
+	}
+}
+
+// WriteMessage sends the data as a single frame of the given message type
+func (c *Conn) WriteMessage(msgType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closeSent {
+		return ErrClosed
+	}
+	if msgType == CloseMessage {
+		c.closeSent = true
+	}
+
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | byte(msgType)
+	switch {
+	case len(data) < 126:
+		header[1] = byte(len(data))
+	case len(data) <= 0xffff:
+		header[1] = 126
+		header = header[:4]
+		binary.BigEndian.PutUint16(header[2:], uint16(len(data)))
+	default:
+		header[1] = 127
+		header = header[:10]
+		binary.BigEndian.PutUint64(header[2:], uint64(len(data)))
+	}
+
+	if _, err := c.conn.Write(header); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(data)
+	return err
+}
+
+// WriteClose starts the closing handshake with the given code and reason
+func (c *Conn) WriteClose(code int, text string) error {
+	payload := make([]byte, 2, 2+len(text))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	return c.WriteMessage(CloseMessage, append(payload, text...))
+}
+
+// WriteJSON sends v as a text message of json
+func (c *Conn) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return c.WriteMessage(TextMessage, data)
+}
@@ -0,0 +1,145 @@
+// Package websocket is a small server side implementation of the WebSocket
+// protocol, RFC 6455, for the live endpoints. It supports text and binary
+// messages, fragmented messages, pings, and the closing handshake. It does
+// not support extensions or subprotocols.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cljohnson4343/scavenge/response"
+)
+
+// acceptGUID is appended to the client's key to make the accept key
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// acceptKey returns the Sec-WebSocket-Accept value for the client's
+// Sec-WebSocket-Key
+func acceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// headerContains returns whether or not the comma separated header has the
+// token, ignoring case
+func headerContains(r *http.Request, header, token string) bool {
+	for _, value := range r.Header[header] {
+		for _, v := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// originAllowed returns whether or not the request comes from a page that
+// can open a connection. Clients that are not browsers do not send an
+// origin. Browsers can only connect from the api's own host or one of the
+// allowed origins, so other sites can not use a player's session.
+func originAllowed(r *http.Request, allowed []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	for _, a := range allowed {
+		if strings.EqualFold(strings.TrimSuffix(a, "/"), origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Upgrade switches the request's connection to the WebSocket protocol.
+// Browsers must connect from the api's host or one of the allowed origins.
+// An error is returned, and nothing is written, if the request is not a
+// valid WebSocket handshake.
+func Upgrade(w http.ResponseWriter, r *http.Request, allowedOrigins []string) (*Conn, *response.Error) {
+	if r.Method != http.MethodGet {
+		return nil, response.NewError(
+			http.StatusMethodNotAllowed,
+			"websocket: the handshake must be a GET request",
+		)
+	}
+
+	if !headerContains(r, "Connection", "upgrade") || !headerContains(r, "Upgrade", "websocket") {
+		return nil, response.NewError(
+			http.StatusBadRequest,
+			"websocket: the request is not a websocket handshake",
+		)
+	}
+
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		return nil, response.NewError(
+			http.StatusBadRequest,
+			"websocket: only version 13 is supported",
+		)
+	}
+
+	key := strings.TrimSpace(r.Header.Get("Sec-WebSocket-Key"))
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return nil, response.NewError(
+			http.StatusBadRequest,
+			"websocket: the Sec-WebSocket-Key header is invalid",
+		)
+	}
+
+	if !originAllowed(r, allowedOrigins) {
+		return nil, response.NewErrorf(
+			http.StatusForbidden,
+			"websocket: connections from %s are not allowed",
+			r.Header.Get("Origin"),
+		)
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, response.NewError(
+			http.StatusInternalServerError,
+			"websocket: the connection can not be upgraded",
+		)
+	}
+
+	netConn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"websocket: error upgrading the connection: %v",
+			err,
+		)
+	}
+
+	handshake := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+	if _, err = netConn.Write([]byte(handshake)); err != nil {
+		netConn.Close()
+		return nil, response.NewErrorf(
+			http.StatusInternalServerError,
+			"websocket: error writing the handshake: %v",
+			err,
+		)
+	}
+
+	var br *bufio.Reader
+	if rw != nil {
+		br = rw.Reader
+	}
+	return newConn(netConn, br), nil
+}
@@ -0,0 +1,262 @@
+// +build unit
+
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// the example from RFC 6455
+	got := acceptKey("dGhlIHNhbXBsZSBub25jZQ==")
+	if got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("expected the RFC's accept key got %s", got)
+	}
+}
+
+func newHandshake(target string) *http.Request {
+	r := httptest.NewRequest("GET", target, nil)
+	r.Header.Set("Connection", "keep-alive, Upgrade")
+	r.Header.Set("Upgrade", "websocket")
+	r.Header.Set("Sec-WebSocket-Version", "13")
+	r.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	return r
+}
+
+func TestUpgradeErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(r *http.Request)
+		code   int
+	}{
+		{"post", func(r *http.Request) { r.Method = "POST" }, http.StatusMethodNotAllowed},
+		{"no upgrade", func(r *http.Request) { r.Header.Del("Upgrade") }, http.StatusBadRequest},
+		{"old version", func(r *http.Request) { r.Header.Set("Sec-WebSocket-Version", "8") }, http.StatusBadRequest},
+		{"bad key", func(r *http.Request) { r.Header.Set("Sec-WebSocket-Key", "abc") }, http.StatusBadRequest},
+		{"other site", func(r *http.Request) { r.Header.Set("Origin", "https://evil.com") }, http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := newHandshake("http://example.com/teams/1/locations/live")
+			c.modify(r)
+
+			_, e := Upgrade(httptest.NewRecorder(), r, []string{"https://scavenge.app"})
+			if e == nil {
+				t.Fatalf("expected an error")
+			}
+			if e.Code() != c.code {
+				t.Errorf("expected code %d got %d", c.code, e.Code())
+			}
+		})
+	}
+}
+
+func TestOriginAllowed(t *testing.T) {
+	cases := []struct {
+		origin   string
+		expected bool
+	}{
+		{"", true},
+		{"http://example.com", true},
+		{"https://scavenge.app", true},
+		{"https://evil.com", false},
+	}
+
+	for _, c := range cases {
+		r := newHandshake("http://example.com/")
+		if c.origin != "" {
+			r.Header.Set("Origin", c.origin)
+		}
+		if got := originAllowed(r, []string{"https://scavenge.app/"}); got != c.expected {
+			t.Errorf("origin %q: expected %v got %v", c.origin, c.expected, got)
+		}
+	}
+}
+
+// writeFrame writes a masked frame like a client does
+func writeFrame(t *testing.T, conn net.Conn, fin bool, op int, payload []byte) {
+	header := []byte{byte(op), 0x80}
+	if fin {
+		header[0] |= 0x80
+	}
+	if len(payload) < 126 {
+		header[1] |= byte(len(payload))
+	} else {
+		header[1] |= 126
+		header = append(header, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(len(payload)))
+	}
+
+	mask := []byte{1, 2, 3, 4}
+	masked := make([]byte, len(payload))
+	for i := range payload {
+		masked[i] = payload[i] ^ mask[i%4]
+	}
+
+	frame := append(append(header, mask...), masked...)
+	if _, err := conn.Write(frame); err != nil {
+		t.Fatalf("error writing frame: %v", err)
+	}
+}
+
+// readFrame reads an unmasked frame like a client does
+func readFrame(t *testing.T, br *bufio.Reader) (int, []byte) {
+	header := make([]byte, 2)
+	if _, err := br.Read(header[:1]); err != nil {
+		t.Fatalf("error reading frame: %v", err)
+	}
+	if _, err := br.Read(header[1:]); err != nil {
+		t.Fatalf("error reading frame: %v", err)
+	}
+
+	length := int(header[1] & 0x7f)
+	if length == 126 {
+		ext := make([]byte, 2)
+		br.Read(ext[:1])
+		br.Read(ext[1:])
+		length = int(binary.BigEndian.Uint16(ext))
+	}
+
+	payload := make([]byte, length)
+	for read := 0; read < length; {
+		n, err := br.Read(payload[read:])
+		if err != nil {
+			t.Fatalf("error reading frame: %v", err)
+		}
+		read += n
+	}
+
+	return int(header[0] & 0x0f), payload
+}
+
+func TestConn(t *testing.T) {
+	received := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, e := Upgrade(w, r, nil)
+		if e != nil {
+			e.Handle(w)
+			return
+		}
+		defer conn.Close()
+
+		for {
+			msgType, data, err := conn.ReadMessage()
+			if err != nil {
+				if closeErr, ok := err.(*CloseError); ok {
+					received <- closeErr.Text
+				}
+				return
+			}
+
+			conn.WriteMessage(msgType, data)
+		}
+	}))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("error connecting: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	r := newHandshake(server.URL + "/")
+	r.RequestURI = ""
+	if err = r.Write(conn); err != nil {
+		t.Fatalf("error writing handshake: %v", err)
+	}
+
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, r)
+	if err != nil {
+		t.Fatalf("error reading handshake: %v", err)
+	}
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected status 101 got %d", res.StatusCode)
+	}
+	if got := res.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("expected the accept key got %s", got)
+	}
+
+	// a message is echoed
+	writeFrame(t, conn, true, TextMessage, []byte(`{"latitude":1}`))
+	op, payload := readFrame(t, br)
+	if op != TextMessage || string(payload) != `{"latitude":1}` {
+		t.Errorf("expected the message to be echoed got %d %q", op, payload)
+	}
+
+	// a ping between the frames of a fragmented message is answered first
+	writeFrame(t, conn, false, TextMessage, []byte("hello "))
+	writeFrame(t, conn, true, PingMessage, []byte("ping"))
+	writeFrame(t, conn, true, continuationFrame, make([]byte, 200))
+	op, payload = readFrame(t, br)
+	if op != PongMessage || string(payload) != "ping" {
+		t.Errorf("expected a pong got %d %q", op, payload)
+	}
+	op, payload = readFrame(t, br)
+	if op != TextMessage || len(payload) != 206 || string(payload[:6]) != "hello " {
+		t.Errorf("expected the fragmented message got %d %q", op, payload)
+	}
+
+	// closing is answered
+	closePayload := []byte{0x03, 0xe8}
+	writeFrame(t, conn, true, CloseMessage, append(closePayload, "bye"...))
+	op, payload = readFrame(t, br)
+	if op != CloseMessage || binary.BigEndian.Uint16(payload) != CloseNormal {
+		t.Errorf("expected a normal close got %d %v", op, payload)
+	}
+	if text := <-received; text != "bye" {
+		t.Errorf("expected the close reason got %q", text)
+	}
+}
+
+func TestConnProtocolErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		frame []byte
+		code  int
+	}{
+		{"unmasked", []byte{0x81, 0x01, 'a'}, CloseProtocolError},
+		{"extension", []byte{0xc1, 0x81, 0, 0, 0, 0, 'a'}, CloseProtocolError},
+		{"too big", []byte{0x82, 0xfe, 0xff, 0xff}, CloseTooBig},
+		{"continuation first", []byte{0x80, 0x81, 0, 0, 0, 0, 'a'}, CloseProtocolError},
+		{"invalid utf-8", []byte{0x81, 0x81, 0, 0, 0, 0, 0xff}, CloseInvalidData},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+			conn := newConn(server, nil)
+			conn.SetReadLimit(1024)
+
+			done := make(chan error)
+			go func() {
+				_, _, err := conn.ReadMessage()
+				done <- err
+			}()
+
+			go client.Write(c.frame)
+			op, payload := readFrame(t, bufio.NewReader(client))
+			if op != CloseMessage || int(binary.BigEndian.Uint16(payload)) != c.code {
+				t.Errorf("expected close code %d got %d %v", c.code, op, payload)
+			}
+
+			closeErr, ok := (<-done).(*CloseError)
+			if !ok || closeErr.Code != c.code {
+				t.Errorf("expected a close error with code %d got %v", c.code, closeErr)
+			}
+
+			if err := conn.WriteMessage(TextMessage, []byte("a")); err != ErrClosed {
+				t.Errorf("expected writing after closing to fail got %v", err)
+			}
+		})
+	}
+}